@@ -0,0 +1,138 @@
+// Command server runs the whole skill as a single process: Alexa requests are
+// served over HTTP(S), prompts are generated by an in-process worker and
+// images are written to a local directory. Requests must be signed by Alexa
+// for one of ALEXA_APPLICATION_IDS unless SKIP_REQUEST_VERIFICATION=true, and
+// STUB_PROVIDERS=true runs the skill without any provider credentials.
+// Queued requests and responses are held in memory, or in bbolt files in
+// QUEUE_DIR so they survive a restart.
+package main
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/jackmcguire1/alexa-chatgpt/internal/dom/chatmodels"
+	"github.com/jackmcguire1/alexa-chatgpt/internal/pkg/alexa"
+	"github.com/jackmcguire1/alexa-chatgpt/internal/pkg/bucket"
+	pkginit "github.com/jackmcguire1/alexa-chatgpt/internal/pkg/init"
+	"github.com/jackmcguire1/alexa-chatgpt/internal/pkg/queue"
+	"github.com/jackmcguire1/alexa-chatgpt/internal/worker"
+)
+
+// requestsWait is how long the worker long-polls the requests queue.
+const requestsWait = 20
+
+func main() {
+	logger := pkginit.SetupLogger()
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	tp := pkginit.SetupTracing(ctx, logger)
+	defer tp.Shutdown(context.Background())
+	mp := pkginit.SetupMetrics(ctx, logger)
+	defer mp.Shutdown(context.Background())
+
+	addr := cmp.Or(os.Getenv("ADDR"), ":8080")
+	publicURL := cmp.Or(os.Getenv("PUBLIC_URL"), "http://localhost"+addr)
+	fileDir := cmp.Or(os.Getenv("FILE_DIR"), "./local-files")
+
+	var verifier *alexa.Verifier
+	if !pkginit.EnvBool("SKIP_REQUEST_VERIFICATION", false) {
+		applicationIDs := strings.FieldsFunc(os.Getenv("ALEXA_APPLICATION_IDS"), func(r rune) bool { return r == ',' || r == ' ' })
+		if len(applicationIDs) == 0 {
+			logger.Error("ALEXA_APPLICATION_IDS is required unless SKIP_REQUEST_VERIFICATION=true")
+			os.Exit(1)
+		}
+		verifier = alexa.NewVerifier(applicationIDs)
+	}
+
+	var resources *chatmodels.Resources
+	if pkginit.EnvBool("STUB_PROVIDERS", false) {
+		pkginit.LoadModelRegistry()
+		resources = &chatmodels.Resources{
+			BedrockAPI:    stubProvider{},
+			MantleAPI:     stubProvider{},
+			CloudflareAPI: stubProvider{},
+		}
+	} else {
+		resources = pkginit.InitializeResources()
+	}
+	svc := chatmodels.NewClient(resources)
+
+	var requests, responses queue.PullPoll = queue.NewMemoryQueue(0), queue.NewMemoryQueue(0)
+	if dir := os.Getenv("QUEUE_DIR"); dir != "" {
+		requests, responses = openBoltQueues(logger, dir)
+	}
+
+	w := &worker.SqsHandler{
+		GenerationModelSvc: svc,
+		ResponseQueue:      responses,
+		Logger:             logger,
+		Bucket: &bucket.Directory{
+			Path:    fileDir,
+			BaseURL: publicURL + "/files",
+		},
+	}
+	w.MaxWorkers = pkginit.EnvInt("MAX_WORKERS", worker.DefaultMaxWorkers)
+	w.StreamPartials = pkginit.EnvBool("STREAM_PARTIAL_RESPONSES", false)
+	go w.Consume(ctx, requests, requestsWait)
+
+	h := pkginit.InitializeHandler(logger, svc, responses, requests)
+
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           newServer(logger, h, verifier, fileDir),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		srv.Shutdown(shutdownCtx)
+	}()
+
+	logger.
+		With("addr", addr).
+		With("public-url", publicURL).
+		With("verify-requests", verifier != nil).
+		With("available-models", chatmodels.AvailableModels).
+		Info("serving alexa skill")
+
+	var err error
+	if cert, key := os.Getenv("TLS_CERT_FILE"), os.Getenv("TLS_KEY_FILE"); cert != "" && key != "" {
+		err = srv.ListenAndServeTLS(cert, key)
+	} else {
+		err = srv.ListenAndServe()
+	}
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		logger.With("error", err).Error("server stopped")
+		os.Exit(1)
+	}
+}
+
+// openBoltQueues opens the requests and responses queues kept in dir.
+func openBoltQueues(logger *slog.Logger, dir string) (*queue.BoltQueue, *queue.BoltQueue) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		logger.With("error", err).Error("failed to create queue directory")
+		os.Exit(1)
+	}
+	requests, err := queue.NewBoltQueue(filepath.Join(dir, "requests.db"))
+	if err != nil {
+		logger.With("error", err).Error("failed to open requests queue")
+		os.Exit(1)
+	}
+	responses, err := queue.NewBoltQueue(filepath.Join(dir, "responses.db"))
+	if err != nil {
+		logger.With("error", err).Error("failed to open responses queue")
+		os.Exit(1)
+	}
+	return requests, responses
+}
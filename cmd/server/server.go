@@ -0,0 +1,56 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"github.com/jackmcguire1/alexa-chatgpt/internal/api"
+	"github.com/jackmcguire1/alexa-chatgpt/internal/pkg/alexa"
+)
+
+// filesPath is where the local image directory is served from.
+const filesPath = "/files/"
+
+// maxRequestSize bounds the body of an Alexa request.
+const maxRequestSize = 1 << 20
+
+// newServer routes Alexa requests posted to / to the skill handler and serves
+// the generated images stored under fileDir. Requests are only dispatched once
+// verifier has checked their signature, unless verifier is nil.
+func newServer(logger *slog.Logger, h *api.Handler, verifier *alexa.Verifier, fileDir string) http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("GET "+filesPath, http.StripPrefix(filesPath, http.FileServer(http.Dir(fileDir))))
+	mux.HandleFunc("POST /{$}", func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxRequestSize))
+		if err != nil {
+			http.Error(w, "invalid alexa request", http.StatusBadRequest)
+			return
+		}
+
+		var req alexa.Request
+		if verifier != nil {
+			req, err = verifier.Verify(r.Context(), r.Header, body)
+		} else {
+			err = json.Unmarshal(body, &req)
+		}
+		if err != nil {
+			logger.With("error", err).Error("rejected alexa request")
+			http.Error(w, "invalid alexa request", http.StatusBadRequest)
+			return
+		}
+
+		resp, err := h.Invoke(r.Context(), req)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json;charset=UTF-8")
+		if err := json.NewEncoder(w).Encode(resp); err != nil {
+			logger.With("error", err).Error("failed to write alexa response")
+		}
+	})
+	return mux
+}
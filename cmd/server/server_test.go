@@ -0,0 +1,161 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jackmcguire1/alexa-chatgpt/internal/api"
+	"github.com/jackmcguire1/alexa-chatgpt/internal/dom/chatmodels"
+	"github.com/jackmcguire1/alexa-chatgpt/internal/pkg/alexa"
+	"github.com/jackmcguire1/alexa-chatgpt/internal/pkg/bucket"
+	"github.com/jackmcguire1/alexa-chatgpt/internal/pkg/queue"
+	"github.com/jackmcguire1/alexa-chatgpt/internal/worker"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestServer wires the skill the way main does, with stub providers and
+// the worker consuming the in-memory requests queue.
+func newTestServer(t *testing.T, verifier *alexa.Verifier) *httptest.Server {
+	return newTestServerWithQueues(t, verifier, queue.NewMemoryQueue(0), queue.NewMemoryQueue(0))
+}
+
+func newTestServerWithQueues(t *testing.T, verifier *alexa.Verifier, requests, responses queue.PullPoll) *httptest.Server {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	svc := chatmodels.NewClient(&chatmodels.Resources{
+		BedrockAPI:    stubProvider{},
+		MantleAPI:     stubProvider{},
+		CloudflareAPI: stubProvider{},
+	})
+
+	fileDir := t.TempDir()
+	files := &bucket.Directory{Path: fileDir}
+	w := &worker.SqsHandler{
+		GenerationModelSvc: svc,
+		ResponseQueue:      responses,
+		Logger:             logger,
+		Bucket:             files,
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go w.Consume(ctx, requests, 1)
+
+	h := api.NewHandler(
+		logger,
+		svc,
+		responses,
+		requests,
+		5,
+		chatmodels.CHAT_MODEL_SONNET,
+		chatmodels.IMAGE_MODEL_FLUX,
+		nil,
+	)
+
+	srv := httptest.NewServer(newServer(logger, h, verifier, fileDir))
+	t.Cleanup(srv.Close)
+	files.BaseURL = srv.URL + "/files"
+	return srv
+}
+
+func invoke(t *testing.T, srv *httptest.Server, req alexa.Request) alexa.Response {
+	resp, err := http.Post(srv.URL, "application/json", strings.NewReader(mustJSON(t, req)))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "application/json;charset=UTF-8", resp.Header.Get("Content-Type"))
+
+	var res alexa.Response
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&res))
+	return res
+}
+
+func intent(name string, prompt string) alexa.Request {
+	var req alexa.Request
+	req.Session.User.UserID = "local-user"
+	req.Body.Type = alexa.IntentRequestType
+	req.Body.Intent = alexa.Intent{Name: name, Slots: map[string]alexa.Slot{"prompt": {Value: prompt}}}
+	return req
+}
+
+func mustJSON(t *testing.T, v any) string {
+	data, err := json.Marshal(v)
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestLaunchRequest(t *testing.T) {
+	srv := newTestServer(t, nil)
+
+	var req alexa.Request
+	req.Body.Type = alexa.LaunchRequestType
+	res := invoke(t, srv, req)
+	assert.Equal(t, "Hi, lets begin our conversation!", res.Body.OutputSpeech.Text)
+}
+
+func TestPromptIsAnsweredByInProcessWorker(t *testing.T) {
+	srv := newTestServer(t, nil)
+
+	res := invoke(t, srv, intent(alexa.AutoCompleteIntent, "why is the sky blue"))
+	assert.Contains(t, res.Body.Card.Text, "You asked: why is the sky blue")
+	assert.Contains(t, res.Body.Card.Text, "from the sonnet model")
+	assert.Contains(t, res.Body.OutputSpeech.SSML, "You asked: why is the sky blue")
+}
+
+func TestPromptIsAnsweredOverBoltQueues(t *testing.T) {
+	requests, responses := openBoltQueues(slog.New(slog.NewTextHandler(io.Discard, nil)), t.TempDir())
+	t.Cleanup(func() {
+		requests.Close()
+		responses.Close()
+	})
+	srv := newTestServerWithQueues(t, nil, requests, responses)
+
+	res := invoke(t, srv, intent(alexa.AutoCompleteIntent, "why is the sky blue"))
+	assert.Contains(t, res.Body.Card.Text, "You asked: why is the sky blue")
+}
+
+func TestGeneratedImageIsServedFromLocalDirectory(t *testing.T) {
+	srv := newTestServer(t, nil)
+
+	res := invoke(t, srv, intent(alexa.ImageIntent, "a lighthouse"))
+	require.NotNil(t, res.Body.Card)
+	imageURL := res.Body.Card.Image.LargeImageURL
+	require.True(t, strings.HasPrefix(imageURL, srv.URL+"/files/images/"), imageURL)
+
+	resp, err := http.Get(imageURL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "image/jpeg", resp.Header.Get("Content-Type"))
+}
+
+func TestInvalidRequestBody(t *testing.T) {
+	srv := newTestServer(t, nil)
+
+	resp, err := http.Post(srv.URL, "application/json", bytes.NewBufferString("{"))
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	resp, err = http.Get(srv.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+}
+
+func TestUnsignedRequestIsRejected(t *testing.T) {
+	srv := newTestServer(t, alexa.NewVerifier([]string{"amzn1.ask.skill.local-test"}))
+
+	var req alexa.Request
+	req.Body.Type = alexa.LaunchRequestType
+	resp, err := http.Post(srv.URL, "application/json", strings.NewReader(mustJSON(t, req)))
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"strings"
+
+	"github.com/jackmcguire1/alexa-chatgpt/internal/dom/chatmodels"
+)
+
+// stubProvider stands in for Bedrock, Mantle and Cloudflare so the skill can
+// be tried end to end without credentials. Chat models echo the prompt back
+// and image models draw a gradient.
+type stubProvider struct{}
+
+func (stubProvider) GenerateContent(_ context.Context, messages []chatmodels.Message, opts chatmodels.GenerateOptions) (*chatmodels.GenerateResponse, error) {
+	var prompt string
+	for _, msg := range messages {
+		if msg.Role == chatmodels.RoleUser {
+			prompt = msg.Content
+		}
+	}
+	return &chatmodels.GenerateResponse{
+		Content: fmt.Sprintf("This is a stub answer from %s. You asked: %s.", opts.Model, prompt),
+	}, nil
+}
+
+func (s stubProvider) StreamContent(ctx context.Context, messages []chatmodels.Message, opts chatmodels.GenerateOptions, onDelta chatmodels.DeltaFunc) (*chatmodels.GenerateResponse, error) {
+	resp, err := s.GenerateContent(ctx, messages, opts)
+	if err != nil {
+		return nil, err
+	}
+	for _, word := range strings.SplitAfter(resp.Content, " ") {
+		if err := onDelta(word); err != nil {
+			return nil, err
+		}
+	}
+	return resp, nil
+}
+
+func (stubProvider) GenerateImage(_ context.Context, _ string, _ string) ([]byte, error) {
+	img := image.NewRGBA(image.Rect(0, 0, 256, 256))
+	for x := range 256 {
+		for y := range 256 {
+			img.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: 160, A: 255})
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,77 @@
+// Command skillgen writes the skill.json interaction model from the intents
+// defined in internal/api and the aliases in the model registry, and with -dir
+// the interaction model of every supported locale.
+//
+//	go run ./cmd/skillgen -locale en-US -out skill.json
+//	go run ./cmd/skillgen -dir interactionModels/custom
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/jackmcguire1/alexa-chatgpt/internal/api"
+	"github.com/jackmcguire1/alexa-chatgpt/internal/dom/chatmodels"
+)
+
+func main() {
+	locale := flag.String("locale", api.DefaultLocale, "locale of the generated interaction model")
+	out := flag.String("out", "skill.json", "file to write, - for stdout")
+	registry := flag.String("registry", "", "model registry file to take MODEL_LIST from instead of the embedded one")
+	dir := flag.String("dir", "", "directory to write <locale>.json into for every supported locale, instead of -out")
+	flag.Parse()
+
+	if *registry != "" {
+		data, err := os.ReadFile(*registry)
+		if err != nil {
+			fail(err)
+		}
+		configs, err := chatmodels.LoadModelConfigs(data)
+		if err != nil {
+			fail(err)
+		}
+		chatmodels.UseModelConfigs(configs)
+	}
+
+	if *dir != "" {
+		for _, locale := range api.Locales {
+			data, err := generate(locale)
+			if err != nil {
+				fail(err)
+			}
+			if err := os.WriteFile(filepath.Join(*dir, locale+".json"), data, 0o644); err != nil {
+				fail(err)
+			}
+		}
+		return
+	}
+
+	data, err := generate(*locale)
+	if err != nil {
+		fail(err)
+	}
+	if *out == "-" {
+		os.Stdout.Write(data)
+		return
+	}
+	if err := os.WriteFile(*out, data, 0o644); err != nil {
+		fail(err)
+	}
+}
+
+// generate renders the interaction model of locale as skill.json.
+func generate(locale string) ([]byte, error) {
+	data, err := json.MarshalIndent(api.NewInteractionModel(locale), "", "    ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, "skillgen:", err)
+	os.Exit(1)
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"os"
+	"testing"
+
+	"github.com/jackmcguire1/alexa-chatgpt/internal/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSkillJSONIsUpToDate(t *testing.T) {
+	want, err := generate(api.DefaultLocale)
+	require.NoError(t, err)
+
+	got, err := os.ReadFile("../../skill.json")
+	require.NoError(t, err)
+	assert.Equal(t, string(want), string(got), "skill.json is stale, run: go run ./cmd/skillgen")
+}
+
+func TestLocaleInteractionModelsAreUpToDate(t *testing.T) {
+	for _, locale := range api.Locales {
+		t.Run(locale, func(t *testing.T) {
+			want, err := generate(locale)
+			require.NoError(t, err)
+
+			got, err := os.ReadFile("../../interactionModels/custom/" + locale + ".json")
+			require.NoError(t, err)
+			assert.Equal(t, string(want), string(got), "run: go run ./cmd/skillgen -dir interactionModels/custom")
+		})
+	}
+}
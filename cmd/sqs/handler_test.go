@@ -7,123 +7,60 @@ import (
 	"os"
 	"testing"
 
-	"github.com/stretchr/testify/assert"
-
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/jackmcguire1/alexa-chatgpt/internal/dom/chatmodels"
 	"github.com/jackmcguire1/alexa-chatgpt/internal/pkg/queue"
 	"github.com/jackmcguire1/alexa-chatgpt/internal/pkg/utils"
+	"github.com/jackmcguire1/alexa-chatgpt/internal/worker"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
 
-func TestHandler(t *testing.T) {
-	mockChatGptSvc := &chatmodels.MockClient{}
-	mockChatGptSvc.On("TextGeneration", mock.Anything, "tell me a random fact", chatmodels.CHAT_MODEL_SONNET).Return("The battle of zanzibar lasted 30 minutes.", nil)
-
-	mockQueue := &queue.MockQueue{}
-	mockQueue.On("PushMessage", mock.Anything, mock.Anything).Return(nil)
-
-	jsonH := slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo})
-	logger := slog.New(jsonH)
-
-	h := &SqsHandler{
-		GenerationModelSvc: mockChatGptSvc,
-		ResponseQueue:      mockQueue,
-		Logger:             logger,
-	}
-
-	request := &chatmodels.Request{
-		Prompt: "tell me a random fact",
-		Model:  chatmodels.CHAT_MODEL_SONNET,
-	}
-
-	err := h.ProcessSQS(context.Background(), events.SQSEvent{
-		Records: []events.SQSMessage{
-			events.SQSMessage{
-				Body: utils.ToJSON(request),
-			},
-		},
-	})
-	assert.NoError(t, err)
+func testLogger() *slog.Logger {
+	return slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo}))
 }
 
-func TestModelErrorResponse(t *testing.T) {
-	mockChatGptSvc := &chatmodels.MockClient{}
-	mockChatGptSvc.On("TextGeneration", mock.Anything, "tell me a random fact", chatmodels.CHAT_MODEL_SONNET).Return("", fmt.Errorf("there was an issue with your API KEY"))
+func TestBatchReportsOnlyFailedRecords(t *testing.T) {
+	t.Setenv("MAX_WORKERS", "2")
 
-	mockQueue := &queue.MockQueue{}
-	mockQueue.On("PushMessage", mock.Anything, mock.Anything).Return(nil)
+	svc := &chatmodels.MockClient{}
+	svc.On("TextGeneration", mock.Anything, "tell me a random fact", chatmodels.CHAT_MODEL_SONNET).Return("The battle of zanzibar lasted 30 minutes.", nil)
+	svc.On("TextGeneration", mock.Anything, "tell me a joke", chatmodels.CHAT_MODEL_SONNET).Return("knock knock", nil)
 
-	jsonH := slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo})
-	logger := slog.New(jsonH)
+	responses := &queue.MockQueue{}
+	responses.On("PushMessage", mock.Anything, mock.MatchedBy(func(r *chatmodels.LastResponse) bool {
+		return r.RequestID == "unlucky"
+	})).Return(fmt.Errorf("500 internal server error"))
+	responses.On("PushMessage", mock.Anything, mock.Anything).Return(nil)
 
-	h := &SqsHandler{
-		GenerationModelSvc: mockChatGptSvc,
-		ResponseQueue:      mockQueue,
-		Logger:             logger,
-	}
+	h := newHandler(testLogger(), svc, responses, nil)
+	assert.Equal(t, 2, h.MaxWorkers)
 
-	request := &chatmodels.Request{
-		Prompt: "tell me a random fact",
-		Model:  chatmodels.CHAT_MODEL_SONNET,
-	}
+	fact := &chatmodels.Request{RequestID: "fact", Prompt: "tell me a random fact", Model: chatmodels.CHAT_MODEL_SONNET}
+	joke := &chatmodels.Request{RequestID: "joke", Prompt: "tell me a joke", Model: chatmodels.CHAT_MODEL_SONNET}
+	unlucky := &chatmodels.Request{RequestID: "unlucky", Prompt: "tell me a joke", Model: chatmodels.CHAT_MODEL_SONNET}
 
-	err := h.ProcessSQS(context.Background(), events.SQSEvent{
+	resp, err := h.ProcessSQS(context.Background(), events.SQSEvent{
 		Records: []events.SQSMessage{
-			events.SQSMessage{
-				Body: utils.ToJSON(request),
-			},
+			{MessageId: "message-1", Body: utils.ToJSON(fact)},
+			{MessageId: "message-2", Body: `{"prompt": `},
+			{MessageId: "message-3", Body: utils.ToJSON(joke)},
+			{MessageId: "message-4", Body: utils.ToJSON(unlucky)},
 		},
 	})
 	assert.NoError(t, err)
-	mockQueue.AssertNumberOfCalls(t, "PushMessage", 1)
-}
-
-func TestSqsError(t *testing.T) {
-	mockChatGptSvc := &chatmodels.MockClient{}
-	mockChatGptSvc.On("TextGeneration", mock.Anything, "tell me a random fact", chatmodels.CHAT_MODEL_SONNET).Return("", fmt.Errorf("there was an issue with your API KEY"))
-
-	mockQueue := &queue.MockQueue{}
-	mockQueue.On("PushMessage", mock.Anything, mock.Anything).Return(fmt.Errorf("500 internal server error"))
-
-	jsonH := slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo})
-	logger := slog.New(jsonH)
-
-	h := &SqsHandler{
-		GenerationModelSvc: mockChatGptSvc,
-		ResponseQueue:      mockQueue,
-		Logger:             logger,
-	}
-
-	request := &chatmodels.Request{
-		Prompt: "tell me a random fact",
-		Model:  chatmodels.CHAT_MODEL_SONNET,
-	}
-
-	err := h.ProcessSQS(context.Background(), events.SQSEvent{
-		Records: []events.SQSMessage{
-			events.SQSMessage{
-				Body: utils.ToJSON(request),
-			},
-		},
-	})
-	assert.Error(t, err)
+	assert.ElementsMatch(t, []events.SQSBatchItemFailure{
+		{ItemIdentifier: "message-2"},
+		{ItemIdentifier: "message-4"},
+	}, resp.BatchItemFailures)
+	responses.AssertNumberOfCalls(t, "PushMessage", 3)
 }
 
-func TestBadJSONPayload(t *testing.T) {
-	jsonH := slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo})
-	logger := slog.New(jsonH)
-
-	h := &SqsHandler{
-		Logger: logger,
-	}
+func TestHandlerDefaults(t *testing.T) {
+	t.Setenv("MAX_WORKERS", "")
+	t.Setenv("STREAM_PARTIAL_RESPONSES", "")
 
-	err := h.ProcessSQS(context.Background(), events.SQSEvent{
-		Records: []events.SQSMessage{
-			events.SQSMessage{
-				Body: `{]`,
-			},
-		},
-	})
-	assert.Error(t, err)
+	h := newHandler(testLogger(), nil, nil, nil)
+	assert.Equal(t, worker.DefaultMaxWorkers, h.MaxWorkers)
+	assert.False(t, h.StreamPartials)
 }
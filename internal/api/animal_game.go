@@ -1,6 +1,7 @@
 package api
 
 import (
+	"encoding/json"
 	"math/rand"
 	"strings"
 	"time"
@@ -12,6 +13,7 @@ type AnimalGame struct {
 	guessesLeft  int
 	hintsLeft    int
 	hintsGiven   []int
+	hints        []string
 	gameActive   bool
 	maxGuesses   int
 	maxHints     int
@@ -133,6 +135,48 @@ func NewAnimalGame() *AnimalGame {
 	return game
 }
 
+// animalGameJSON is the persisted form of an AnimalGame.
+type animalGameJSON struct {
+	Animal      string   `json:"animal"`
+	GuessesLeft int      `json:"guesses_left"`
+	HintsLeft   int      `json:"hints_left"`
+	HintsGiven  []int    `json:"hints_given"`
+	Hints       []string `json:"hints,omitempty"`
+	GameActive  bool     `json:"game_active"`
+	MaxGuesses  int      `json:"max_guesses"`
+	MaxHints    int      `json:"max_hints"`
+}
+
+func (g *AnimalGame) MarshalJSON() ([]byte, error) {
+	return json.Marshal(animalGameJSON{
+		Animal:      g.animal,
+		GuessesLeft: g.guessesLeft,
+		HintsLeft:   g.hintsLeft,
+		HintsGiven:  g.hintsGiven,
+		Hints:       g.hints,
+		GameActive:  g.gameActive,
+		MaxGuesses:  g.maxGuesses,
+		MaxHints:    g.maxHints,
+	})
+}
+
+func (g *AnimalGame) UnmarshalJSON(data []byte) error {
+	var game animalGameJSON
+	if err := json.Unmarshal(data, &game); err != nil {
+		return err
+	}
+	g.animal = game.Animal
+	g.guessesLeft = game.GuessesLeft
+	g.hintsLeft = game.HintsLeft
+	g.hintsGiven = game.HintsGiven
+	g.hints = game.Hints
+	g.gameActive = game.GameActive
+	g.maxGuesses = game.MaxGuesses
+	g.maxHints = game.MaxHints
+	g.animalList = animalDatabase
+	return nil
+}
+
 // selectRandomAnimal picks a random animal from the database
 func (g *AnimalGame) selectRandomAnimal() {
 	rand.Seed(time.Now().UnixNano())
@@ -210,6 +254,16 @@ func (g *AnimalGame) RequestHint() HintResult {
 	}
 }
 
+// RecordHint keeps the text of a hint given to the player.
+func (g *AnimalGame) RecordHint(hint string) {
+	g.hints = append(g.hints, hint)
+}
+
+// Hints returns the hints given so far, oldest first.
+func (g *AnimalGame) Hints() []string {
+	return g.hints
+}
+
 // GetStatus returns the current game status
 func (g *AnimalGame) GetStatus() GameStatus {
 	return GameStatus{
@@ -234,6 +288,7 @@ func (g *AnimalGame) ResetGame() {
 	g.guessesLeft = MaxGuesses
 	g.hintsLeft = MaxHints
 	g.hintsGiven = []int{}
+	g.hints = nil
 	g.gameActive = true
 	g.selectRandomAnimal()
 }
@@ -334,3 +334,19 @@ func TestGameConstants(t *testing.T) {
 func TestAnimalDatabaseCount(t *testing.T) {
 	assert.GreaterOrEqual(t, len(animalDatabase), 15, "Should have at least 15 animals in database")
 }
+
+func TestRecordHintKeepsHistoryUntilReset(t *testing.T) {
+	game := NewAnimalGame()
+	game.RecordHint("It lives on a farm.")
+	game.RecordHint("It says moo.")
+	assert.Equal(t, []string{"It lives on a farm.", "It says moo."}, game.Hints())
+
+	data, err := game.MarshalJSON()
+	assert.NoError(t, err)
+	restored := &AnimalGame{}
+	assert.NoError(t, restored.UnmarshalJSON(data))
+	assert.Equal(t, game.Hints(), restored.Hints())
+
+	game.ResetGame()
+	assert.Empty(t, game.Hints())
+}
@@ -3,6 +3,7 @@ package api
 import (
 	"testing"
 
+	"github.com/jackmcguire1/alexa-chatgpt/internal/pkg/alexa"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -102,3 +103,18 @@ func TestAttackEndsGame(t *testing.T) {
 
 	assert.Equal(t, GameOver, result)
 }
+
+func TestAPLBoardHidesShipsUntilHit(t *testing.T) {
+	game := NewBattleships(3)
+	_ = game.PlaceShip(0, 0, 2, true)
+	_ = game.PlaceShip(2, 0, 1, true)
+	game.Attack(0, 0)
+	game.Attack(1, 1)
+	game.Attack(2, 0)
+
+	assert.Equal(t, [][]alexa.BattleshipCell{
+		{alexa.CellHit, alexa.CellWater, alexa.CellWater},
+		{alexa.CellWater, alexa.CellMiss, alexa.CellWater},
+		{alexa.CellSunk, alexa.CellWater, alexa.CellWater},
+	}, game.aplBoard())
+}
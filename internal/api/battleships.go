@@ -1,8 +1,11 @@
 package api
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
+
+	"github.com/jackmcguire1/alexa-chatgpt/internal/pkg/alexa"
 )
 
 const (
@@ -35,6 +38,29 @@ func NewBattleships(size int) *Battleships {
 	return &Battleships{board: board, size: size, ships: make(map[int]*Ship)}
 }
 
+// battleshipsJSON is the persisted form of a Battleships game.
+type battleshipsJSON struct {
+	Board [][]int       `json:"board"`
+	Size  int           `json:"size"`
+	Ships map[int]*Ship `json:"ships"`
+}
+
+func (b *Battleships) MarshalJSON() ([]byte, error) {
+	return json.Marshal(battleshipsJSON{Board: b.board, Size: b.size, Ships: b.ships})
+}
+
+func (b *Battleships) UnmarshalJSON(data []byte) error {
+	var game battleshipsJSON
+	if err := json.Unmarshal(data, &game); err != nil {
+		return err
+	}
+	if game.Ships == nil {
+		game.Ships = make(map[int]*Ship)
+	}
+	b.board, b.size, b.ships = game.Board, game.Size, game.Ships
+	return nil
+}
+
 func (b *Battleships) PlaceShip(x, y, length int, horizontal bool) error {
 	if horizontal {
 		if y+length > b.size {
@@ -170,6 +196,38 @@ func (b *Battleships) TotalHitsAndMisses() (int, int) {
 	return hits, misses
 }
 
+// aplBoard returns what the player knows of each square: ships are only
+// revealed where they have been hit.
+func (b *Battleships) aplBoard() [][]alexa.BattleshipCell {
+	board := make([][]alexa.BattleshipCell, len(b.board))
+	for x, row := range b.board {
+		board[x] = make([]alexa.BattleshipCell, len(row))
+		for y, cell := range row {
+			switch cell {
+			case Miss:
+				board[x][y] = alexa.CellMiss
+			case Hit:
+				board[x][y] = alexa.CellHit
+			default:
+				board[x][y] = alexa.CellWater
+			}
+		}
+	}
+	for _, ship := range b.ships {
+		if !ship.Killed {
+			continue
+		}
+		for i := range ship.Length {
+			if ship.Horizontal {
+				board[ship.X][ship.Y+i] = alexa.CellSunk
+			} else {
+				board[ship.X+i][ship.Y] = alexa.CellSunk
+			}
+		}
+	}
+	return board
+}
+
 func (b *Battleships) PrintBoard() {
 	for _, row := range b.board {
 		for _, cell := range row {
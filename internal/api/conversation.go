@@ -0,0 +1,88 @@
+package api
+
+import (
+	"encoding/json"
+
+	"github.com/jackmcguire1/alexa-chatgpt/internal/dom/chatmodels"
+	"github.com/jackmcguire1/alexa-chatgpt/internal/pkg/alexa"
+)
+
+const (
+	conversationAttribute = "conversation"
+
+	// DefaultConversationTokenBudget bounds the estimated size of the history
+	// sent along with each prompt when the Handler does not set its own.
+	DefaultConversationTokenBudget = 2000
+)
+
+// Conversation is the history of prompts and answers exchanged with a user,
+// oldest first, as alternating user and assistant messages.
+type Conversation struct {
+	Turns []chatmodels.Message `json:"turns,omitempty"`
+}
+
+// Append records an exchange and drops the oldest exchanges until the history
+// fits within budget estimated tokens.
+func (c *Conversation) Append(prompt string, response string, budget int) {
+	c.Turns = append(c.Turns,
+		chatmodels.Message{Role: chatmodels.RoleUser, Content: prompt},
+		chatmodels.Message{Role: chatmodels.RoleAssistant, Content: response},
+	)
+
+	for len(c.Turns) > 0 && c.tokens() > budget {
+		c.Turns = c.Turns[2:]
+	}
+}
+
+// Forget removes the most recent exchange, reporting whether there was one.
+func (c *Conversation) Forget() bool {
+	if len(c.Turns) < 2 {
+		return false
+	}
+	c.Turns = c.Turns[:len(c.Turns)-2]
+	return true
+}
+
+func (c *Conversation) Reset() {
+	c.Turns = nil
+}
+
+func (c *Conversation) tokens() (total int) {
+	for _, turn := range c.Turns {
+		total += estimateTokens(turn.Content)
+	}
+	return
+}
+
+// estimateTokens approximates the token count of English text at roughly four
+// characters per token, which is close enough for every supported provider.
+func estimateTokens(text string) int {
+	return (len(text) + 3) / 4
+}
+
+// sessionConversation returns the conversation carried in the Alexa session
+// attributes, if the session has one.
+func sessionConversation(session alexa.Session) (*Conversation, bool) {
+	raw, ok := session.Attributes[conversationAttribute]
+	if !ok {
+		return nil, false
+	}
+
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, false
+	}
+
+	var conversation *Conversation
+	if err = json.Unmarshal(data, &conversation); err != nil || conversation == nil {
+		return nil, false
+	}
+	return conversation, true
+}
+
+func (h *Handler) conversationTokenBudget() int {
+	if h.ConversationTokenBudget > 0 {
+		return h.ConversationTokenBudget
+	}
+	return DefaultConversationTokenBudget
+}
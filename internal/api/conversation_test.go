@@ -0,0 +1,135 @@
+package api
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/jackmcguire1/alexa-chatgpt/internal/dom/chatmodels"
+	"github.com/jackmcguire1/alexa-chatgpt/internal/pkg/alexa"
+	"github.com/jackmcguire1/alexa-chatgpt/internal/pkg/queue"
+	"github.com/jackmcguire1/alexa-chatgpt/internal/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestConversationAppendTruncatesOldestTurns(t *testing.T) {
+	var conversation Conversation
+	conversation.Append("first question", strings.Repeat("a", 40), 20)
+	conversation.Append("second question", strings.Repeat("b", 40), 20)
+
+	assert.Len(t, conversation.Turns, 2)
+	assert.Equal(t, chatmodels.RoleUser, conversation.Turns[0].Role)
+	assert.Equal(t, "second question", conversation.Turns[0].Content)
+	assert.Equal(t, chatmodels.RoleAssistant, conversation.Turns[1].Role)
+}
+
+func TestConversationAppendDropsExchangeLargerThanBudget(t *testing.T) {
+	var conversation Conversation
+	conversation.Append("question", strings.Repeat("a", 400), 10)
+
+	assert.Empty(t, conversation.Turns)
+}
+
+func TestConversationForget(t *testing.T) {
+	var conversation Conversation
+	assert.False(t, conversation.Forget())
+
+	conversation.Append("first question", "first answer", 100)
+	conversation.Append("second question", "second answer", 100)
+	assert.True(t, conversation.Forget())
+	assert.Equal(t, []chatmodels.Message{
+		{Role: chatmodels.RoleUser, Content: "first question"},
+		{Role: chatmodels.RoleAssistant, Content: "first answer"},
+	}, conversation.Turns)
+}
+
+func TestFollowUpPromptCarriesHistory(t *testing.T) {
+	mockRequestsQueue := &queue.MockQueue{}
+	mockRequestsQueue.On("PushMessage", mock.Anything, mock.Anything).Return(nil)
+
+	mockResponsesQueue := &queue.MockQueue{}
+	mockResponsesQueue.On("Ack", mock.Anything, mock.Anything).Return(nil)
+	mockResponsesQueue.On("ReceiveMessage", mock.Anything, mock.Anything).Return([]byte(utils.ToJSON(&chatmodels.LastResponse{
+		RequestID: "alice-1", UserID: "alice", Prompt: "why is the sky blue", Response: "rayleigh scattering", Model: "sonnet",
+	})), nil).Once()
+	mockResponsesQueue.On("ReceiveMessage", mock.Anything, mock.Anything).Return([]byte(utils.ToJSON(&chatmodels.LastResponse{
+		RequestID: "alice-2", UserID: "alice", Prompt: "and why is that", Response: "shorter wavelengths scatter more", Model: "sonnet",
+	})), nil).Once()
+
+	h := NewHandler(logger, &chatmodels.MockClient{}, mockResponsesQueue, mockRequestsQueue, 0, chatmodels.CHAT_MODEL_SONNET, "", nil)
+
+	resp, err := h.Invoke(context.Background(), autoCompleteRequest("alice", "alice-1", "why is the sky blue"))
+	assert.NoError(t, err)
+	assert.Contains(t, resp.SessionAttributes, conversationAttribute)
+
+	_, err = h.Invoke(context.Background(), autoCompleteRequest("alice", "alice-2", "and why is that"))
+	assert.NoError(t, err)
+
+	mockRequestsQueue.AssertCalled(t, "PushMessage", mock.Anything, mock.MatchedBy(func(r *chatmodels.Request) bool {
+		return r.RequestID == "alice-2" && len(r.History) == 2 &&
+			r.History[0].Content == "why is the sky blue" &&
+			r.History[1].Content == "rayleigh scattering"
+	}))
+
+	state, err := h.loadState(context.Background(), "alice")
+	assert.NoError(t, err)
+	assert.Len(t, state.Conversation.Turns, 4)
+}
+
+func TestConversationRestoredFromSessionAttributes(t *testing.T) {
+	mockRequestsQueue := &queue.MockQueue{}
+	mockRequestsQueue.On("PushMessage", mock.Anything, mock.Anything).Return(nil)
+
+	mockResponsesQueue := &queue.MockQueue{}
+	mockResponsesQueue.On("Ack", mock.Anything, mock.Anything).Return(nil)
+	mockResponsesQueue.On("ReceiveMessage", mock.Anything, mock.Anything).Return(nil, nil)
+
+	h := NewHandler(logger, &chatmodels.MockClient{}, mockResponsesQueue, mockRequestsQueue, 0, chatmodels.CHAT_MODEL_SONNET, "", nil)
+
+	req := autoCompleteRequest("alice", "alice-2", "and why is that")
+	req.Session.Attributes = map[string]any{
+		conversationAttribute: map[string]any{
+			"turns": []any{
+				map[string]any{"role": "user", "content": "why is the sky blue"},
+				map[string]any{"role": "assistant", "content": "rayleigh scattering"},
+			},
+		},
+	}
+
+	_, err := h.Invoke(context.Background(), req)
+	assert.NoError(t, err)
+	mockRequestsQueue.AssertCalled(t, "PushMessage", mock.Anything, mock.MatchedBy(func(r *chatmodels.Request) bool {
+		return len(r.History) == 2 && r.History[1].Role == chatmodels.RoleAssistant
+	}))
+}
+
+func TestNewConversationAndForgetIntents(t *testing.T) {
+	h := NewHandler(logger, &chatmodels.MockClient{}, nil, nil, 0, chatmodels.CHAT_MODEL_SONNET, "", nil)
+
+	state, err := h.loadState(context.Background(), "alice")
+	assert.NoError(t, err)
+	state.Conversation.Append("first question", "first answer", 100)
+	state.Conversation.Append("second question", "second answer", 100)
+	assert.NoError(t, h.saveState(context.Background(), "alice", state))
+
+	resp, err := h.Invoke(context.Background(), intentRequest("alice", alexa.ForgetIntent, nil))
+	assert.NoError(t, err)
+	assert.Equal(t, "okay, I've forgotten that", resp.Body.OutputSpeech.Text)
+
+	state, err = h.loadState(context.Background(), "alice")
+	assert.NoError(t, err)
+	assert.Len(t, state.Conversation.Turns, 2)
+
+	resp, err = h.Invoke(context.Background(), intentRequest("alice", alexa.NewConversationIntent, nil))
+	assert.NoError(t, err)
+	assert.Equal(t, "okay, let's start a new conversation", resp.Body.OutputSpeech.Text)
+
+	state, err = h.loadState(context.Background(), "alice")
+	assert.NoError(t, err)
+	assert.Empty(t, state.Conversation.Turns)
+
+	resp, err = h.Invoke(context.Background(), intentRequest("alice", alexa.ForgetIntent, nil))
+	assert.NoError(t, err)
+	assert.Equal(t, "there is nothing in our conversation to forget", resp.Body.OutputSpeech.Text)
+}
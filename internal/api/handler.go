@@ -5,13 +5,14 @@ import (
 	"fmt"
 	"log/slog"
 	"strconv"
-	"strings"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/jackmcguire1/alexa-chatgpt/internal/dom/chatmodels"
 	otelsetup "github.com/jackmcguire1/alexa-chatgpt/internal/otel"
 	"github.com/jackmcguire1/alexa-chatgpt/internal/pkg/alexa"
 	"github.com/jackmcguire1/alexa-chatgpt/internal/pkg/queue"
+	"github.com/jackmcguire1/alexa-chatgpt/internal/pkg/userstate"
 	"github.com/jackmcguire1/alexa-chatgpt/internal/pkg/utils"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
@@ -19,23 +20,39 @@ import (
 
 var trace = otel.Tracer("prompt-requester")
 
-type intentHandler func(ctx context.Context, req alexa.Request, xrayID string) (alexa.Response, error)
+// ProgressiveResponder speaks to the user while their request is still being
+// served, see alexa.Client.
+type ProgressiveResponder interface {
+	SendProgressiveResponse(ctx context.Context, req alexa.Request, speech *alexa.SSML) error
+}
+
+// gameHostPrompt is the system prompt of the game hosts.
+const gameHostPrompt = "You are a friendly game host for players of all ages. Be encouraging, enthusiastic, and use simple language."
+
+type intentHandler func(ctx context.Context, req alexa.Request, state *UserState, xrayID string) (alexa.Response, error)
 
 type Handler struct {
-	Logger          *slog.Logger
-	ChatGptService  chatmodels.Service
-	lastResponse    *chatmodels.LastResponse
-	ResponsesQueue  queue.PullPoll
-	RequestsQueue   queue.PullPoll
-	PollDelay       int
-	Model           chatmodels.ChatModel
-	ImageModel      chatmodels.ImageModel
-	RandomNumberSvc *RandomNumberGame
-	BattleShips     *Battleships
-	AnimalGame      *AnimalGame
-	LastIntent      alexa.Request
-	SystemMessage   string
-	routes          map[string]intentHandler
+	Logger                  *slog.Logger
+	ChatGptService          chatmodels.Service
+	StateStore              userstate.UserStateStore
+	ResponsesQueue          queue.PullPoll
+	RequestsQueue           queue.PullPoll
+	PollDelay               int
+	Model                   chatmodels.ChatModel
+	ImageModel              chatmodels.ImageModel
+	ConversationTokenBudget int
+	// DetectTranslationSource asks the user's model which language a phrase
+	// is in when they don't say, rather than assuming their locale's.
+	DetectTranslationSource bool
+	// TranslationModel, when set, translates instead of the user's model.
+	TranslationModel chatmodels.ChatModel
+	// RomanizeTranslations asks for translations into non-Latin scripts to
+	// come with a romanised version Alexa can pronounce.
+	RomanizeTranslations bool
+	// ProgressiveResponder, when set, tells the user which model is working on
+	// their request while the responses queue is polled.
+	ProgressiveResponder ProgressiveResponder
+	routes               map[string]intentHandler
 }
 
 func NewHandler(
@@ -46,21 +63,20 @@ func NewHandler(
 	pollDelay int,
 	model chatmodels.ChatModel,
 	imageModel chatmodels.ImageModel,
-	randomNumberSvc *RandomNumberGame,
-	battleShips *Battleships,
-	animalGame *AnimalGame,
+	stateStore userstate.UserStateStore,
 ) *Handler {
+	if stateStore == nil {
+		stateStore = userstate.NewMemoryStore()
+	}
 	h := &Handler{
-		Logger:          logger,
-		ChatGptService:  chatGptService,
-		ResponsesQueue:  responsesQueue,
-		RequestsQueue:   requestsQueue,
-		PollDelay:       pollDelay,
-		Model:           model,
-		ImageModel:      imageModel,
-		RandomNumberSvc: randomNumberSvc,
-		BattleShips:     battleShips,
-		AnimalGame:      animalGame,
+		Logger:         logger,
+		ChatGptService: chatGptService,
+		ResponsesQueue: responsesQueue,
+		RequestsQueue:  requestsQueue,
+		PollDelay:      pollDelay,
+		Model:          model,
+		ImageModel:     imageModel,
+		StateStore:     stateStore,
 	}
 	h.initRoutes()
 	return h
@@ -74,6 +90,7 @@ func (h *Handler) initRoutes() {
 		alexa.SystemMessageIntent:      h.handleSystemMessage,
 		alexa.SystemAutoCompleteIntent: h.handleSystemAutoComplete,
 		alexa.TranslateIntent:          h.handleTranslate,
+		alexa.GlossaryIntent:           h.handleGlossary,
 		alexa.AutoCompleteIntent:       h.handleAutoComplete,
 		alexa.RandomFactIntent:         h.handleRandomFact,
 		alexa.BattleshipStatusIntent:   h.handleBattleshipStatus,
@@ -83,6 +100,12 @@ func (h *Handler) initRoutes() {
 		alexa.AnimalGuessIntent:        h.handleAnimalGuess,
 		alexa.RandomNumberIntent:       h.handleRandomNumber,
 		alexa.LastResponseIntent:       h.handleLastResponse,
+		alexa.NextIntent:               h.handleNext,
+		alexa.RepeatIntent:             h.handleRepeat,
+		alexa.PreviousIntent:           h.handlePrevious,
+		alexa.NewConversationIntent:    h.handleNewConversation,
+		alexa.ForgetIntent:             h.handleForget,
+		alexa.UsageIntent:              h.handleUsage,
 		alexa.HelpIntent:               h.handleHelp,
 		alexa.CancelIntent:             h.handleCancel,
 		alexa.NoIntent:                 h.handleStop,
@@ -91,14 +114,71 @@ func (h *Handler) initRoutes() {
 	}
 }
 
-func (h *Handler) randomFact(ctx context.Context) (string, error) {
+// enqueue stamps the request with the correlation IDs of the invoking user, pushes
+// it onto the requests queue and waits for the response produced for it.
+func (h *Handler) enqueue(ctx context.Context, req alexa.Request, state *UserState, request *chatmodels.Request) (alexa.Response, error) {
+	request.RequestID = req.Body.RequestID
+	if request.RequestID == "" {
+		request.RequestID = uuid.New().String()
+	}
+	request.UserID = req.Session.User.UserID
+	request.SessionID = req.Session.SessionID
+	request.SpanID = otelsetup.GetSpanID(ctx)
+	request.RequestedAt = time.Now()
+
+	err := h.RequestsQueue.PushMessage(ctx, request)
+	if err != nil {
+		return alexa.Response{}, err
+	}
+	state.PendingRequestID = request.RequestID
+
+	deadline := time.Now().Add(time.Duration(h.PollDelay) * time.Second)
+	h.sendProgress(ctx, req, request, deadline)
+
+	return h.getResponse(ctx, req, state, deadline, false)
+}
+
+// sendProgress says which model is working on request, so the user does not
+// sit through the poll delay in silence. It gives up at deadline and a failure
+// only costs the user the interim message.
+func (h *Handler) sendProgress(ctx context.Context, req alexa.Request, request *chatmodels.Request, deadline time.Time) {
+	if h.ProgressiveResponder == nil || h.PollDelay <= 0 || req.Context.System.APIAccessToken == "" {
+		return
+	}
+	ctx, span := trace.Start(ctx, "sendProgress")
+	defer span.End()
+	ctx, cancel := context.WithDeadline(ctx, deadline)
+	defer cancel()
+
+	msgs := messagesFor(req.Body.Locale)
+	var progress string
+	switch {
+	case request.ImageModel != nil:
+		progress = msgs.get(msgProgressImage, *request.ImageModel)
+	case request.Model == chatmodels.CHAT_MODEL_TRANSLATIONS:
+		progress = msgs.get(msgProgressTranslation)
+	default:
+		progress = msgs.get(msgProgressChat, request.Model)
+	}
+
+	err := h.ProgressiveResponder.SendProgressiveResponse(ctx, req, alexa.NewSSML().Text(progress))
+	if err != nil {
+		span.RecordError(err)
+		h.Logger.
+			With("request-id", request.RequestID).
+			With("error", err).
+			Error("failed to send progressive response")
+	}
+}
+
+func (h *Handler) randomFact(ctx context.Context, msgs messages, state *UserState) (string, error) {
 	ctx, span := trace.Start(ctx, "randomFact")
 	defer span.End()
 
-	return h.ChatGptService.TextGeneration(ctx, "tell me a random fact", h.Model)
+	return h.generate(ctx, state, "", msgs.prompt("tell me a random fact"), state.Model)
 }
 
-func (h *Handler) DispatchIntents(ctx context.Context, req alexa.Request) (alexa.Response, error) {
+func (h *Handler) DispatchIntents(ctx context.Context, req alexa.Request, state *UserState) (alexa.Response, error) {
 	h.Logger.With("intent", utils.ToJSON(req)).Info("got intent")
 	ctx, span := trace.Start(ctx, "DispatchIntents")
 	defer span.End()
@@ -108,246 +188,328 @@ func (h *Handler) DispatchIntents(ctx context.Context, req alexa.Request) (alexa
 	handler, ok := h.routes[req.Body.Intent.Name]
 	if !ok {
 		h.Logger.Error("user has invoked unsupported intent")
-		return alexa.NewResponse("unsupported intent", "unsupported intent!", false), nil
+		msgs := messagesFor(req.Body.Locale)
+		return alexa.NewResponse(msgs.get(msgUnsupportedTitle), msgs.get(msgUnsupported), false), nil
 	}
-	return handler(ctx, req, xrayID)
+	return handler(ctx, req, state, xrayID)
 }
 
-func (h *Handler) handlePurge(ctx context.Context, _ alexa.Request, _ string) (alexa.Response, error) {
+func (h *Handler) handlePurge(ctx context.Context, req alexa.Request, _ *UserState, _ string) (alexa.Response, error) {
 	err := h.ResponsesQueue.Purge(ctx)
 	if err != nil {
 		return alexa.Response{}, err
 	}
-	return alexa.NewResponse("Purged", "successfully purged queue", false), nil
+	msgs := messagesFor(req.Body.Locale)
+	return alexa.NewResponse(msgs.get(msgPurgedTitle), msgs.get(msgPurged), false), nil
 }
 
-func (h *Handler) handleModel(_ context.Context, req alexa.Request, _ string) (alexa.Response, error) {
+func (h *Handler) handleModel(_ context.Context, req alexa.Request, state *UserState, _ string) (alexa.Response, error) {
 	model := req.Body.Intent.Slots["chatModel"].Value
-	return h.getOrSetModel(model)
+	return h.getOrSetModel(messagesFor(req.Body.Locale), state, model)
 }
 
-func (h *Handler) handleImage(ctx context.Context, req alexa.Request, xrayID string) (alexa.Response, error) {
+func (h *Handler) handleImage(ctx context.Context, req alexa.Request, state *UserState, xrayID string) (alexa.Response, error) {
 	prompt := req.Body.Intent.Slots["prompt"].Value
 	h.Logger.With("prompt", prompt).Info("found phrase to autocomplete")
 
-	err := h.RequestsQueue.PushMessage(ctx, &chatmodels.Request{Prompt: prompt, ImageModel: &h.ImageModel, TraceID: xrayID})
-	if err != nil {
-		return alexa.Response{}, err
-	}
-
-	return h.GetResponse(ctx, h.PollDelay, false)
+	return h.enqueue(ctx, req, state, &chatmodels.Request{Prompt: prompt, ImageModel: &state.ImageModel, TraceID: xrayID})
 }
 
-func (h *Handler) handleSystemMessage(_ context.Context, req alexa.Request, _ string) (alexa.Response, error) {
+func (h *Handler) handleSystemMessage(_ context.Context, req alexa.Request, state *UserState, _ string) (alexa.Response, error) {
 	prompt := req.Body.Intent.Slots["prompt"].Value
 	h.Logger.With("prompt", prompt).Info("settings system message")
-	h.SystemMessage = prompt
-	return alexa.NewResponse("System Message Set", "Ok", false), nil
+	state.SystemMessage = prompt
+	msgs := messagesFor(req.Body.Locale)
+	return alexa.NewResponse(msgs.get(msgSystemMessageTitle), msgs.get(msgSystemMessageSet), false), nil
 }
 
-func (h *Handler) handleSystemAutoComplete(ctx context.Context, req alexa.Request, xrayID string) (alexa.Response, error) {
+func (h *Handler) handleSystemAutoComplete(ctx context.Context, req alexa.Request, state *UserState, xrayID string) (alexa.Response, error) {
 	prompt := req.Body.Intent.Slots["prompt"].Value
 	h.Logger.With("prompt", prompt).Info("found phrase to autocomplete")
 
-	err := h.RequestsQueue.PushMessage(ctx, &chatmodels.Request{Prompt: prompt, Model: h.Model, SystemPrompt: h.SystemMessage, TraceID: xrayID})
-	if err != nil {
-		return alexa.Response{}, err
-	}
-	return h.GetResponse(ctx, h.PollDelay, false)
+	return h.enqueue(ctx, req, state, &chatmodels.Request{
+		Prompt:       prompt,
+		History:      state.Conversation.Turns,
+		Model:        state.Model,
+		SystemPrompt: state.SystemMessage,
+		TraceID:      xrayID,
+	})
 }
 
-func (h *Handler) handleTranslate(ctx context.Context, req alexa.Request, xrayID string) (alexa.Response, error) {
+func (h *Handler) handleTranslate(ctx context.Context, req alexa.Request, state *UserState, xrayID string) (alexa.Response, error) {
 	prompt := req.Body.Intent.Slots["prompt"].Value
-	spaces := strings.Split(prompt, " ")
-	sourceLanguage := spaces[0]
-	targetLanguage := spaces[2]
-	promptPhrase := strings.SplitN(prompt, targetLanguage, 2)
-	promptToTranslate := promptPhrase[1]
-
-	err := h.RequestsQueue.PushMessage(ctx, &chatmodels.Request{
-		Prompt:         promptToTranslate,
-		TargetLanguage: targetLanguage,
-		SourceLanguage: sourceLanguage,
-		Model:          chatmodels.CHAT_MODEL_TRANSLATIONS,
-		TraceID:        xrayID,
-	})
+	translation, err := parseTranslation(prompt)
 	if err != nil {
-		return alexa.Response{}, err
+		h.Logger.With("prompt", prompt).With("error", err).Info("could not parse translation request")
+		msgs := messagesFor(req.Body.Locale)
+		return alexa.NewResponse(msgs.get(msgTranslationTitle), msgs.get(msgTranslationUnclear), false), nil
+	}
+	if translation.Source == "" && h.DetectTranslationSource {
+		translation.Source, _ = h.detectLanguage(ctx, state, translation.Phrase)
+	}
+	if translation.Source == "" {
+		translation.Source = localeLanguage(req.Body.Locale)
 	}
 
-	return h.GetResponse(ctx, h.PollDelay, false)
+	model := h.TranslationModel
+	if model == "" {
+		model = state.Model
+	}
+
+	return h.enqueue(ctx, req, state, &chatmodels.Request{
+		Prompt:           translation.Phrase,
+		TargetLanguage:   translation.Target,
+		SourceLanguage:   translation.Source,
+		TranslationModel: model,
+		Glossary:         state.Glossary,
+		Romanize:         h.RomanizeTranslations && nonLatinScripts[translation.Target],
+		Model:            chatmodels.CHAT_MODEL_TRANSLATIONS,
+		TraceID:          xrayID,
+	})
 }
 
-func (h *Handler) handleAutoComplete(ctx context.Context, req alexa.Request, xrayID string) (alexa.Response, error) {
+func (h *Handler) handleAutoComplete(ctx context.Context, req alexa.Request, state *UserState, xrayID string) (alexa.Response, error) {
 	prompt := req.Body.Intent.Slots["prompt"].Value
 	h.Logger.With("prompt", prompt).Info("found phrase to autocomplete")
 
-	err := h.RequestsQueue.PushMessage(ctx, &chatmodels.Request{Prompt: prompt, Model: h.Model, TraceID: xrayID})
-	if err != nil {
-		return alexa.Response{}, err
-	}
-
-	return h.GetResponse(ctx, h.PollDelay, false)
+	return h.enqueue(ctx, req, state, &chatmodels.Request{
+		Prompt:  prompt,
+		History: state.Conversation.Turns,
+		Model:   state.Model,
+		TraceID: xrayID,
+	})
 }
 
-func (h *Handler) handleRandomFact(ctx context.Context, _ alexa.Request, _ string) (alexa.Response, error) {
+func (h *Handler) handleRandomFact(ctx context.Context, req alexa.Request, state *UserState, _ string) (alexa.Response, error) {
 	h.Logger.Debug("random fact")
+	msgs := messagesFor(req.Body.Locale)
 
 	execTime := time.Now().UTC()
-	randomFact, err := h.randomFact(ctx)
+	randomFact, err := h.randomFact(ctx, msgs, state)
 	if err != nil {
 		return alexa.Response{}, err
 	}
-	h.lastResponse = &chatmodels.LastResponse{Response: randomFact, TimeDiff: fmt.Sprintf("%.0f", time.Since(execTime).Seconds()), Model: h.Model.String()}
-	return alexa.NewResponse("Random Fact", randomFact, false), nil
+	state.setLastResponse(&chatmodels.LastResponse{Response: randomFact, TimeDiff: fmt.Sprintf("%.0f", time.Since(execTime).Seconds()), Model: state.Model.String()})
+	return markdownResponse(msgs.get(msgRandomFactTitle), randomFact), nil
 }
 
-func (h *Handler) handleBattleshipStatus(ctx context.Context, _ alexa.Request, _ string) (alexa.Response, error) {
-	alive, killed := h.BattleShips.ShipsTotals()
-	hits, misses := h.BattleShips.TotalHitsAndMisses()
+func (h *Handler) handleBattleshipStatus(ctx context.Context, req alexa.Request, state *UserState, _ string) (alexa.Response, error) {
+	alive, killed := state.BattleShips.ShipsTotals()
+	hits, misses := state.BattleShips.TotalHitsAndMisses()
+	msgs := messagesFor(req.Body.Locale)
 
 	statusStr := "the user is playing a game of battleships, tell the status update of their game, ther are %d boats still alive, %d boats have been killed. Their total hits are %d, their total misses are %d."
-	statement, _ := h.ChatGptService.TextGeneration(ctx, fmt.Sprintf(statusStr, alive, killed, hits, misses), h.Model)
-	return alexa.NewResponse("BattleShips", statement, false), nil
+	statement, _ := h.generate(ctx, state, "", msgs.prompt(fmt.Sprintf(statusStr, alive, killed, hits, misses)), state.Model)
+	res := markdownResponse(msgs.get(msgBattleshipsTitle), statement)
+	if req.SupportsAPL() {
+		res.AddDirective(alexa.NewBattleshipDocument(msgs.get(msgBattleshipsTitle), state.BattleShips.aplBoard()))
+	}
+	return res, nil
 }
 
-func (h *Handler) handleBattleships(ctx context.Context, req alexa.Request, _ string) (alexa.Response, error) {
+func (h *Handler) handleBattleships(ctx context.Context, req alexa.Request, state *UserState, _ string) (alexa.Response, error) {
 	execTime := time.Now().UTC()
+	msgs := messagesFor(req.Body.Locale)
 
 	x, ok := req.Body.Intent.Slots["x"]
 	if !ok || x.Value == "" || x.Value == "?" {
-		return alexa.NewResponse("Try again!", "Try again!", false), nil
+		return alexa.NewResponse(msgs.get(msgTryAgain), msgs.get(msgTryAgain), false), nil
 	}
 
 	y, ok := req.Body.Intent.Slots["y"]
 	if !ok || y.Value == "" || y.Value == "?" {
-		return alexa.NewResponse("Try again!", "Try again!", false), nil
+		return alexa.NewResponse(msgs.get(msgTryAgain), msgs.get(msgTryAgain), false), nil
 	}
 
 	x_cord, _ := strconv.Atoi(x.Value)
 	y_cord, _ := strconv.Atoi(y.Value)
 
+	result := state.BattleShips.Attack(x_cord, y_cord)
+	// the board as it stands after this shot, before a won game is reset
+	board := state.BattleShips.aplBoard()
+
 	var statement string
-	switch h.BattleShips.Attack(x_cord, y_cord) {
+	switch result {
 	case Hit:
-		statement, _ = h.ChatGptService.TextGeneration(ctx, "playing battleships, tell the user they hit a ship", h.Model)
+		statement, _ = h.generate(ctx, state, "", msgs.prompt("playing battleships, tell the user they hit a ship"), state.Model)
 	case Miss:
-		statement, _ = h.ChatGptService.TextGeneration(ctx, "playing battleships, tell the user they missed a ship", h.Model)
+		statement, _ = h.generate(ctx, state, "", msgs.prompt("playing battleships, tell the user they missed a ship"), state.Model)
 	case Sink:
-		statement, _ = h.ChatGptService.TextGeneration(ctx, "playing battleships, tell the user they sunk a ship", h.Model)
+		statement, _ = h.generate(ctx, state, "", msgs.prompt("playing battleships, tell the user they sunk a ship"), state.Model)
 	case GameOver:
-		statement, _ = h.ChatGptService.TextGeneration(ctx, "playing battleships, tell the user they won the game", h.Model)
-		h.BattleShips = NewBattleShipSetup()
+		statement, _ = h.generate(ctx, state, "", msgs.prompt("playing battleships, tell the user they won the game"), state.Model)
+		state.BattleShips = NewBattleShipSetup()
 	case Invalid:
-		statement, _ = h.ChatGptService.TextGeneration(ctx, "playing battleships, tell the user they made an invalid move", h.Model)
+		statement, _ = h.generate(ctx, state, "", msgs.prompt("playing battleships, tell the user they made an invalid move"), state.Model)
+	}
+	state.setLastResponse(&chatmodels.LastResponse{Response: statement, TimeDiff: fmt.Sprintf("%.0f", time.Since(execTime).Seconds()), Model: state.Model.String()})
+	res := markdownResponse(msgs.get(msgBattleshipsTitle), statement)
+	if req.SupportsAPL() {
+		res.AddDirective(alexa.NewBattleshipDocument(msgs.get(msgBattleshipsTitle), board))
 	}
-	h.lastResponse = &chatmodels.LastResponse{Response: statement, TimeDiff: fmt.Sprintf("%.0f", time.Since(execTime).Seconds()), Model: h.Model.String()}
-	return alexa.NewResponse("BattleShips", statement, false), nil
+	return res, nil
 }
 
-func (h *Handler) handleAnimalStatus(ctx context.Context, _ alexa.Request, _ string) (alexa.Response, error) {
-	status := h.AnimalGame.GetStatus()
+func (h *Handler) handleAnimalStatus(ctx context.Context, req alexa.Request, state *UserState, _ string) (alexa.Response, error) {
+	status := state.AnimalGame.GetStatus()
 
-	systemPrompt := "You are a friendly game host for players of all ages. Be encouraging, enthusiastic, and use simple language."
+	systemPrompt := messagesFor(req.Body.Locale).prompt(gameHostPrompt)
 	statusStr := "The player has %d guesses left and %d hints remaining in the animal guessing game. Tell them this information."
-	statement, _ := h.ChatGptService.TextGenerationWithSystem(ctx, systemPrompt, fmt.Sprintf(statusStr, status.GuessesLeft, status.HintsLeft), h.Model)
-	return alexa.NewResponse("Animal Game", statement, false), nil
+	statement, _ := h.generate(ctx, state, systemPrompt, fmt.Sprintf(statusStr, status.GuessesLeft, status.HintsLeft), state.Model)
+	return animalGameResponse(req, state, statement), nil
 }
 
-func (h *Handler) handleAnimalHint(ctx context.Context, _ alexa.Request, _ string) (alexa.Response, error) {
+func (h *Handler) handleAnimalHint(ctx context.Context, req alexa.Request, state *UserState, _ string) (alexa.Response, error) {
 	execTime := time.Now().UTC()
 
-	hintResult := h.AnimalGame.RequestHint()
+	hintResult := state.AnimalGame.RequestHint()
 	var statement string
-	systemPrompt := "You are a friendly game host for players of all ages. Be encouraging, enthusiastic, and use simple language."
+	msgs := messagesFor(req.Body.Locale)
+	systemPrompt := msgs.prompt(gameHostPrompt)
 
 	switch hintResult.Status {
 	case GameInactive:
-		statement, _ = h.ChatGptService.TextGenerationWithSystem(ctx, systemPrompt, "Tell the player there's no active animal guessing game. They need to start a new game by making a guess.", h.Model)
+		statement, _ = h.generate(ctx, state, systemPrompt, "Tell the player there's no active animal guessing game. They need to start a new game by making a guess.", state.Model)
 	case NoHintsLeft:
-		statement, _ = h.ChatGptService.TextGenerationWithSystem(ctx, systemPrompt, "Tell the player they have no hints left. They need to keep guessing!", h.Model)
+		statement, _ = h.generate(ctx, state, systemPrompt, "Tell the player they have no hints left. They need to keep guessing!", state.Model)
 	case HintAvailable:
-		hintSystemPrompt := "You are a friendly game host helping players of all ages guess animals. Give educational, age-appropriate hints without revealing the animal's name. Be fun and encouraging."
+		hintSystemPrompt := msgs.prompt("You are a friendly game host helping players of all ages guess animals. Give educational, age-appropriate hints without revealing the animal's name. Be fun and encouraging.")
 		hintPrompt := fmt.Sprintf("Give hint number %d about a %s. The player has %d guesses left and %d hints remaining.",
 			hintResult.HintNumber, hintResult.Animal, hintResult.GuessesLeft, hintResult.HintsLeft)
-		statement, _ = h.ChatGptService.TextGenerationWithSystem(ctx, hintSystemPrompt, hintPrompt, h.Model)
+		statement, _ = h.generate(ctx, state, hintSystemPrompt, hintPrompt, state.Model)
+		state.AnimalGame.RecordHint(statement)
 	}
-	h.lastResponse = &chatmodels.LastResponse{Response: statement, TimeDiff: fmt.Sprintf("%.0f", time.Since(execTime).Seconds()), Model: h.Model.String()}
-	return alexa.NewResponse("Animal Game", statement, false), nil
+	state.setLastResponse(&chatmodels.LastResponse{Response: statement, TimeDiff: fmt.Sprintf("%.0f", time.Since(execTime).Seconds()), Model: state.Model.String()})
+	return animalGameResponse(req, state, statement), nil
 }
 
-func (h *Handler) handleAnimalGuess(ctx context.Context, req alexa.Request, _ string) (alexa.Response, error) {
+func (h *Handler) handleAnimalGuess(ctx context.Context, req alexa.Request, state *UserState, _ string) (alexa.Response, error) {
 	execTime := time.Now().UTC()
 
+	msgs := messagesFor(req.Body.Locale)
 	guessSlot, ok := req.Body.Intent.Slots["animal"]
 	if !ok || guessSlot.Value == "" || guessSlot.Value == "?" {
-		return alexa.NewResponse("Try again!", "Please say an animal name to make your guess!", false), nil
+		return alexa.NewResponse(msgs.get(msgTryAgain), msgs.get(msgAnimalSayAName), false), nil
 	}
 
 	guess := guessSlot.Value
-	result := h.AnimalGame.MakeGuess(guess)
+	result := state.AnimalGame.MakeGuess(guess)
 
 	var statement string
-	systemPrompt := "You are a friendly game host for players of all ages. Be encouraging, enthusiastic, and use simple language."
+	systemPrompt := msgs.prompt(gameHostPrompt)
 
 	switch result.Status {
 	case GameWon:
 		congratsPrompt := fmt.Sprintf("The player correctly guessed the animal '%s'! Congratulate them enthusiastically. Then say: Here's what a %s sounds like: %s",
 			result.Animal, result.Animal, result.Sound)
-		statement, _ = h.ChatGptService.TextGenerationWithSystem(ctx, systemPrompt, congratsPrompt, h.Model)
-		h.AnimalGame.ResetGame()
+		statement, _ = h.generate(ctx, state, systemPrompt, congratsPrompt, state.Model)
+		state.AnimalGame.ResetGame()
 	case GuessIncorrect:
 		incorrectPrompt := fmt.Sprintf("The player guessed '%s' but it's wrong. They have %d guesses left. Encourage them to try again and suggest they can ask for a hint!",
 			guess, result.GuessesLeft)
-		statement, _ = h.ChatGptService.TextGenerationWithSystem(ctx, systemPrompt, incorrectPrompt, h.Model)
+		statement, _ = h.generate(ctx, state, systemPrompt, incorrectPrompt, state.Model)
 	case GameLost:
 		losePrompt := fmt.Sprintf("The player ran out of guesses! The correct animal was '%s'. Be supportive and encourage them to play again.",
 			result.Animal)
-		statement, _ = h.ChatGptService.TextGenerationWithSystem(ctx, systemPrompt, losePrompt, h.Model)
-		h.AnimalGame.ResetGame()
+		statement, _ = h.generate(ctx, state, systemPrompt, losePrompt, state.Model)
+		state.AnimalGame.ResetGame()
 	case GameInactive:
-		statement = "There's no active game! Say an animal name to start a new guessing game!"
-		h.AnimalGame.ResetGame()
+		statement = msgs.get(msgAnimalNoGame)
+		state.AnimalGame.ResetGame()
 	}
-	h.lastResponse = &chatmodels.LastResponse{Response: statement, TimeDiff: fmt.Sprintf("%.0f", time.Since(execTime).Seconds()), Model: h.Model.String()}
-	return alexa.NewResponse("Animal Game", statement, false), nil
+	state.setLastResponse(&chatmodels.LastResponse{Response: statement, TimeDiff: fmt.Sprintf("%.0f", time.Since(execTime).Seconds()), Model: state.Model.String()})
+	return animalGameResponse(req, state, statement), nil
 }
 
-func (h *Handler) handleRandomNumber(ctx context.Context, req alexa.Request, _ string) (alexa.Response, error) {
+// animalGameResponse speaks statement and shows the hints given so far on
+// devices with a screen.
+func animalGameResponse(req alexa.Request, state *UserState, statement string) alexa.Response {
+	title := messagesFor(req.Body.Locale).get(msgAnimalGameTitle)
+	res := markdownResponse(title, statement)
+	if req.SupportsAPL() {
+		status := state.AnimalGame.GetStatus()
+		res.AddDirective(alexa.NewHintsDocument(title, state.AnimalGame.Hints(), status.GuessesLeft, status.HintsLeft))
+	}
+	return res
+}
+
+func (h *Handler) handleRandomNumber(ctx context.Context, req alexa.Request, state *UserState, _ string) (alexa.Response, error) {
 	if req.Body.Intent.Slots["number"].Value == "cheat" {
-		res := alexa.NewResponse("Random Fact", fmt.Sprintf("You gave up so easily, your number is %d", h.RandomNumberSvc.Number), false)
-		h.RandomNumberSvc.ShuffleRandomNumber()
+		msgs := messagesFor(req.Body.Locale)
+		res := alexa.NewResponse(msgs.get(msgRandomFactTitle), msgs.get(msgRandomNumberCheat, state.RandomNumber.Number), false)
+		state.RandomNumber.ShuffleRandomNumber()
 		return res, nil
 	}
-	return h.RandomNumberGame(ctx, req)
+	return h.RandomNumberGame(ctx, req, state)
 }
 
-func (h *Handler) handleLastResponse(ctx context.Context, _ alexa.Request, _ string) (alexa.Response, error) {
+func (h *Handler) handleLastResponse(ctx context.Context, req alexa.Request, state *UserState, _ string) (alexa.Response, error) {
 	h.Logger.Debug("fetching last response")
-	return h.GetResponse(ctx, h.PollDelay, true)
+	return h.GetResponse(ctx, req, state, h.PollDelay, true)
 }
 
-func (h *Handler) handleHelp(_ context.Context, _ alexa.Request, _ string) (alexa.Response, error) {
-	return alexa.NewResponse(
-		"Help",
-		"simply repeat, question followed by a desired sentence, to change model simply say 'use' followed by 'gpt' or 'gemini'",
-		false,
-	), nil
+func (h *Handler) handleNext(_ context.Context, req alexa.Request, state *UserState, _ string) (alexa.Response, error) {
+	msgs := messagesFor(req.Body.Locale)
+	if !hasChunks(state) {
+		return alexa.NewResponse(msgs.get(msgResponseTitle), msgs.get(msgNothingToContinue), false), nil
+	}
+	if state.ResponseChunk+1 >= len(splitAnswer(state.LastResponse.Response, answerChunkSize)) {
+		return alexa.NewResponse(msgs.get(msgResponseTitle), msgs.get(msgAnswerEnd), false), nil
+	}
+	return answerChunk(msgs, state, state.ResponseChunk+1), nil
 }
 
-func (h *Handler) handleCancel(_ context.Context, _ alexa.Request, _ string) (alexa.Response, error) {
+func (h *Handler) handleRepeat(_ context.Context, req alexa.Request, state *UserState, _ string) (alexa.Response, error) {
+	msgs := messagesFor(req.Body.Locale)
+	if !hasChunks(state) {
+		return alexa.NewResponse(msgs.get(msgResponseTitle), msgs.get(msgNothingToRepeat), false), nil
+	}
+	return answerChunk(msgs, state, state.ResponseChunk), nil
+}
+
+func (h *Handler) handlePrevious(_ context.Context, req alexa.Request, state *UserState, _ string) (alexa.Response, error) {
+	msgs := messagesFor(req.Body.Locale)
+	if !hasChunks(state) {
+		return alexa.NewResponse(msgs.get(msgResponseTitle), msgs.get(msgNothingToGoBack), false), nil
+	}
+	return answerChunk(msgs, state, state.ResponseChunk-1), nil
+}
+
+func (h *Handler) handleNewConversation(_ context.Context, req alexa.Request, state *UserState, _ string) (alexa.Response, error) {
+	state.Conversation.Reset()
+	msgs := messagesFor(req.Body.Locale)
+	return alexa.NewResponse(msgs.get(msgNewConversationTitle), msgs.get(msgNewConversation), false), nil
+}
+
+func (h *Handler) handleForget(_ context.Context, req alexa.Request, state *UserState, _ string) (alexa.Response, error) {
+	msgs := messagesFor(req.Body.Locale)
+	if !state.Conversation.Forget() {
+		return alexa.NewResponse(msgs.get(msgForgetTitle), msgs.get(msgNothingToForget), false), nil
+	}
+	return alexa.NewResponse(msgs.get(msgForgetTitle), msgs.get(msgForgotten), false), nil
+}
+
+func (h *Handler) handleHelp(_ context.Context, req alexa.Request, _ *UserState, _ string) (alexa.Response, error) {
+	msgs := messagesFor(req.Body.Locale)
+	return alexa.NewResponse(msgs.get(msgHelpTitle), msgs.get(msgHelp), false), nil
+}
+
+func (h *Handler) handleCancel(_ context.Context, req alexa.Request, _ *UserState, _ string) (alexa.Response, error) {
 	h.Logger.Debug("user has invoked cancelled intent")
-	return alexa.NewResponse("Next Question", "okay, i'm listening", false), nil
+	msgs := messagesFor(req.Body.Locale)
+	return alexa.NewResponse(msgs.get(msgCancelTitle), msgs.get(msgCancel), false), nil
 }
 
-func (h *Handler) handleStop(_ context.Context, _ alexa.Request, _ string) (alexa.Response, error) {
+func (h *Handler) handleStop(_ context.Context, req alexa.Request, _ *UserState, _ string) (alexa.Response, error) {
 	h.Logger.Debug("user has invoked no or stop intent")
-	return alexa.NewResponse("Bye", "Good bye", true), nil
+	msgs := messagesFor(req.Body.Locale)
+	return alexa.NewResponse(msgs.get(msgStopTitle), msgs.get(msgStop), true), nil
 }
 
-func (h *Handler) handleFallback(_ context.Context, _ alexa.Request, _ string) (alexa.Response, error) {
+func (h *Handler) handleFallback(_ context.Context, req alexa.Request, _ *UserState, _ string) (alexa.Response, error) {
 	h.Logger.Debug("user has invoked fallback intent")
-	return alexa.NewResponse("Try again!", "Try again!", false), nil
+	msgs := messagesFor(req.Body.Locale)
+	return alexa.NewResponse(msgs.get(msgTryAgain), msgs.get(msgTryAgain), false), nil
 }
 
 func (h *Handler) Invoke(ctx context.Context, req alexa.Request) (resp alexa.Response, err error) {
@@ -357,18 +519,44 @@ func (h *Handler) Invoke(ctx context.Context, req alexa.Request) (resp alexa.Res
 		With("payload", utils.ToJSON(req)).
 		Debug("lambda invoked")
 
+	userID := req.Session.User.UserID
+	msgs := messagesFor(req.Body.Locale)
+	var state *UserState
+	state, err = h.loadState(ctx, userID)
+	if err != nil {
+		goto respond
+	}
+	if conversation, ok := sessionConversation(req.Session); ok {
+		state.Conversation = *conversation
+	}
+
+	if req.Body.Type == alexa.IntentRequestType {
+		recordRequest(ctx, req.Body.Intent.Name)
+	} else {
+		recordRequest(ctx, req.Body.Type)
+	}
+
 	switch req.Body.Type {
 	case alexa.LaunchRequestType:
 		h.Logger.Debug("launch request type found")
-		resp = alexa.NewResponse("chatGPT",
-			"Hi, lets begin our conversation!",
-			false,
-		)
+		resp = alexa.NewResponse(msgs.get(msgLaunchTitle), msgs.get(msgLaunch), false)
 	default:
-		resp, err = h.DispatchIntents(ctx, req)
+		resp, err = h.DispatchIntents(ctx, req, state)
+	}
+	resp.SessionAttributes = map[string]any{conversationAttribute: state.Conversation}
+
+	// responses are only deleted from the queue once the state holding them is saved
+	if saveErr := h.saveState(ctx, userID, state); saveErr != nil {
+		span.RecordError(saveErr)
+		h.Logger.
+			With("error", saveErr).
+			Error("failed to save user state")
+		h.settle(ctx, state.received, false)
+	} else {
+		h.settle(ctx, state.received, true)
 	}
-	h.LastIntent = req
 
+respond:
 	if err != nil {
 		span.RecordError(err)
 		h.Logger.
@@ -376,10 +564,7 @@ func (h *Handler) Invoke(ctx context.Context, req alexa.Request) (resp alexa.Res
 			Error("there as an error processing the request")
 
 		err = nil
-		resp = alexa.NewResponse("error",
-			"an error occurred when processing your prompt",
-			true,
-		)
+		resp = alexa.NewResponse(msgs.get(msgErrorTitle), msgs.get(msgError), true)
 	}
 
 	h.Logger.
@@ -14,6 +14,7 @@ import (
 	"github.com/jackmcguire1/alexa-chatgpt/internal/pkg/alexa"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 var (
@@ -27,7 +28,7 @@ func init() {
 
 func TestLaunchIntent(t *testing.T) {
 	mockChatGptService := &chatmodels.MockClient{}
-	h := NewHandler(logger, mockChatGptService, nil, nil, 0, "", "", nil, nil, nil)
+	h := NewHandler(logger, mockChatGptService, nil, nil, 0, "", "", nil)
 
 	req := alexa.Request{
 		Version: "",
@@ -45,7 +46,7 @@ func TestLaunchIntent(t *testing.T) {
 
 func TestFallbackIntent(t *testing.T) {
 	mockChatGptService := &chatmodels.MockClient{}
-	h := NewHandler(logger, mockChatGptService, nil, nil, 0, chatmodels.CHAT_MODEL_SONNET, "", nil, nil, nil)
+	h := NewHandler(logger, mockChatGptService, nil, nil, 0, chatmodels.CHAT_MODEL_SONNET, "", nil)
 
 	req := alexa.Request{
 		Version: "",
@@ -78,16 +79,18 @@ func TestAutoCompleteIntent(t *testing.T) {
 	mockRequestsQueue.On("PushMessage", mock.Anything, mock.Anything).Return(nil)
 
 	mockResponsesQueue := &queue.MockQueue{}
-	queueResponse := chatmodels.LastResponse{Response: "chimney", Model: chatmodels.CHAT_MODEL_SONNET.String(), TimeDiff: "1s"}
+	mockResponsesQueue.On("Ack", mock.Anything, mock.Anything).Return(nil)
+	queueResponse := chatmodels.LastResponse{RequestID: "req-1", Response: "chimney", Model: chatmodels.CHAT_MODEL_SONNET.String(), TimeDiff: "1s"}
 	jsonResp := utils.ToJSON(queueResponse)
-	mockResponsesQueue.On("PullMessage", mock.Anything, mock.Anything).Return([]byte(jsonResp), nil)
+	mockResponsesQueue.On("ReceiveMessage", mock.Anything, mock.Anything).Return([]byte(jsonResp), nil)
 
-	h := NewHandler(logger, mockChatGptService, mockResponsesQueue, mockRequestsQueue, 0, chatmodels.CHAT_MODEL_SONNET, "", nil, nil, nil)
+	h := NewHandler(logger, mockChatGptService, mockResponsesQueue, mockRequestsQueue, 0, chatmodels.CHAT_MODEL_SONNET, "", nil)
 
 	req := alexa.Request{
 		Version: "",
 		Session: alexa.Session{},
 		Body: alexa.ReqBody{
+			RequestID: "req-1",
 			Intent: alexa.Intent{
 				Name: "AutoCompleteIntent",
 				Slots: map[string]alexa.Slot{
@@ -105,7 +108,9 @@ func TestAutoCompleteIntent(t *testing.T) {
 
 	resp, err := h.Invoke(context.Background(), req)
 	assert.NoError(t, err)
-	assert.EqualValues(t, "chimney, from the sonnet model, this took 1s seconds to fetch the answer", resp.Body.OutputSpeech.Text)
+	assert.EqualValues(t, "chimney, from the sonnet model, this took 1s seconds to fetch the answer", resp.Body.Card.Text)
+	assert.Equal(t, "SSML", resp.Body.OutputSpeech.Type)
+	assert.Equal(t, `<speak><p>chimney</p><break time="400ms"/>from the sonnet model, this took 1s seconds to fetch the answer</speak>`, resp.Body.OutputSpeech.SSML)
 }
 
 func TestImageIntent(t *testing.T) {
@@ -118,19 +123,21 @@ func TestImageIntent(t *testing.T) {
 	mockRequestsQueue.On("PushMessage", mock.Anything, mock.Anything).Return(nil)
 
 	mockResponsesQueue := &queue.MockQueue{}
-	queueResponse := chatmodels.LastResponse{Response: "", Model: chatmodels.IMAGE_MODEL_FLUX.String(), TimeDiff: "1", ImagesResponse: []string{
+	mockResponsesQueue.On("Ack", mock.Anything, mock.Anything).Return(nil)
+	queueResponse := chatmodels.LastResponse{RequestID: "req-1", Response: "", Model: chatmodels.IMAGE_MODEL_FLUX.String(), TimeDiff: "1", ImagesResponse: []string{
 		smallImageUrl,
 		largeImageUrl,
 	}}
 	jsonResp := utils.ToJSON(queueResponse)
-	mockResponsesQueue.On("PullMessage", mock.Anything, mock.Anything).Return([]byte(jsonResp), nil)
+	mockResponsesQueue.On("ReceiveMessage", mock.Anything, mock.Anything).Return([]byte(jsonResp), nil)
 
-	h := NewHandler(logger, mockChatGptService, mockResponsesQueue, mockRequestsQueue, 0, chatmodels.CHAT_MODEL_SONNET, "", nil, nil, nil)
+	h := NewHandler(logger, mockChatGptService, mockResponsesQueue, mockRequestsQueue, 0, chatmodels.CHAT_MODEL_SONNET, "", nil)
 
 	req := alexa.Request{
 		Version: "",
 		Session: alexa.Session{},
 		Body: alexa.ReqBody{
+			RequestID: "req-1",
 			Intent: alexa.Intent{
 				Name: "ImageIntent",
 				Slots: map[string]alexa.Slot{
@@ -160,16 +167,18 @@ func TestImageIntentFailedToGenerateImagesResponse(t *testing.T) {
 	mockRequestsQueue.On("PushMessage", mock.Anything, mock.Anything).Return(nil)
 
 	mockResponsesQueue := &queue.MockQueue{}
-	queueResponse := chatmodels.LastResponse{Error: "image API failed", Model: chatmodels.IMAGE_MODEL_FLUX.String(), TimeDiff: "1", ImagesResponse: []string{}}
+	mockResponsesQueue.On("Ack", mock.Anything, mock.Anything).Return(nil)
+	queueResponse := chatmodels.LastResponse{RequestID: "req-1", Error: "image API failed", Model: chatmodels.IMAGE_MODEL_FLUX.String(), TimeDiff: "1", ImagesResponse: []string{}}
 	jsonResp := utils.ToJSON(queueResponse)
-	mockResponsesQueue.On("PullMessage", mock.Anything, mock.Anything).Return([]byte(jsonResp), nil)
+	mockResponsesQueue.On("ReceiveMessage", mock.Anything, mock.Anything).Return([]byte(jsonResp), nil)
 
-	h := NewHandler(logger, mockChatGptService, mockResponsesQueue, mockRequestsQueue, 0, chatmodels.CHAT_MODEL_SONNET, "", nil, nil, nil)
+	h := NewHandler(logger, mockChatGptService, mockResponsesQueue, mockRequestsQueue, 0, chatmodels.CHAT_MODEL_SONNET, "", nil)
 
 	req := alexa.Request{
 		Version: "",
 		Session: alexa.Session{},
 		Body: alexa.ReqBody{
+			RequestID: "req-1",
 			Intent: alexa.Intent{
 				Name: "ImageIntent",
 				Slots: map[string]alexa.Slot{
@@ -187,7 +196,7 @@ func TestImageIntentFailedToGenerateImagesResponse(t *testing.T) {
 
 	resp, err := h.Invoke(context.Background(), req)
 	assert.NoError(t, err)
-	assert.EqualValues(t, "I encountered an error processing your prompt, image API failed", resp.Body.OutputSpeech.Text)
+	assert.EqualValues(t, "I encountered an error processing your prompt, image API failed", resp.Body.Card.Text)
 }
 
 func TestRandomIntent(t *testing.T) {
@@ -195,7 +204,7 @@ func TestRandomIntent(t *testing.T) {
 
 	mockChatGptService.On("TextGeneration", mock.Anything, mock.Anything, mock.Anything).Return("santa fell down the chimney", nil)
 
-	h := NewHandler(logger, mockChatGptService, nil, nil, 0, chatmodels.CHAT_MODEL_SONNET, "", nil, nil, nil)
+	h := NewHandler(logger, mockChatGptService, nil, nil, 0, chatmodels.CHAT_MODEL_SONNET, "", nil)
 
 	req := alexa.Request{
 		Version: "",
@@ -218,12 +227,13 @@ func TestRandomIntent(t *testing.T) {
 
 	resp, err := h.Invoke(context.Background(), req)
 	assert.NoError(t, err)
-	assert.EqualValues(t, "santa fell down the chimney", resp.Body.OutputSpeech.Text)
+	assert.EqualValues(t, "santa fell down the chimney", resp.Body.Card.Text)
 }
 
 func TestLastResponseIntent(t *testing.T) {
 
 	mockResponsesQueue := &queue.MockQueue{}
+	mockResponsesQueue.On("Ack", mock.Anything, mock.Anything).Return(nil)
 	queueResponse := chatmodels.LastResponse{
 		Model:    chatmodels.CHAT_MODEL_SONNET.String(),
 		Response: "chimney",
@@ -231,11 +241,10 @@ func TestLastResponseIntent(t *testing.T) {
 	}
 
 	jsonResp := utils.ToJSON(queueResponse)
-	mockResponsesQueue.On("PullMessage", mock.Anything, mock.Anything).Return([]byte(jsonResp), nil)
+	mockResponsesQueue.On("ReceiveMessage", mock.Anything, mock.Anything).Return([]byte(jsonResp), nil)
 
 	mockChatGptService := &chatmodels.MockClient{}
-	h := NewHandler(logger, mockChatGptService, mockResponsesQueue, nil, 0, "", "", nil, nil, nil)
-	h.lastResponse = &queueResponse
+	h := NewHandler(logger, mockChatGptService, mockResponsesQueue, nil, 0, "", "", nil)
 
 	req := alexa.Request{
 		Version: "",
@@ -258,12 +267,12 @@ func TestLastResponseIntent(t *testing.T) {
 
 	resp, err := h.Invoke(context.Background(), req)
 	assert.NoError(t, err)
-	assert.Contains(t, resp.Body.OutputSpeech.Text, "chimney")
+	assert.Contains(t, resp.Body.Card.Text, "chimney")
 }
 
 func TestStopIntent(t *testing.T) {
 	mockChatGptService := &chatmodels.MockClient{}
-	h := NewHandler(logger, mockChatGptService, nil, nil, 0, chatmodels.CHAT_MODEL_SONNET, "", nil, nil, nil)
+	h := NewHandler(logger, mockChatGptService, nil, nil, 0, chatmodels.CHAT_MODEL_SONNET, "", nil)
 
 	req := alexa.Request{
 		Version: "",
@@ -285,7 +294,7 @@ func TestStopIntent(t *testing.T) {
 
 func TestCancelIntent(t *testing.T) {
 	mockChatGptService := &chatmodels.MockClient{}
-	h := NewHandler(logger, mockChatGptService, nil, nil, 0, chatmodels.CHAT_MODEL_SONNET, "", nil, nil, nil)
+	h := NewHandler(logger, mockChatGptService, nil, nil, 0, chatmodels.CHAT_MODEL_SONNET, "", nil)
 
 	req := alexa.Request{
 		Version: "",
@@ -307,7 +316,7 @@ func TestCancelIntent(t *testing.T) {
 
 func TestHelpIntent(t *testing.T) {
 	mockChatGptService := &chatmodels.MockClient{}
-	h := NewHandler(logger, mockChatGptService, nil, nil, 0, chatmodels.CHAT_MODEL_SONNET, "", nil, nil, nil)
+	h := NewHandler(logger, mockChatGptService, nil, nil, 0, chatmodels.CHAT_MODEL_SONNET, "", nil)
 
 	req := alexa.Request{
 		Version: "",
@@ -326,7 +335,7 @@ func TestHelpIntent(t *testing.T) {
 	assert.EqualValues(
 		t,
 		resp.Body.OutputSpeech.Text,
-		"simply repeat, question followed by a desired sentence, to change model simply say 'use' followed by 'gpt' or 'gemini'",
+		"simply repeat, question followed by a desired sentence, to change model simply say 'model' followed by a model such as 'sonnet' or 'nova pro', or say 'model available' to hear them all",
 	)
 	assert.False(t, resp.Body.ShouldEndSession)
 }
@@ -335,7 +344,7 @@ func TestModelIntentSonnet(t *testing.T) {
 	chatmodels.RegisterAvailableClients(false)
 
 	mockChatGptService := &chatmodels.MockClient{}
-	h := NewHandler(logger, mockChatGptService, nil, nil, 0, chatmodels.CHAT_MODEL_SONNET, "", nil, nil, nil)
+	h := NewHandler(logger, mockChatGptService, nil, nil, 0, chatmodels.CHAT_MODEL_SONNET, "", nil)
 
 	req := alexa.Request{
 		Version: "",
@@ -370,7 +379,7 @@ func TestModelIntentFable(t *testing.T) {
 	chatmodels.RegisterAvailableClients(false)
 
 	mockChatGptService := &chatmodels.MockClient{}
-	h := NewHandler(logger, mockChatGptService, nil, nil, 0, chatmodels.CHAT_MODEL_FABLE, "", nil, nil, nil)
+	h := NewHandler(logger, mockChatGptService, nil, nil, 0, chatmodels.CHAT_MODEL_FABLE, "", nil)
 
 	req := alexa.Request{
 		Version: "",
@@ -403,7 +412,7 @@ func TestModelIntentFable(t *testing.T) {
 
 func TestUnsupportedIntent(t *testing.T) {
 	mockChatGptService := &chatmodels.MockClient{}
-	h := NewHandler(logger, mockChatGptService, nil, nil, 0, chatmodels.CHAT_MODEL_SONNET, "", nil, nil, nil)
+	h := NewHandler(logger, mockChatGptService, nil, nil, 0, chatmodels.CHAT_MODEL_SONNET, "", nil)
 
 	req := alexa.Request{
 		Version: "",
@@ -429,7 +438,7 @@ func TestPurgeIntent(t *testing.T) {
 	mockQueue.On("Purge", mock.Anything).Return(nil)
 
 	mockChatGptService := &chatmodels.MockClient{}
-	h := NewHandler(logger, mockChatGptService, mockQueue, nil, 0, chatmodels.CHAT_MODEL_FABLE, "", nil, nil, nil)
+	h := NewHandler(logger, mockChatGptService, mockQueue, nil, 0, chatmodels.CHAT_MODEL_FABLE, "", nil)
 
 	req := alexa.Request{
 		Version: "",
@@ -452,3 +461,51 @@ func TestPurgeIntent(t *testing.T) {
 	)
 	assert.False(t, resp.Body.ShouldEndSession)
 }
+
+func TestBattleshipsShowsBoardOnDevicesWithScreens(t *testing.T) {
+	mockChatGptService := &chatmodels.MockClient{}
+	mockChatGptService.On("TextGeneration", mock.Anything, "playing battleships, tell the user they hit a ship", mock.Anything).Return("Direct hit!", nil)
+	h := NewHandler(logger, mockChatGptService, nil, nil, 0, chatmodels.CHAT_MODEL_SONNET, "", nil)
+
+	req := withAPL(alexa.Request{Body: alexa.ReqBody{
+		Type: alexa.IntentRequestType,
+		Intent: alexa.Intent{
+			Name:  alexa.BattleShipsIntent,
+			Slots: map[string]alexa.Slot{"x": {Value: "0"}, "y": {Value: "1"}},
+		},
+	}})
+	req.Session.User.UserID = "captain"
+
+	resp, err := h.Invoke(context.Background(), req)
+	assert.NoError(t, err)
+	assert.Equal(t, "Direct hit!", resp.Body.Card.Text)
+	require.Len(t, resp.Body.Directives, 1)
+
+	board := NewBattleShipSetup()
+	board.Attack(0, 1)
+	assert.Equal(t, alexa.NewBattleshipDocument("BattleShips", board.aplBoard()), resp.Body.Directives[0])
+}
+
+func TestAnimalHintShowsHintHistory(t *testing.T) {
+	mockChatGptService := &chatmodels.MockClient{}
+	mockChatGptService.On("TextGenerationWithSystem", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return("It lives on a farm.", nil)
+	h := NewHandler(logger, mockChatGptService, nil, nil, 0, chatmodels.CHAT_MODEL_SONNET, "", nil)
+
+	req := withAPL(alexa.Request{Body: alexa.ReqBody{
+		Type:   alexa.IntentRequestType,
+		Intent: alexa.Intent{Name: alexa.AnimalHintIntent},
+	}})
+	req.Session.User.UserID = "player"
+
+	resp, err := h.Invoke(context.Background(), req)
+	assert.NoError(t, err)
+	assert.Equal(t, []alexa.Directive{
+		alexa.NewHintsDocument("Animal Game", []string{"It lives on a farm."}, MaxGuesses, MaxHints-1),
+	}, resp.Body.Directives)
+
+	resp, err = h.Invoke(context.Background(), req)
+	assert.NoError(t, err)
+	assert.Equal(t, []alexa.Directive{
+		alexa.NewHintsDocument("Animal Game", []string{"It lives on a farm.", "It lives on a farm."}, MaxGuesses, MaxHints-2),
+	}, resp.Body.Directives)
+}
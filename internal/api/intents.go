@@ -0,0 +1,278 @@
+package api
+
+import (
+	"slices"
+
+	"github.com/jackmcguire1/alexa-chatgpt/internal/dom/chatmodels"
+	"github.com/jackmcguire1/alexa-chatgpt/internal/pkg/alexa"
+)
+
+const (
+	// InvocationName is what users say to open the skill in English.
+	InvocationName = "jack question"
+	// DefaultLocale is the locale of the checked in skill.json.
+	DefaultLocale = "en-US"
+
+	// ModelListSlotType holds the registry's model aliases, so Alexa resolves
+	// "model nova pro" rather than hearing a free form query.
+	ModelListSlotType = "MODEL_LIST"
+
+	searchQuery = "AMAZON.SearchQuery"
+	number      = "AMAZON.NUMBER"
+	animal      = "AMAZON.Animal"
+)
+
+// modelQueries are the Model intent values that are not aliases.
+var modelQueries = []string{"which", "available"}
+
+// invocationNames are the invocation names of locales not spoken in English.
+var invocationNames = map[string]string{
+	"de-DE": "jack frage",
+	"es-ES": "jack pregunta",
+	"fr-FR": "jack question",
+	"ja-JP": "ジャックの質問",
+}
+
+// Intents defines every intent of the interaction model. Each one, except
+// AMAZON.NavigateHomeIntent which Alexa requires but handles itself, must
+// have a route in Handler.initRoutes.
+var Intents = []alexa.IntentDefinition{
+	{Name: alexa.CancelIntent, Samples: map[string][]string{
+		"en": {"menu", "cancel"},
+		"de": {"menü", "abbrechen"},
+		"es": {"menú", "cancelar"},
+		"fr": {"menu", "annuler"},
+		"ja": {"メニュー", "キャンセル"},
+	}},
+	{Name: alexa.HelpIntent, Samples: map[string][]string{
+		"en": {"help"},
+		"de": {"hilfe"},
+		"es": {"ayuda"},
+		"fr": {"aide"},
+		"ja": {"ヘルプ"},
+	}},
+	{Name: alexa.StopIntent, Samples: map[string][]string{
+		"en": {"exit", "stop"},
+		"de": {"beenden", "stopp"},
+		"es": {"salir", "para"},
+		"fr": {"quitter", "arrête"},
+		"ja": {"終了", "ストップ"},
+	}},
+	{Name: alexa.NoIntent, Samples: map[string][]string{
+		"en": {"no", "no thanks"},
+		"de": {"nein", "nein danke"},
+		"es": {"no", "no gracias"},
+		"fr": {"non", "non merci"},
+		"ja": {"いいえ", "結構です"},
+	}},
+	{Name: alexa.NavigateHomeIntent},
+	{Name: alexa.NextIntent, Samples: map[string][]string{
+		"en":    {"continue", "keep going", "tell me more"},
+		"en-GB": {"carry on"},
+		"de":    {"weiter", "erzähl mir mehr"},
+		"es":    {"continúa", "sigue", "cuéntame más"},
+		"fr":    {"continue", "dis m'en plus"},
+		"ja":    {"続けて", "もっと教えて"},
+	}},
+	{Name: alexa.RepeatIntent, Samples: map[string][]string{
+		"en": {"repeat that", "say that again"},
+		"de": {"wiederholen", "sag das noch einmal"},
+		"es": {"repite", "dilo otra vez"},
+		"fr": {"répète", "redis-le"},
+		"ja": {"もう一度", "もう一回言って"},
+	}},
+	{Name: alexa.PreviousIntent, Samples: map[string][]string{
+		"en": {"go back"},
+		"de": {"zurück"},
+		"es": {"vuelve atrás"},
+		"fr": {"reviens en arrière"},
+		"ja": {"戻って"},
+	}},
+	{
+		Name:  alexa.AutoCompleteIntent,
+		Slots: []alexa.InteractionSlot{{Name: "prompt", Type: searchQuery}},
+		Samples: map[string][]string{
+			"en": {"what is {prompt}", "computer {prompt}", "question {prompt}"},
+			"de": {"was ist {prompt}", "computer {prompt}", "frage {prompt}"},
+			"es": {"qué es {prompt}", "ordenador {prompt}", "pregunta {prompt}"},
+			"fr": {"qu'est-ce que {prompt}", "ordinateur {prompt}", "question {prompt}"},
+			"ja": {"{prompt}とは何", "質問 {prompt}"},
+		},
+	},
+	{
+		Name:  alexa.SystemAutoCompleteIntent,
+		Slots: []alexa.InteractionSlot{{Name: "prompt", Type: searchQuery}},
+		Samples: map[string][]string{
+			"en": {"system {prompt}"},
+			"de": {"system {prompt}"},
+			"es": {"sistema {prompt}"},
+			"fr": {"système {prompt}"},
+			"ja": {"システム {prompt}"},
+		},
+	},
+	{
+		Name:  alexa.SystemMessageIntent,
+		Slots: []alexa.InteractionSlot{{Name: "prompt", Type: searchQuery}},
+		Samples: map[string][]string{
+			"en": {"set system message {prompt}"},
+			"de": {"setze systemnachricht {prompt}"},
+			"es": {"pon el mensaje del sistema {prompt}"},
+			"fr": {"définis le message système {prompt}"},
+			"ja": {"システムメッセージを設定 {prompt}"},
+		},
+	},
+	{Name: alexa.RandomFactIntent, Samples: map[string][]string{
+		"en": {"surprise me", "random fact"},
+		"de": {"überrasche mich", "zufällige tatsache"},
+		"es": {"sorpréndeme", "dato curioso"},
+		"fr": {"surprends-moi", "anecdote"},
+		"ja": {"驚かせて", "豆知識"},
+	}},
+	{Name: alexa.LastResponseIntent, Samples: map[string][]string{
+		"en": {"last response"},
+		"de": {"letzte antwort"},
+		"es": {"última respuesta"},
+		"fr": {"dernière réponse"},
+		"ja": {"前回の回答"},
+	}},
+	{Name: alexa.NewConversationIntent, Samples: map[string][]string{
+		"en": {"start a new conversation", "new conversation", "start over"},
+		"de": {"neues gespräch beginnen", "neues gespräch", "von vorne"},
+		"es": {"empieza una nueva conversación", "nueva conversación", "empezar de nuevo"},
+		"fr": {"commence une nouvelle conversation", "nouvelle conversation", "recommence"},
+		"ja": {"新しい会話を始めて", "新しい会話", "最初から"},
+	}},
+	{Name: alexa.ForgetIntent, Samples: map[string][]string{
+		"en": {"forget that", "forget the last answer"},
+		"de": {"vergiss das", "vergiss die letzte antwort"},
+		"es": {"olvida eso", "olvida la última respuesta"},
+		"fr": {"oublie ça", "oublie la dernière réponse"},
+		"ja": {"それを忘れて", "前回の回答を忘れて"},
+	}},
+	{Name: alexa.UsageIntent, Samples: map[string][]string{
+		"en": {"how much have I used", "how many tokens have I used", "what has it cost"},
+		"de": {"wie viel habe ich verbraucht", "wie viele tokens habe ich verbraucht"},
+		"es": {"cuánto he usado", "cuántos tokens he usado"},
+		"fr": {"combien ai-je utilisé", "combien de tokens ai-je utilisé"},
+		"ja": {"どれくらい使った", "トークンをいくつ使った"},
+	}},
+	{Name: alexa.FallbackIntent},
+	{
+		Name:  alexa.ModelIntent,
+		Slots: []alexa.InteractionSlot{{Name: "chatModel", Type: ModelListSlotType}},
+		Samples: map[string][]string{
+			"en": {"model {chatModel}", "use {chatModel}"},
+			"de": {"modell {chatModel}", "benutze {chatModel}"},
+			"es": {"modelo {chatModel}", "usa {chatModel}"},
+			"fr": {"modèle {chatModel}", "utilise {chatModel}"},
+			"ja": {"モデル {chatModel}", "{chatModel}を使って"},
+		},
+	},
+	{Name: alexa.PurgeIntent, Samples: map[string][]string{
+		"en": {"purge"},
+		"de": {"leeren"},
+		"es": {"vaciar"},
+		"fr": {"vider"},
+		"ja": {"消去"},
+	}},
+	{
+		Name:  alexa.ImageIntent,
+		Slots: []alexa.InteractionSlot{{Name: "prompt", Type: searchQuery}},
+		Samples: map[string][]string{
+			"en": {"image {prompt}"},
+			"de": {"bild {prompt}"},
+			"es": {"imagen {prompt}"},
+			"fr": {"image {prompt}"},
+			"ja": {"画像 {prompt}"},
+		},
+	},
+	{
+		Name:  alexa.TranslateIntent,
+		Slots: []alexa.InteractionSlot{{Name: "prompt", Type: searchQuery}},
+		Samples: map[string][]string{
+			"en": {"translate {prompt}"},
+			"de": {"übersetze {prompt}"},
+			"es": {"traduce {prompt}"},
+			"fr": {"traduis {prompt}"},
+			"ja": {"翻訳 {prompt}"},
+		},
+	},
+	{
+		Name:  alexa.GlossaryIntent,
+		Slots: []alexa.InteractionSlot{{Name: "prompt", Type: searchQuery}},
+		Samples: map[string][]string{
+			"en": {"always translate {prompt}"},
+			"de": {"übersetze immer {prompt}"},
+			"es": {"traduce siempre {prompt}"},
+			"fr": {"traduis toujours {prompt}"},
+			"ja": {"いつも次のように翻訳して {prompt}"},
+		},
+	},
+	{
+		Name:  alexa.RandomNumberIntent,
+		Slots: []alexa.InteractionSlot{{Name: "number", Type: searchQuery}},
+		Samples: map[string][]string{
+			"en": {"guess {number}"},
+			"de": {"rate {number}"},
+			"es": {"adivino {number}"},
+			"fr": {"je devine {number}"},
+			"ja": {"数字は {number}"},
+		},
+	},
+	{
+		Name:  alexa.BattleShipsIntent,
+		Slots: []alexa.InteractionSlot{{Name: "x", Type: number}, {Name: "y", Type: number}},
+		Samples: map[string][]string{
+			"en": {"battleship {x} {y}"},
+			"de": {"schiffe versenken {x} {y}"},
+			"es": {"hundir la flota {x} {y}"},
+			"fr": {"bataille navale {x} {y}"},
+			"ja": {"戦艦 {x} {y}"},
+		},
+	},
+	{Name: alexa.BattleshipStatusIntent, Samples: map[string][]string{
+		"en": {"battleship status"},
+		"de": {"schiffe versenken status"},
+		"es": {"estado de hundir la flota"},
+		"fr": {"état de la bataille navale"},
+		"ja": {"戦艦の状況"},
+	}},
+	{
+		Name:  alexa.AnimalGuessIntent,
+		Slots: []alexa.InteractionSlot{{Name: "animal", Type: animal}},
+		Samples: map[string][]string{
+			"en": {"animal {animal}", "guess animal {animal}", "is it a {animal}"},
+			"de": {"tier {animal}", "ist es ein {animal}"},
+			"es": {"animal {animal}", "es un {animal}"},
+			"fr": {"animal {animal}", "est-ce un {animal}"},
+			"ja": {"動物 {animal}", "{animal}ですか"},
+		},
+	},
+	{Name: alexa.AnimalHintIntent, Samples: map[string][]string{
+		"en": {"tell me a animal hint", "animal hint"},
+		"de": {"gib mir einen tiertipp", "tiertipp"},
+		"es": {"dame una pista del animal", "pista del animal"},
+		"fr": {"donne-moi un indice sur l'animal", "indice animal"},
+		"ja": {"動物のヒントをちょうだい", "動物のヒント"},
+	}},
+	{Name: alexa.AnimalStatusIntent, Samples: map[string][]string{
+		"en": {"status animal", "animal status"},
+		"de": {"tierspiel status"},
+		"es": {"estado del juego de animales"},
+		"fr": {"état du jeu des animaux"},
+		"ja": {"動物当てゲームの状況"},
+	}},
+}
+
+// NewInteractionModel builds the skill.json interaction model of locale from
+// Intents, with the model registry's aliases as the MODEL_LIST slot type.
+func NewInteractionModel(locale string) alexa.InteractionModel {
+	models := slices.Concat(chatmodels.GetModelAliases(), modelQueries)
+	invocationName, ok := invocationNames[locale]
+	if !ok {
+		invocationName = InvocationName
+	}
+	return alexa.NewInteractionModel(invocationName, locale, Intents, []alexa.SlotType{
+		alexa.NewSlotType(ModelListSlotType, models),
+	})
+}
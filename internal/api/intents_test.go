@@ -0,0 +1,52 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/jackmcguire1/alexa-chatgpt/internal/pkg/alexa"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIntentsMatchRoutes(t *testing.T) {
+	h := NewHandler(logger, nil, nil, nil, 0, "", "", nil)
+
+	defined := map[string]bool{}
+	for _, intent := range Intents {
+		assert.False(t, defined[intent.Name], "%s is defined twice", intent.Name)
+		defined[intent.Name] = true
+		if intent.Name != alexa.NavigateHomeIntent {
+			assert.Contains(t, h.routes, intent.Name, "%s has no route", intent.Name)
+		}
+	}
+	for name := range h.routes {
+		assert.True(t, defined[name], "%s is routed but not in the interaction model", name)
+	}
+}
+
+func TestInteractionModelListsModelAliases(t *testing.T) {
+	model := NewInteractionModel("en-GB").InteractionModel.LanguageModel
+	assert.Equal(t, InvocationName, model.InvocationName)
+
+	var models []string
+	for _, value := range model.Types[0].Values {
+		models = append(models, value.Name.Value)
+	}
+	assert.Equal(t, ModelListSlotType, model.Types[0].Name)
+	assert.Subset(t, models, []string{"sonnet", "nova pro", "flux", "which", "available"})
+
+	for _, intent := range model.Intents {
+		if intent.Name == alexa.NextIntent {
+			assert.Equal(t, []string{"carry on", "continue", "keep going", "tell me more"}, intent.Samples)
+		}
+	}
+}
+
+func TestIntentsHaveSamplesInEveryLocale(t *testing.T) {
+	for _, locale := range Locales {
+		for _, intent := range Intents {
+			if len(intent.Samples) > 0 {
+				assert.NotEmpty(t, intent.SamplesFor(locale), "%s has no %s samples", intent.Name, locale)
+			}
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package api
+
+import (
+	"embed"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// messageKey names a user facing message in the locale catalogs.
+type messageKey string
+
+const (
+	msgLaunchTitle          messageKey = "launch_title"
+	msgLaunch               messageKey = "launch"
+	msgErrorTitle           messageKey = "error_title"
+	msgError                messageKey = "error"
+	msgUnsupportedTitle     messageKey = "unsupported_title"
+	msgUnsupported          messageKey = "unsupported"
+	msgTryAgain             messageKey = "try_again"
+	msgPurgedTitle          messageKey = "purged_title"
+	msgPurged               messageKey = "purged"
+	msgSystemMessageTitle   messageKey = "system_message_title"
+	msgSystemMessageSet     messageKey = "system_message_set"
+	msgHelpTitle            messageKey = "help_title"
+	msgHelp                 messageKey = "help"
+	msgCancelTitle          messageKey = "cancel_title"
+	msgCancel               messageKey = "cancel"
+	msgStopTitle            messageKey = "stop_title"
+	msgStop                 messageKey = "stop"
+	msgResponseTitle        messageKey = "response_title"
+	msgResponsePending      messageKey = "response_pending"
+	msgNoLastResponse       messageKey = "no_last_response"
+	msgPartialNote          messageKey = "partial_note"
+	msgResponseError        messageKey = "response_error"
+	msgImageNote            messageKey = "image_note"
+	msgTranslationIntro     messageKey = "translation_intro"
+	msgTranslationNote      messageKey = "translation_note"
+	msgTranslationTitle     messageKey = "translation_title"
+	msgTranslationUnclear   messageKey = "translation_unclear"
+	msgGlossaryTitle        messageKey = "glossary_title"
+	msgGlossarySet          messageKey = "glossary_set"
+	msgGlossaryUnclear      messageKey = "glossary_unclear"
+	msgGlossaryFull         messageKey = "glossary_full"
+	msgUsageTitle           messageKey = "usage_title"
+	msgUsageNone            messageKey = "usage_none"
+	msgUsageTotal           messageKey = "usage_total"
+	msgUsageModel           messageKey = "usage_model"
+	msgAnswerNote           messageKey = "answer_note"
+	msgProgressChat         messageKey = "progress_chat"
+	msgProgressImage        messageKey = "progress_image"
+	msgProgressTranslation  messageKey = "progress_translation"
+	msgContinuePrompt       messageKey = "continue_prompt"
+	msgNothingToContinue    messageKey = "nothing_to_continue"
+	msgAnswerEnd            messageKey = "answer_end"
+	msgNothingToRepeat      messageKey = "nothing_to_repeat"
+	msgNothingToGoBack      messageKey = "nothing_to_go_back"
+	msgNewConversationTitle messageKey = "new_conversation_title"
+	msgNewConversation      messageKey = "new_conversation"
+	msgForgetTitle          messageKey = "forget_title"
+	msgNothingToForget      messageKey = "nothing_to_forget"
+	msgForgotten            messageKey = "forgotten"
+	msgChatModelsTitle      messageKey = "chat_models_title"
+	msgImageModelsTitle     messageKey = "image_models_title"
+	msgModelsTitle          messageKey = "models_title"
+	msgModelSet             messageKey = "model_set"
+	msgModelWhich           messageKey = "model_which"
+	msgModelsAvailable      messageKey = "models_available"
+	msgRandomFactTitle      messageKey = "random_fact_title"
+	msgBattleshipsTitle     messageKey = "battleships_title"
+	msgAnimalGameTitle      messageKey = "animal_game_title"
+	msgAnimalSayAName       messageKey = "animal_say_a_name"
+	msgAnimalNoGame         messageKey = "animal_no_game"
+	msgRandomNumberTitle    messageKey = "random_number_title"
+	msgRandomNumberCheat    messageKey = "random_number_cheat"
+	msgRandomNumberError    messageKey = "random_number_error"
+)
+
+// Locales are the locales the skill speaks. Requests in any other locale are
+// answered in a locale of the same language, or else in DefaultLocale.
+var Locales = []string{DefaultLocale, "en-GB", "de-DE", "es-ES", "fr-FR", "ja-JP"}
+
+// replyLanguages tells the models which language to answer in for locales
+// other than DefaultLocale.
+var replyLanguages = map[string]string{
+	"en-GB": "British English",
+	"de-DE": "German",
+	"es-ES": "Spanish",
+	"fr-FR": "French",
+	"ja-JP": "Japanese",
+}
+
+//go:embed locales/*.yaml
+var localeFiles embed.FS
+
+var catalog = mustLoadCatalog()
+
+func mustLoadCatalog() map[string]map[messageKey]string {
+	catalog := make(map[string]map[messageKey]string, len(Locales))
+	for _, locale := range Locales {
+		data, err := localeFiles.ReadFile("locales/" + locale + ".yaml")
+		if err != nil {
+			panic(err)
+		}
+		var msgs map[messageKey]string
+		if err := yaml.Unmarshal(data, &msgs); err != nil {
+			panic(fmt.Sprintf("invalid %s messages: %v", locale, err))
+		}
+		catalog[locale] = msgs
+	}
+	return catalog
+}
+
+// messages are the catalog entries of a single locale.
+type messages struct {
+	locale string
+}
+
+// messagesFor returns the messages of the supported locale closest to locale.
+func messagesFor(locale string) messages {
+	if _, ok := catalog[locale]; ok {
+		return messages{locale: locale}
+	}
+	language, _, _ := strings.Cut(locale, "-")
+	for _, supported := range Locales {
+		if strings.HasPrefix(supported, language+"-") {
+			return messages{locale: supported}
+		}
+	}
+	return messages{locale: DefaultLocale}
+}
+
+// get formats the message key with args, falling back to DefaultLocale for
+// keys the locale is missing.
+func (m messages) get(key messageKey, args ...any) string {
+	msg, ok := catalog[m.locale][key]
+	if !ok {
+		msg = catalog[DefaultLocale][key]
+	}
+	if len(args) == 0 {
+		return msg
+	}
+	return fmt.Sprintf(msg, args...)
+}
+
+// prompt asks a model to answer in the locale's language.
+func (m messages) prompt(prompt string) string {
+	language, ok := replyLanguages[m.locale]
+	if !ok {
+		return prompt
+	}
+	return fmt.Sprintf("%s Reply in %s.", prompt, language)
+}
@@ -0,0 +1,87 @@
+package api
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/jackmcguire1/alexa-chatgpt/internal/dom/chatmodels"
+	"github.com/jackmcguire1/alexa-chatgpt/internal/pkg/alexa"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var verbPattern = regexp.MustCompile(`%[a-z]`)
+
+func TestCatalogsTranslateEveryMessage(t *testing.T) {
+	reference := catalog[DefaultLocale]
+	for _, locale := range Locales {
+		t.Run(locale, func(t *testing.T) {
+			msgs := catalog[locale]
+			assert.Len(t, msgs, len(reference))
+			for key, want := range reference {
+				got, ok := msgs[key]
+				if !assert.True(t, ok, "missing %s", key) {
+					continue
+				}
+				assert.NotEmpty(t, got, key)
+				assert.Equal(t, verbPattern.FindAllString(want, -1), verbPattern.FindAllString(got, -1), key)
+			}
+		})
+	}
+}
+
+func TestMessagesFor(t *testing.T) {
+	tests := map[string]string{
+		"en-US": "en-US",
+		"en-GB": "en-GB",
+		"de-DE": "de-DE",
+		"de-AT": "de-DE",
+		"es-MX": "es-ES",
+		"fr-CA": "fr-FR",
+		"ja-JP": "ja-JP",
+		"en-IN": "en-US",
+		"pt-BR": DefaultLocale,
+		"":      DefaultLocale,
+	}
+	for locale, want := range tests {
+		assert.Equal(t, want, messagesFor(locale).locale, locale)
+	}
+
+	assert.Equal(t, "deine Antwort ist gleich verfügbar", messagesFor("de-DE").get(msgResponsePending))
+	assert.Equal(t, "Ich verwende das Textmodell sonnet und das Bildmodell flux", messagesFor("de-DE").get(msgModelWhich, "sonnet", "flux"))
+	assert.Equal(t, "tell me a joke", messagesFor("en-US").prompt("tell me a joke"))
+	assert.Equal(t, "tell me a joke Reply in Japanese.", messagesFor("ja-JP").prompt("tell me a joke"))
+}
+
+func TestResponsesAreInTheRequestLocale(t *testing.T) {
+	h := NewHandler(logger, &chatmodels.MockClient{}, nil, nil, 0, chatmodels.CHAT_MODEL_SONNET, "", nil)
+
+	req := alexa.Request{Body: alexa.ReqBody{
+		Type:   alexa.IntentRequestType,
+		Locale: "fr-FR",
+		Intent: alexa.Intent{Name: alexa.StopIntent},
+	}}
+	resp, err := h.Invoke(context.Background(), req)
+	assert.NoError(t, err)
+	assert.Equal(t, "Au revoir", resp.Body.Card.Title)
+	assert.Equal(t, "Au revoir", resp.Body.OutputSpeech.Text)
+	assert.True(t, resp.Body.ShouldEndSession)
+}
+
+func TestGameHostRepliesInTheRequestLocale(t *testing.T) {
+	mockChatGptService := &chatmodels.MockClient{}
+	mockChatGptService.On("TextGenerationWithSystem", mock.Anything, gameHostPrompt+" Reply in German.", mock.Anything, chatmodels.CHAT_MODEL_SONNET).Return("Du hast noch 10 Versuche.", nil).Once()
+	h := NewHandler(logger, mockChatGptService, nil, nil, 0, chatmodels.CHAT_MODEL_SONNET, "", nil)
+
+	req := alexa.Request{Body: alexa.ReqBody{
+		Type:   alexa.IntentRequestType,
+		Locale: "de-DE",
+		Intent: alexa.Intent{Name: alexa.AnimalStatusIntent},
+	}}
+	resp, err := h.Invoke(context.Background(), req)
+	assert.NoError(t, err)
+	assert.Equal(t, "Tierspiel", resp.Body.Card.Title)
+	assert.Equal(t, "Du hast noch 10 Versuche.", resp.Body.Card.Text)
+	mockChatGptService.AssertExpectations(t)
+}
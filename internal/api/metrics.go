@@ -0,0 +1,62 @@
+package api
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackmcguire1/alexa-chatgpt/internal/dom/chatmodels"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+var meter = otel.Meter("prompt-requester")
+
+// Outcomes of polling the responses queue for a user's response.
+const (
+	pollHit     = "hit"
+	pollTimeout = "timeout"
+	pollEmpty   = "empty"
+)
+
+var (
+	requestCounter, _ = meter.Int64Counter(
+		"alexa.requests",
+		metric.WithDescription("Alexa requests by intent, or by request type outside intents"),
+		metric.WithUnit("{request}"),
+	)
+	responseLatency, _ = meter.Float64Histogram(
+		"alexa.response.latency",
+		metric.WithDescription("Time from pushing a request onto the requests queue to receiving its response"),
+		metric.WithUnit("s"),
+	)
+	pollCounter, _ = meter.Int64Counter(
+		"alexa.queue.polls",
+		metric.WithDescription("Polls of the responses queue by outcome: hit, timeout with only other responses seen, or empty"),
+		metric.WithUnit("{poll}"),
+	)
+	pendingCounter, _ = meter.Int64Counter(
+		"alexa.responses.pending",
+		metric.WithDescription(`Responses telling the user their answer is "available shortly"`),
+		metric.WithUnit("{response}"),
+	)
+)
+
+func recordRequest(ctx context.Context, intent string) {
+	requestCounter.Add(ctx, 1, metric.WithAttributes(attribute.String("intent", intent)))
+}
+
+func recordPoll(ctx context.Context, outcome string) {
+	pollCounter.Add(ctx, 1, metric.WithAttributes(attribute.String("outcome", outcome)))
+}
+
+// recordLatency records how long response took to arrive after its request
+// was pushed, for responses that say when that was.
+func recordLatency(ctx context.Context, response *chatmodels.LastResponse) {
+	if response.RequestedAt.IsZero() || response.Partial {
+		return
+	}
+	responseLatency.Record(ctx, time.Since(response.RequestedAt).Seconds(), metric.WithAttributes(
+		attribute.String("model", response.Model),
+	))
+}
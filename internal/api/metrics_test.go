@@ -0,0 +1,109 @@
+package api
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackmcguire1/alexa-chatgpt/internal/dom/chatmodels"
+	"github.com/jackmcguire1/alexa-chatgpt/internal/otel/metrictest"
+	"github.com/jackmcguire1/alexa-chatgpt/internal/pkg/alexa"
+	"github.com/jackmcguire1/alexa-chatgpt/internal/pkg/queue"
+	"github.com/jackmcguire1/alexa-chatgpt/internal/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// metricDelta returns how much the metric grows while fn runs.
+func metricDelta(t *testing.T, name string, fn func(), attrs ...attribute.KeyValue) float64 {
+	before := metrictest.Sum(t, name, attrs...)
+	fn()
+	return metrictest.Sum(t, name, attrs...) - before
+}
+
+func TestRequestsAreCountedPerIntent(t *testing.T) {
+	h := NewHandler(logger, &chatmodels.MockClient{}, &queue.MockQueue{}, &queue.MockQueue{}, 0, chatmodels.CHAT_MODEL_SONNET, "", nil)
+
+	helps := metricDelta(t, "alexa.requests", func() {
+		for range 2 {
+			_, err := h.Invoke(context.Background(), intentRequest("alice", alexa.HelpIntent, nil))
+			require.NoError(t, err)
+		}
+	}, attribute.String("intent", alexa.HelpIntent))
+	assert.Equal(t, float64(2), helps)
+
+	launches := metricDelta(t, "alexa.requests", func() {
+		_, err := h.Invoke(context.Background(), alexa.Request{Body: alexa.ReqBody{Type: alexa.LaunchRequestType}})
+		require.NoError(t, err)
+	}, attribute.String("intent", alexa.LaunchRequestType))
+	assert.Equal(t, float64(1), launches)
+}
+
+func TestQueuePollOutcomes(t *testing.T) {
+	hit := attribute.String("outcome", pollHit)
+	empty := attribute.String("outcome", pollEmpty)
+	timeout := attribute.String("outcome", pollTimeout)
+
+	t.Run("hit", func(t *testing.T) {
+		mockResponsesQueue := &queue.MockQueue{}
+		mockResponsesQueue.On("Ack", mock.Anything, mock.Anything).Return(nil)
+		mockResponsesQueue.On("ReceiveMessage", mock.Anything, mock.Anything).Return([]byte(utils.ToJSON(&chatmodels.LastResponse{
+			UserID:      "alice",
+			Response:    "hello",
+			Model:       "sonnet",
+			RequestedAt: time.Now().Add(-2 * time.Second),
+		})), nil).Once()
+		h := NewHandler(logger, &chatmodels.MockClient{}, mockResponsesQueue, &queue.MockQueue{}, 1, chatmodels.CHAT_MODEL_SONNET, "", nil)
+
+		var hits float64
+		latencies := metricDelta(t, "alexa.response.latency", func() {
+			hits = metricDelta(t, "alexa.queue.polls", func() {
+				_, err := h.Invoke(context.Background(), lastResponseRequest("alice"))
+				require.NoError(t, err)
+			}, hit)
+		}, attribute.String("model", "sonnet"))
+		assert.Equal(t, float64(1), hits)
+		assert.Equal(t, float64(1), latencies)
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		mockResponsesQueue := &queue.MockQueue{}
+		mockResponsesQueue.On("Ack", mock.Anything, mock.Anything).Return(nil)
+		mockResponsesQueue.On("ReceiveMessage", mock.Anything, mock.Anything).Return(nil, queue.EmptyMessageErr)
+		mockRequestsQueue := &queue.MockQueue{}
+		mockRequestsQueue.On("PushMessage", mock.Anything, mock.Anything).Return(nil)
+		h := NewHandler(logger, &chatmodels.MockClient{}, mockResponsesQueue, mockRequestsQueue, 0, chatmodels.CHAT_MODEL_SONNET, "", nil)
+
+		var empties float64
+		pending := metricDelta(t, "alexa.responses.pending", func() {
+			empties = metricDelta(t, "alexa.queue.polls", func() {
+				resp, err := h.Invoke(context.Background(), intentRequest("alice", alexa.AutoCompleteIntent, map[string]alexa.Slot{
+					"prompt": {Name: "prompt", Value: "why is the sky blue"},
+				}))
+				require.NoError(t, err)
+				assert.Contains(t, resp.Body.OutputSpeech.Text, "available shortly")
+			}, empty)
+		})
+		assert.Equal(t, float64(1), empties)
+		assert.Equal(t, float64(1), pending)
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		mockResponsesQueue := &queue.MockQueue{}
+		mockResponsesQueue.On("Ack", mock.Anything, mock.Anything).Return(nil)
+		mockResponsesQueue.On("ReceiveMessage", mock.Anything, mock.Anything).Return([]byte(utils.ToJSON(&chatmodels.LastResponse{
+			UserID:   "bob",
+			Response: "hello",
+		})), nil).Once()
+		mockResponsesQueue.On("Nack", mock.Anything, mock.Anything).Return(nil)
+		h := NewHandler(logger, &chatmodels.MockClient{}, mockResponsesQueue, &queue.MockQueue{}, 0, chatmodels.CHAT_MODEL_SONNET, "", nil)
+
+		timeouts := metricDelta(t, "alexa.queue.polls", func() {
+			_, err := h.Invoke(context.Background(), lastResponseRequest("alice"))
+			require.NoError(t, err)
+		}, timeout)
+		assert.Equal(t, float64(1), timeouts)
+	})
+}
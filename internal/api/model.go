@@ -8,48 +8,40 @@ import (
 	"github.com/jackmcguire1/alexa-chatgpt/internal/pkg/alexa"
 )
 
-const (
-	responseTitleChatModels  = "Chat Models"
-	responseTitleImageModels = "Image Models"
-	responseTitleModels      = "Models"
-	responseOK               = "ok"
-)
-
-func (h *Handler) setChatModel(config chatmodels.ModelConfig) alexa.Response {
+func (h *Handler) setChatModel(msgs messages, state *UserState, config chatmodels.ModelConfig) alexa.Response {
 	if !chatmodels.IsModelAvailable(config.ChatModel) {
-		return alexa.NewResponse(responseTitleChatModels, config.ErrorMessage, false)
+		return alexa.NewResponse(msgs.get(msgChatModelsTitle), config.ErrorMessage, false)
 	}
-	h.Model = config.ChatModel
-	return alexa.NewResponse(responseTitleChatModels, responseOK, false)
+	state.Model = config.ChatModel
+	return alexa.NewResponse(msgs.get(msgChatModelsTitle), msgs.get(msgModelSet), false)
 }
 
-func (h *Handler) setImageModel(config chatmodels.ModelConfig) alexa.Response {
+func (h *Handler) setImageModel(msgs messages, state *UserState, config chatmodels.ModelConfig) alexa.Response {
 	if !chatmodels.IsImageModelAvailable(config.ImageModel) {
-		return alexa.NewResponse(responseTitleImageModels, config.ErrorMessage, false)
+		return alexa.NewResponse(msgs.get(msgImageModelsTitle), config.ErrorMessage, false)
 	}
-	h.ImageModel = config.ImageModel
-	return alexa.NewResponse(responseTitleImageModels, responseOK, false)
+	state.ImageModel = config.ImageModel
+	return alexa.NewResponse(msgs.get(msgImageModelsTitle), msgs.get(msgModelSet), false)
 }
 
-func (h *Handler) getOrSetModel(model string) (res alexa.Response, err error) {
+func (h *Handler) getOrSetModel(msgs messages, state *UserState, model string) (res alexa.Response, err error) {
 	lowerModel := strings.ToLower(model)
 
 	// Check chat models
 	if config, ok := chatmodels.GetChatModelByAlias(lowerModel); ok {
-		return h.setChatModel(config), nil
+		return h.setChatModel(msgs, state, config), nil
 	}
 
 	// Check image models
 	if config, ok := chatmodels.GetImageModelByAlias(lowerModel); ok {
-		return h.setImageModel(config), nil
+		return h.setImageModel(msgs, state, config), nil
 	}
 
 	// Special cases
 	switch lowerModel {
 	case "which":
-		res = alexa.NewResponse(responseTitleChatModels,
-			fmt.Sprintf("I am using the text-model %s and image-model %s",
-				h.Model.String(), h.ImageModel.String()), false)
+		res = alexa.NewResponse(msgs.get(msgChatModelsTitle),
+			msgs.get(msgModelWhich, state.Model.String(), state.ImageModel.String()), false)
 		return
 	default:
 		// Build formatted list with alias -> provider model mapping
@@ -67,8 +59,8 @@ func (h *Handler) getOrSetModel(model string) (res alexa.Response, err error) {
 		}
 
 		res = alexa.NewResponse(
-			responseTitleModels,
-			fmt.Sprintf("The available models are, TEXT MODELS: \n - %s\n\nIMAGE MODELS: \n - %s",
+			msgs.get(msgModelsTitle),
+			msgs.get(msgModelsAvailable,
 				strings.Join(chatModelsList, "\n - "),
 				strings.Join(imageModelsList, "\n - ")),
 			false,
@@ -0,0 +1,110 @@
+package api
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/jackmcguire1/alexa-chatgpt/internal/dom/chatmodels"
+	"github.com/jackmcguire1/alexa-chatgpt/internal/pkg/alexa"
+)
+
+const (
+	// answerChunkSize is roughly how many characters of an answer are spoken
+	// at a time, about thirty seconds of speech.
+	answerChunkSize = 600
+)
+
+// splitAnswer splits a markdown answer into chunks of about size characters,
+// breaking between lines or sentences. Code blocks are never split, and a
+// single sentence longer than size makes a chunk of its own.
+func splitAnswer(answer string, size int) []string {
+	var (
+		chunks  []string
+		current strings.Builder
+		inCode  bool
+		code    strings.Builder
+	)
+	add := func(piece string, sep string) {
+		if current.Len() > 0 && current.Len()+len(sep)+len(piece) > size {
+			chunks = append(chunks, current.String())
+			current.Reset()
+		}
+		if current.Len() > 0 {
+			current.WriteString(sep)
+		}
+		current.WriteString(piece)
+	}
+
+	for _, line := range strings.Split(strings.TrimSpace(answer), "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			code.WriteString(line)
+			if inCode {
+				add(code.String(), "\n")
+				code.Reset()
+			} else {
+				code.WriteString("\n")
+			}
+			inCode = !inCode
+			continue
+		}
+		if inCode {
+			code.WriteString(line + "\n")
+			continue
+		}
+
+		for i, sentence := range splitSentences(line) {
+			sep := "\n"
+			if i > 0 {
+				sep = " "
+			}
+			add(sentence, sep)
+		}
+	}
+	if code.Len() > 0 {
+		add(strings.TrimSuffix(code.String(), "\n"), "\n")
+	}
+	if current.Len() > 0 {
+		chunks = append(chunks, current.String())
+	}
+	return chunks
+}
+
+// splitSentences splits a line after each full stop, question or exclamation
+// mark that is followed by a space.
+func splitSentences(line string) []string {
+	var sentences []string
+	start := 0
+	for i := 0; i < len(line)-1; i++ {
+		if strings.ContainsRune(".!?", rune(line[i])) && unicode.IsSpace(rune(line[i+1])) {
+			if sentence := strings.TrimSpace(line[start : i+1]); sentence != "" {
+				sentences = append(sentences, sentence)
+			}
+			start = i + 1
+		}
+	}
+	return append(sentences, strings.TrimSpace(line[start:]))
+}
+
+// answerChunk speaks chunk i of the user's last answer, showing the whole
+// answer on the card.
+func answerChunk(msgs messages, state *UserState, i int) alexa.Response {
+	chunks := splitAnswer(state.LastResponse.Response, answerChunkSize)
+	i = min(max(i, 0), len(chunks)-1)
+	state.ResponseChunk = i
+
+	speech := alexa.NewSSML().Markdown(chunks[i])
+	if i < len(chunks)-1 {
+		speech.Break(answerPause).Text(msgs.get(msgContinuePrompt))
+	}
+	return alexa.NewSSMLResponse(msgs.get(msgResponseTitle), state.LastResponse.Response, speech, false)
+}
+
+// hasChunks reports whether the user's last answer can be navigated chunk by
+// chunk, which images, translations and errors cannot.
+func hasChunks(state *UserState) bool {
+	return state.LastResponse != nil &&
+		state.LastResponse.Error == "" &&
+		state.LastResponse.Model != chatmodels.CHAT_MODEL_TRANSLATIONS.String() &&
+		len(state.LastResponse.ImagesResponse) == 0 &&
+		strings.TrimSpace(state.LastResponse.Response) != ""
+}
@@ -0,0 +1,170 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/jackmcguire1/alexa-chatgpt/internal/dom/chatmodels"
+	"github.com/jackmcguire1/alexa-chatgpt/internal/pkg/alexa"
+	"github.com/jackmcguire1/alexa-chatgpt/internal/pkg/queue"
+	"github.com/jackmcguire1/alexa-chatgpt/internal/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitAnswer(t *testing.T) {
+	tests := []struct {
+		name   string
+		answer string
+		size   int
+		want   []string
+	}{
+		{
+			name:   "short answer",
+			answer: "The sky is blue.",
+			size:   100,
+			want:   []string{"The sky is blue."},
+		},
+		{
+			name:   "sentences",
+			answer: "One two three. Four five six? Seven eight nine! Ten.",
+			size:   30,
+			want:   []string{"One two three. Four five six?", "Seven eight nine! Ten."},
+		},
+		{
+			name:   "decimals are not sentence ends",
+			answer: "Pi is 3.14 or so. It goes on.",
+			size:   20,
+			want:   []string{"Pi is 3.14 or so.", "It goes on."},
+		},
+		{
+			name:   "lines",
+			answer: "## Planets\n- Mercury\n- Venus\n\nThat is all.",
+			size:   25,
+			want:   []string{"## Planets\n- Mercury", "- Venus\n\nThat is all."},
+		},
+		{
+			name:   "code blocks stay whole",
+			answer: "Try this.\n```go\nfmt.Println(\"a. b\")\nreturn\n```\nDone.",
+			size:   12,
+			want:   []string{"Try this.", "```go\nfmt.Println(\"a. b\")\nreturn\n```", "Done."},
+		},
+		{
+			name:   "long sentence",
+			answer: "Short. A sentence far longer than the chunk size. End.",
+			size:   10,
+			want:   []string{"Short.", "A sentence far longer than the chunk size.", "End."},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, splitAnswer(tt.answer, tt.size))
+		})
+	}
+}
+
+func TestLongAnswerIsSpokenInChunks(t *testing.T) {
+	var sentences []string
+	for i := 1; i <= 60; i++ {
+		sentences = append(sentences, fmt.Sprintf("This is sentence number %d of a very long answer.", i))
+	}
+	answer := strings.Join(sentences, " ")
+	chunks := splitAnswer(answer, answerChunkSize)
+	require.Len(t, chunks, 5)
+
+	mockRequestsQueue := &queue.MockQueue{}
+	mockRequestsQueue.On("PushMessage", mock.Anything, mock.Anything).Return(nil)
+	mockResponsesQueue := &queue.MockQueue{}
+	mockResponsesQueue.On("Ack", mock.Anything, mock.Anything).Return(nil)
+	mockResponsesQueue.On("ReceiveMessage", mock.Anything, mock.Anything).Return([]byte(utils.ToJSON(&chatmodels.LastResponse{
+		RequestID: "alice-1", UserID: "alice", Prompt: "go on", Response: answer, Model: "sonnet", TimeDiff: "4",
+	})), nil).Once()
+
+	h := NewHandler(logger, &chatmodels.MockClient{}, mockResponsesQueue, mockRequestsQueue, 1, chatmodels.CHAT_MODEL_SONNET, "", nil)
+	invoke := func(req alexa.Request) alexa.Response {
+		t.Helper()
+		resp, err := h.Invoke(context.Background(), req)
+		require.NoError(t, err)
+		return resp
+	}
+	navigate := func(intent string) alexa.Response {
+		req := alexa.Request{Body: alexa.ReqBody{Type: alexa.IntentRequestType, Intent: alexa.Intent{Name: intent}}}
+		req.Session.User.UserID = "alice"
+		return invoke(req)
+	}
+	speaks := func(t *testing.T, resp alexa.Response, chunk int) {
+		t.Helper()
+		assert.Contains(t, resp.Body.OutputSpeech.SSML, "<p>"+chunks[chunk]+"</p>")
+		assert.Contains(t, resp.Body.Card.Text, answer)
+	}
+
+	resp := invoke(autoCompleteRequest("alice", "alice-1", "go on"))
+	speaks(t, resp, 0)
+	assert.Contains(t, resp.Body.OutputSpeech.SSML, "this took 4 seconds to fetch the answer, say continue to hear more")
+	assert.NotContains(t, resp.Body.OutputSpeech.SSML, "sentence number 60")
+
+	speaks(t, navigate(alexa.NextIntent), 1)
+	speaks(t, navigate(alexa.RepeatIntent), 1)
+	speaks(t, navigate(alexa.PreviousIntent), 0)
+	speaks(t, navigate(alexa.PreviousIntent), 0)
+
+	for i := 1; i < len(chunks); i++ {
+		resp = navigate(alexa.NextIntent)
+		speaks(t, resp, i)
+	}
+	assert.NotContains(t, resp.Body.OutputSpeech.SSML, "say continue to hear more")
+	assert.Contains(t, resp.Body.OutputSpeech.SSML, "sentence number 60")
+
+	resp = navigate(alexa.NextIntent)
+	assert.Equal(t, "that's the end of the answer, say repeat that to hear the last part again", resp.Body.OutputSpeech.Text)
+}
+
+func TestNavigatingWithoutAnAnswer(t *testing.T) {
+	h := NewHandler(logger, &chatmodels.MockClient{}, nil, nil, 0, chatmodels.CHAT_MODEL_SONNET, "", nil)
+	for _, intent := range []string{alexa.NextIntent, alexa.RepeatIntent, alexa.PreviousIntent} {
+		req := alexa.Request{Body: alexa.ReqBody{Type: alexa.IntentRequestType, Intent: alexa.Intent{Name: intent}}}
+		req.Session.User.UserID = "nobody"
+		resp, err := h.Invoke(context.Background(), req)
+		assert.NoError(t, err)
+		assert.Contains(t, resp.Body.OutputSpeech.Text, "I do not have an answer to")
+	}
+}
+
+func TestNewLastResponseIsSpokenFromItsStart(t *testing.T) {
+	tests := []struct {
+		name     string
+		response *chatmodels.LastResponse
+	}{
+		{name: "partial", response: &chatmodels.LastResponse{RequestID: "alice-2", UserID: "alice", Response: "The sky is", Model: "opus", Partial: true}},
+		{name: "parked stale response", response: &chatmodels.LastResponse{RequestID: "alice-1", UserID: "alice", Response: "an old answer", Model: "opus"}},
+		{name: "error", response: &chatmodels.LastResponse{RequestID: "alice-2", UserID: "alice", Error: "the model is unavailable", Model: "opus"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRequestsQueue := &queue.MockQueue{}
+			mockRequestsQueue.On("PushMessage", mock.Anything, mock.Anything).Return(nil)
+			mockResponsesQueue := &queue.MockQueue{}
+			mockResponsesQueue.On("Ack", mock.Anything, mock.Anything).Return(nil)
+			mockResponsesQueue.On("ReceiveMessage", mock.Anything, mock.Anything).Return([]byte(utils.ToJSON(tt.response)), nil).Once()
+			mockResponsesQueue.On("ReceiveMessage", mock.Anything, mock.Anything).Return(nil, nil)
+
+			h := NewHandler(logger, &chatmodels.MockClient{}, mockResponsesQueue, mockRequestsQueue, 1, chatmodels.CHAT_MODEL_OPUS, "", nil)
+			state, err := h.loadState(context.Background(), "alice")
+			require.NoError(t, err)
+			state.LastResponse = &chatmodels.LastResponse{Response: "a long answer spoken up to its fourth chunk"}
+			state.ResponseChunk = 3
+			require.NoError(t, h.saveState(context.Background(), "alice", state))
+
+			_, err = h.Invoke(context.Background(), autoCompleteRequest("alice", "alice-2", "why is the sky blue"))
+			require.NoError(t, err)
+
+			state, err = h.loadState(context.Background(), "alice")
+			require.NoError(t, err)
+			assert.Equal(t, tt.response.Response, state.LastResponse.Response)
+			assert.Zero(t, state.ResponseChunk)
+		})
+	}
+}
@@ -11,8 +11,8 @@ import (
 )
 
 type RandomNumberGame struct {
-	Number   int
-	MaxLimit int
+	Number   int `json:"number"`
+	MaxLimit int `json:"max_limit"`
 }
 
 func NewRandomNumberGame(maxLimit int) *RandomNumberGame {
@@ -41,35 +41,33 @@ func (svc *RandomNumberGame) ShuffleRandomNumber() {
 	}
 }
 
-func (h *Handler) RandomNumberGame(ctx context.Context, req alexa.Request) (res alexa.Response, err error) {
+func (h *Handler) RandomNumberGame(ctx context.Context, req alexa.Request, state *UserState) (res alexa.Response, err error) {
 	h.Logger.With("intent", req.Body.Intent.Name).Debug("got intent")
 	guess := req.Body.Intent.Slots["number"].Value
 	guessInt, _ := strconv.Atoi(guess)
-	number := h.RandomNumberSvc.Number
+	number := state.RandomNumber.Number
 
 	h.Logger.With("guess", guess).With("current number number", number).Info("got guess")
+	msgs := messagesFor(req.Body.Locale)
+	title := msgs.get(msgRandomNumberTitle)
 
 	if guessInt > number {
-		statement, _ := h.ChatGptService.TextGeneration(ctx, higherThanprompt, h.Model)
-		res = alexa.NewResponse("Random Number Game", statement, false)
+		statement, _ := h.generate(ctx, state, "", msgs.prompt(higherThanprompt), state.Model)
+		res = markdownResponse(title, statement)
 	}
 	if guessInt < number {
-		statement, _ := h.ChatGptService.TextGeneration(ctx, lessThanprompt, h.Model)
-		res = alexa.NewResponse("Random Number Game", statement, false)
+		statement, _ := h.generate(ctx, state, "", msgs.prompt(lessThanprompt), state.Model)
+		res = markdownResponse(title, statement)
 	}
 	if guessInt == number {
-		winningStatement := fmt.Sprintf(winningprompt, h.RandomNumberSvc.Number)
-		statement, _ := h.ChatGptService.TextGeneration(
-			ctx,
-			winningStatement,
-			h.Model,
-		)
-		res = alexa.NewResponse("Random Number Game", statement, false)
-		h.RandomNumberSvc.ShuffleRandomNumber()
+		winningStatement := fmt.Sprintf(winningprompt, state.RandomNumber.Number)
+		statement, _ := h.generate(ctx, state, "", msgs.prompt(winningStatement), state.Model)
+		res = markdownResponse(title, statement)
+		state.RandomNumber.ShuffleRandomNumber()
 	}
 
-	if res.Body.OutputSpeech != nil && res.Body.OutputSpeech.Text == "" {
-		res = alexa.NewResponse("Random Number Game", "I'm sorry, something went wrong", false)
+	if res.Body.Card != nil && res.Body.Card.Text == "" {
+		res = alexa.NewResponse(title, msgs.get(msgRandomNumberError), false)
 	}
 
 	return res, nil
@@ -15,13 +15,15 @@ func TestRandomNumberGameCorrectGuess(t *testing.T) {
 	mockChatGptService.On("TextGeneration", mock.Anything, mock.Anything, mock.Anything).Return("Congratulations! You guessed it right.", nil)
 
 	h := &Handler{
-		ChatGptService:  mockChatGptService,
-		Logger:          logger,
-		RandomNumberSvc: NewRandomNumberGame(10),
-		Model:           chatmodels.CHAT_MODEL_SONNET,
+		ChatGptService: mockChatGptService,
+		Logger:         logger,
+	}
+	state := &UserState{
+		RandomNumber: NewRandomNumberGame(10),
+		Model:        chatmodels.CHAT_MODEL_SONNET,
 	}
 
-	h.RandomNumberSvc.Number = 5
+	state.RandomNumber.Number = 5
 
 	req := alexa.Request{
 		Version: "",
@@ -41,7 +43,7 @@ func TestRandomNumberGameCorrectGuess(t *testing.T) {
 		Context: alexa.Context{},
 	}
 
-	resp, err := h.RandomNumberGame(context.Background(), req)
+	resp, err := h.RandomNumberGame(context.Background(), req, state)
 	assert.NoError(t, err)
 	assert.Contains(t, "Congratulations! You guessed it right.", resp.Body.OutputSpeech.Text)
 }
@@ -5,6 +5,9 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
+	"strconv"
+	"time"
 
 	"github.com/jackmcguire1/alexa-chatgpt/internal/dom/chatmodels"
 	"github.com/jackmcguire1/alexa-chatgpt/internal/pkg/alexa"
@@ -15,50 +18,99 @@ import (
 
 var tracer = otel.Tracer("prompt-requester")
 
-func (h *Handler) GetResponse(ctx context.Context, delay int, lastResponse bool) (res alexa.Response, err error) {
+// answerPause separates a model's answer from the note on where it came from.
+const answerPause = 400 * time.Millisecond
+
+// MaxResponseReceives is how many times a response can be received without
+// being delivered before it is dropped instead of being made visible again,
+// as the responses queue's redrive policy does in SQS.
+const MaxResponseReceives = 20
+
+// ResponseExpiry is how long a response waits in the queue for its user, the
+// worker's timeout, before other users' polls drop it rather than re-route it.
+const ResponseExpiry = 5 * time.Minute
+
+// GetResponse long-polls the responses queue for up to delay seconds for the
+// response to the user's pending request. When no request is pending any
+// response belonging to userID is accepted. A partial response is spoken with a
+// note that the rest is on its way, and keeps the request pending. Devices
+// with a screen are also sent the answer or image as an APL document.
+func (h *Handler) GetResponse(ctx context.Context, req alexa.Request, state *UserState, delay int, lastResponse bool) (res alexa.Response, err error) {
+	return h.getResponse(ctx, req, state, time.Now().Add(time.Duration(delay)*time.Second), lastResponse)
+}
+
+// getResponse is GetResponse polling until deadline, for callers that have
+// already spent some of the poll delay.
+func (h *Handler) getResponse(ctx context.Context, req alexa.Request, state *UserState, deadline time.Time, lastResponse bool) (res alexa.Response, err error) {
+	userID := req.Session.User.UserID
+	msgs := messagesFor(req.Body.Locale)
+	title := msgs.get(msgResponseTitle)
 	ctx, span := tracer.Start(ctx, "GetResponse")
 	defer span.End()
+	span.SetAttributes(attribute.String("request-id", state.PendingRequestID))
 
-	var data []byte
-	data, err = h.ResponsesQueue.PullMessage(ctx, delay)
-	if err != nil && !errors.Is(err, queue.EmptyMessageErr) {
+	var response *chatmodels.LastResponse
+	var received []*queue.Message
+	response, received, err = h.pollResponse(ctx, userID, state, deadline)
+	// the messages are only deleted once the state holding their responses is saved
+	state.received = append(state.received, received...)
+	if err != nil {
 		span.RecordError(err)
 		return
 	}
-	var response *chatmodels.LastResponse
-	if len(data) == 0 && !lastResponse {
-		res = alexa.NewResponse("Response", "your response will be available shortly", false)
+
+	if response == nil && !lastResponse {
+		pendingCounter.Add(ctx, 1)
+		res = alexa.NewResponse(title, msgs.get(msgResponsePending), false)
 		return
 	}
 
-	if len(data) == 0 && lastResponse {
-		if h.lastResponse == nil {
-			res = alexa.NewResponse("Response", "I do not have a answer to your last prompt", false)
+	if response == nil && lastResponse {
+		if state.LastResponse == nil {
+			res = alexa.NewResponse(title, msgs.get(msgNoLastResponse), false)
 			return
 		}
-		response = h.lastResponse
+		response = state.LastResponse
 		goto response
 	}
 
-	err = json.Unmarshal(data, &response)
-	if err != nil {
-		span.RecordError(err)
-		h.Logger.
-			With("error", err).
-			With("data", string(data)).
-			Error("failed to unmarshal chat model response")
-		return
+	// a partial response leaves the request pending until the full answer arrives
+	if state.PendingRequestID == response.RequestID && !response.Partial {
+		state.PendingRequestID = ""
 	}
 
 response:
-	if response.Error != "" {
-		span.RecordError(errors.New(response.Error))
-		res = alexa.NewResponse(
-			"Response",
-			fmt.Sprintf("I encountered an error processing your prompt, %s", response.Error),
+	defer func() {
+		if req.SupportsAPL() && response.Error == "" {
+			res.AddDirective(answerDocument(title, response))
+		}
+	}()
+
+	if response.Partial {
+		note := msgs.get(msgPartialNote, response.Model)
+		res = alexa.NewSSMLResponse(
+			title,
+			fmt.Sprintf("%s %s", response.Response, note),
+			alexa.NewSSML().Markdown(response.Response).Break(answerPause).Text(note),
 			false,
 		)
-		h.lastResponse = response
+		state.setLastResponse(response)
+		return
+	}
+
+	if response.Model == chatmodels.IMAGE_MODEL_FLUX.String() && response.Error == "" && len(response.ImagesResponse) < 2 {
+		h.Logger.
+			With("request-id", response.RequestID).
+			With("images", len(response.ImagesResponse)).
+			Error("image response is missing its images")
+		response.Error = "the image model returned no images"
+	}
+
+	if response.Error != "" {
+		span.RecordError(errors.New(response.Error))
+		text := msgs.get(msgResponseError, response.Error)
+		res = alexa.NewSSMLResponse(title, text, alexa.NewSSML().Text(text), false)
+		state.setLastResponse(response)
 		return
 	}
 
@@ -66,39 +118,213 @@ response:
 	case chatmodels.IMAGE_MODEL_FLUX.String():
 
 		res = alexa.NewImageResponse(
-			"Response",
-			fmt.Sprintf("your generated image took %s seconds to fetch", response.TimeDiff),
+			title,
+			msgs.get(msgImageNote, response.TimeDiff),
 			response.ImagesResponse[0],
 			response.ImagesResponse[1],
 			false,
 		)
-		h.lastResponse = response
+		state.setLastResponse(response)
 		span.SetAttributes(attribute.Int("response-bytes", len(response.Response)))
 		return
 	case chatmodels.CHAT_MODEL_TRANSLATIONS.String():
-		res = alexa.NewResponse(
-			"Response",
-			fmt.Sprintf(
-				"your translated prompt is %s, this took %s seconds to fetch the answer",
-				response.Response,
-				response.TimeDiff,
-			),
+		// speak the translation with the target language's pronunciation
+		intro := msgs.get(msgTranslationIntro) + " "
+		speech := alexa.NewSSML().Text(intro)
+		if locale, ok := alexa.LangLocale(response.TargetLanguage); ok {
+			speech.Lang(locale, response.Response)
+		} else if response.Romanization != "" {
+			// Alexa can't read this script, but can read its romanisation
+			speech.Text(response.Romanization)
+		} else {
+			speech.Text(response.Response)
+		}
+		text := response.Response
+		if response.Romanization != "" {
+			text += " (" + response.Romanization + ")"
+		}
+		note := msgs.get(msgTranslationNote, response.TimeDiff)
+		res = alexa.NewSSMLResponse(
+			title,
+			intro+text+note,
+			speech.Text(note),
 			false,
 		)
-		h.lastResponse = response
+		state.setLastResponse(response)
 		return
 	default:
-		res = alexa.NewResponse("Response",
-			fmt.Sprintf(
-				"%s, from the %s model, this took %s seconds to fetch the answer",
-				response.Response,
-				response.Model,
-				response.TimeDiff,
-			),
+		if response != state.LastResponse {
+			state.Conversation.Append(response.Prompt, response.Response, h.conversationTokenBudget())
+		}
+		// long answers are spoken a chunk at a time, the card has all of it
+		chunks := splitAnswer(response.Response, answerChunkSize)
+		note := msgs.get(msgAnswerNote, response.Model, response.TimeDiff)
+		speech := alexa.NewSSML().Markdown(response.Response).Break(answerPause).Text(note)
+		if len(chunks) > 1 {
+			speech = alexa.NewSSML().Markdown(chunks[0]).Break(answerPause).Text(note + ", " + msgs.get(msgContinuePrompt))
+		}
+		res = alexa.NewSSMLResponse(
+			title,
+			fmt.Sprintf("%s, %s", response.Response, note),
+			speech,
 			false,
 		)
-		h.lastResponse = response
+		state.setLastResponse(response)
 	}
 
 	return
 }
+
+// answerDocument shows a generated image full screen and any other answer as
+// text, under the prompt that asked for it or else under title.
+func answerDocument(title string, response *chatmodels.LastResponse) alexa.Directive {
+	if response.Prompt != "" {
+		title = response.Prompt
+	}
+	if len(response.ImagesResponse) > 1 {
+		return alexa.NewImageDocument(title, response.ImagesResponse[1])
+	}
+	return alexa.NewAnswerDocument(title, response.Response, response.Model, response.TimeDiff)
+}
+
+// markdownResponse speaks a model generated statement as SSML and shows it
+// on the card.
+func markdownResponse(title string, statement string) alexa.Response {
+	return alexa.NewSSMLResponse(title, statement, alexa.NewSSML().Markdown(statement), false)
+}
+
+// pollResponse receives messages from the responses queue until the response
+// to the user's pending request arrives or the deadline passes, returning the
+// messages it has kept responses from for the caller to acknowledge. Responses
+// to an earlier request by the same user are parked as their last response,
+// responses belonging to other users are made visible again so the invocation
+// serving that user can still receive them until they expire, and messages
+// that aren't responses are dropped.
+func (h *Handler) pollResponse(ctx context.Context, userID string, state *UserState, deadline time.Time) (*chatmodels.LastResponse, []*queue.Message, error) {
+	ctx, span := tracer.Start(ctx, "pollResponse")
+	defer span.End()
+
+	requestID := state.PendingRequestID
+	var received, rerouted []*queue.Message
+	defer func() {
+		for _, other := range rerouted {
+			if err := h.release(ctx, other); err != nil {
+				span.RecordError(err)
+				h.Logger.
+					With("receive-count", other.ReceiveCount).
+					With("error", err).
+					Error("failed to re-route response belonging to another request")
+			}
+		}
+		span.SetAttributes(attribute.Int("rerouted-responses", len(rerouted)))
+	}()
+
+	for {
+		wait := max(int(math.Ceil(time.Until(deadline).Seconds())), 0)
+
+		msg, err := h.ResponsesQueue.ReceiveMessage(ctx, wait)
+		if err != nil && !errors.Is(err, queue.EmptyMessageErr) {
+			return nil, received, err
+		}
+		if msg == nil {
+			recordPoll(ctx, pollEmpty)
+			return nil, received, nil
+		}
+
+		var response *chatmodels.LastResponse
+		err = json.Unmarshal(msg.Body, &response)
+		switch {
+		case err != nil || response == nil:
+			// receiving it again won't decode it, so it is dropped
+			h.Logger.
+				With("error", err).
+				With("data", string(msg.Body)).
+				With("receive-count", msg.ReceiveCount).
+				Error("dropping chat model response that failed to unmarshal")
+			h.drop(ctx, msg)
+		case response.UserID == userID && response.Partial && response.RequestID != requestID:
+			// a partial is only worth speaking while its request is pending
+			h.Logger.
+				With("request-id", response.RequestID).
+				With("pending-request-id", requestID).
+				Info("dropping partial response for a request that is no longer pending")
+			received = append(received, msg)
+		case response.UserID == userID && (requestID == "" || response.RequestID == requestID):
+			received = append(received, msg)
+			state.recordUsage(response)
+			recordPoll(ctx, pollHit)
+			recordLatency(ctx, response)
+			return response, received, nil
+		case response.UserID == userID:
+			h.Logger.
+				With("request-id", response.RequestID).
+				With("pending-request-id", requestID).
+				Info("parking stale response as last response")
+			state.recordUsage(response)
+			state.setLastResponse(response)
+			received = append(received, msg)
+		case responseExpired(msg):
+			h.Logger.
+				With("request-id", response.RequestID).
+				With("receive-count", msg.ReceiveCount).
+				Info("dropping expired response belonging to another user")
+			h.drop(ctx, msg)
+		default:
+			rerouted = append(rerouted, msg)
+		}
+
+		if !time.Now().Before(deadline) {
+			recordPoll(ctx, pollTimeout)
+			return nil, received, nil
+		}
+	}
+}
+
+// responseExpired reports whether msg was sent more than ResponseExpiry ago.
+func responseExpired(msg *queue.Message) bool {
+	sent, err := strconv.ParseInt(msg.Attributes[queue.SentTimestampAttribute], 10, 64)
+	if err != nil {
+		return false
+	}
+	return time.Since(time.UnixMilli(sent)) > ResponseExpiry
+}
+
+// drop deletes msg from the responses queue without delivering it.
+func (h *Handler) drop(ctx context.Context, msg *queue.Message) {
+	if err := h.ResponsesQueue.Ack(ctx, msg); err != nil {
+		h.Logger.
+			With("receive-count", msg.ReceiveCount).
+			With("error", err).
+			Error("failed to drop response message")
+	}
+}
+
+// settle acknowledges the messages whose responses are held in a saved user
+// state, or makes them visible again when the state couldn't be saved.
+func (h *Handler) settle(ctx context.Context, messages []*queue.Message, saved bool) {
+	for _, msg := range messages {
+		settle := h.ResponsesQueue.Ack
+		if !saved {
+			settle = h.release
+		}
+		if err := settle(ctx, msg); err != nil {
+			h.Logger.
+				With("receive-count", msg.ReceiveCount).
+				With("acknowledge", saved).
+				With("error", err).
+				Error("failed to settle response message")
+		}
+	}
+}
+
+// release makes msg visible again, unless it has already been received
+// MaxResponseReceives times, in which case it is dropped.
+func (h *Handler) release(ctx context.Context, msg *queue.Message) error {
+	if msg.ReceiveCount >= MaxResponseReceives {
+		h.Logger.
+			With("receive-count", msg.ReceiveCount).
+			Warn("dropping response that was received too many times")
+		return h.ResponsesQueue.Ack(ctx, msg)
+	}
+	return h.ResponsesQueue.Nack(ctx, msg)
+}
@@ -0,0 +1,523 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/jackmcguire1/alexa-chatgpt/internal/dom/chatmodels"
+	"github.com/jackmcguire1/alexa-chatgpt/internal/pkg/alexa"
+	"github.com/jackmcguire1/alexa-chatgpt/internal/pkg/queue"
+	"github.com/jackmcguire1/alexa-chatgpt/internal/pkg/userstate"
+	"github.com/jackmcguire1/alexa-chatgpt/internal/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func autoCompleteRequest(userID, requestID, prompt string) alexa.Request {
+	req := alexa.Request{
+		Body: alexa.ReqBody{
+			Type:      alexa.IntentRequestType,
+			RequestID: requestID,
+			Intent: alexa.Intent{
+				Name: alexa.AutoCompleteIntent,
+				Slots: map[string]alexa.Slot{
+					"prompt": {Name: "prompt", Value: prompt},
+				},
+			},
+		},
+	}
+	req.Session.User.UserID = userID
+	return req
+}
+
+func lastResponseRequest(userID string) alexa.Request {
+	req := alexa.Request{
+		Body: alexa.ReqBody{
+			Type:   alexa.IntentRequestType,
+			Intent: alexa.Intent{Name: alexa.LastResponseIntent},
+		},
+	}
+	req.Session.User.UserID = userID
+	return req
+}
+
+// responseTo matches a queue message holding the response to requestID.
+func responseTo(requestID string) any {
+	return mock.MatchedBy(func(msg *queue.Message) bool {
+		var response chatmodels.LastResponse
+		return json.Unmarshal(msg.Body, &response) == nil && response.RequestID == requestID
+	})
+}
+
+func TestGetResponseReroutesOtherUsersResponse(t *testing.T) {
+	mockRequestsQueue := &queue.MockQueue{}
+	mockRequestsQueue.On("PushMessage", mock.Anything, mock.Anything).Return(nil)
+
+	bobResponse := &chatmodels.LastResponse{RequestID: "bob-1", UserID: "bob", Response: "paris", Model: "sonnet", TimeDiff: "2"}
+	aliceResponse := &chatmodels.LastResponse{RequestID: "alice-1", UserID: "alice", Response: "chimney", Model: "sonnet", TimeDiff: "1"}
+
+	mockResponsesQueue := &queue.MockQueue{}
+	mockResponsesQueue.On("Ack", mock.Anything, mock.Anything).Return(nil)
+	mockResponsesQueue.On("ReceiveMessage", mock.Anything, mock.Anything).Return([]byte(utils.ToJSON(bobResponse)), nil).Once()
+	mockResponsesQueue.On("ReceiveMessage", mock.Anything, mock.Anything).Return([]byte(utils.ToJSON(aliceResponse)), nil).Once()
+	mockResponsesQueue.On("Nack", mock.Anything, responseTo("bob-1")).Return(nil).Once()
+
+	h := NewHandler(logger, &chatmodels.MockClient{}, mockResponsesQueue, mockRequestsQueue, 1, chatmodels.CHAT_MODEL_SONNET, "", nil)
+
+	resp, err := h.Invoke(context.Background(), autoCompleteRequest("alice", "alice-1", "the boy fell down the"))
+	assert.NoError(t, err)
+	assert.EqualValues(t, "chimney, from the sonnet model, this took 1 seconds to fetch the answer", resp.Body.Card.Text)
+	mockResponsesQueue.AssertExpectations(t)
+	mockRequestsQueue.AssertCalled(t, "PushMessage", mock.Anything, mock.MatchedBy(func(r *chatmodels.Request) bool {
+		return r.RequestID == "alice-1" && r.UserID == "alice"
+	}))
+}
+
+func TestGetResponseDropsExpiredResponseOfOtherUser(t *testing.T) {
+	sent := time.Now().Add(-ResponseExpiry - time.Minute)
+	bobResponse := &queue.Message{
+		Body:         []byte(utils.ToJSON(&chatmodels.LastResponse{RequestID: "bob-1", UserID: "bob", Response: "paris", Model: "sonnet"})),
+		Receipt:      "bob-receipt",
+		Attributes:   map[string]string{queue.SentTimestampAttribute: strconv.FormatInt(sent.UnixMilli(), 10)},
+		ReceiveCount: 3,
+	}
+	mockResponsesQueue := &queue.MockQueue{}
+	mockResponsesQueue.On("ReceiveMessage", mock.Anything, mock.Anything).Return(bobResponse, nil).Once()
+	mockResponsesQueue.On("ReceiveMessage", mock.Anything, mock.Anything).Return(nil, nil)
+	mockResponsesQueue.On("Ack", mock.Anything, bobResponse).Return(nil).Once()
+
+	h := NewHandler(logger, &chatmodels.MockClient{}, mockResponsesQueue, &queue.MockQueue{}, 1, chatmodels.CHAT_MODEL_SONNET, "", nil)
+
+	_, err := h.Invoke(context.Background(), lastResponseRequest("alice"))
+	assert.NoError(t, err)
+	mockResponsesQueue.AssertExpectations(t)
+	mockResponsesQueue.AssertNotCalled(t, "Nack", mock.Anything, mock.Anything)
+}
+
+func TestGetResponseParksStaleResponseForSameUser(t *testing.T) {
+	mockRequestsQueue := &queue.MockQueue{}
+	mockRequestsQueue.On("PushMessage", mock.Anything, mock.Anything).Return(nil)
+
+	staleResponse := &chatmodels.LastResponse{RequestID: "alice-1", UserID: "alice", Response: "an old answer", Model: "opus", TimeDiff: "9"}
+	freshResponse := &chatmodels.LastResponse{RequestID: "alice-2", UserID: "alice", Response: "a new answer", Model: "sonnet", TimeDiff: "1"}
+
+	mockResponsesQueue := &queue.MockQueue{}
+	mockResponsesQueue.On("Ack", mock.Anything, mock.Anything).Return(nil)
+	mockResponsesQueue.On("ReceiveMessage", mock.Anything, mock.Anything).Return([]byte(utils.ToJSON(staleResponse)), nil).Once()
+	mockResponsesQueue.On("ReceiveMessage", mock.Anything, mock.Anything).Return([]byte(utils.ToJSON(freshResponse)), nil).Once()
+
+	h := NewHandler(logger, &chatmodels.MockClient{}, mockResponsesQueue, mockRequestsQueue, 1, chatmodels.CHAT_MODEL_SONNET, "", nil)
+
+	resp, err := h.Invoke(context.Background(), autoCompleteRequest("alice", "alice-2", "a new question"))
+	assert.NoError(t, err)
+	assert.Contains(t, resp.Body.Card.Text, "a new answer")
+	assert.NotContains(t, resp.Body.Card.Text, "an old answer")
+	mockResponsesQueue.AssertNotCalled(t, "Nack", mock.Anything, mock.Anything)
+}
+
+func TestLastResponseWaitsForPendingRequest(t *testing.T) {
+	mockRequestsQueue := &queue.MockQueue{}
+	mockRequestsQueue.On("PushMessage", mock.Anything, mock.Anything).Return(nil)
+
+	aliceResponse := &chatmodels.LastResponse{RequestID: "alice-1", UserID: "alice", Response: "chimney", Model: "sonnet", TimeDiff: "8"}
+	bobResponse := &chatmodels.LastResponse{RequestID: "bob-1", UserID: "bob", Response: "paris", Model: "sonnet", TimeDiff: "3"}
+
+	mockResponsesQueue := &queue.MockQueue{}
+	mockResponsesQueue.On("Ack", mock.Anything, mock.Anything).Return(nil)
+	// alice times out waiting for her answer
+	mockResponsesQueue.On("ReceiveMessage", mock.Anything, mock.Anything).Return(nil, nil).Once()
+	// bob's invocation pulls alice's late answer before his own and re-routes it
+	mockResponsesQueue.On("ReceiveMessage", mock.Anything, mock.Anything).Return([]byte(utils.ToJSON(aliceResponse)), nil).Once()
+	mockResponsesQueue.On("ReceiveMessage", mock.Anything, mock.Anything).Return([]byte(utils.ToJSON(bobResponse)), nil).Once()
+	mockResponsesQueue.On("Nack", mock.Anything, responseTo("alice-1")).Return(nil).Once()
+	// alice asks for her last response and receives the re-routed answer
+	mockResponsesQueue.On("ReceiveMessage", mock.Anything, mock.Anything).Return([]byte(utils.ToJSON(aliceResponse)), nil).Once()
+
+	h := NewHandler(logger, &chatmodels.MockClient{}, mockResponsesQueue, mockRequestsQueue, 1, chatmodels.CHAT_MODEL_SONNET, "", nil)
+
+	resp, err := h.Invoke(context.Background(), autoCompleteRequest("alice", "alice-1", "the boy fell down the"))
+	assert.NoError(t, err)
+	assert.EqualValues(t, "your response will be available shortly", resp.Body.OutputSpeech.Text)
+
+	resp, err = h.Invoke(context.Background(), autoCompleteRequest("bob", "bob-1", "capital of france"))
+	assert.NoError(t, err)
+	assert.Contains(t, resp.Body.Card.Text, "paris")
+
+	resp, err = h.Invoke(context.Background(), lastResponseRequest("alice"))
+	assert.NoError(t, err)
+	assert.Contains(t, resp.Body.Card.Text, "chimney")
+	state, err := h.loadState(context.Background(), "alice")
+	assert.NoError(t, err)
+	assert.Empty(t, state.PendingRequestID)
+	mockResponsesQueue.AssertExpectations(t)
+}
+
+func TestResponseIsAcknowledgedOnceBuilt(t *testing.T) {
+	received := &queue.Message{
+		Body:         []byte(utils.ToJSON(&chatmodels.LastResponse{RequestID: "alice-1", UserID: "alice", Response: "chimney", Model: "sonnet", TimeDiff: "1"})),
+		Receipt:      "alice-receipt",
+		ReceiveCount: 2,
+	}
+	mockResponsesQueue := &queue.MockQueue{}
+	mockResponsesQueue.On("ReceiveMessage", mock.Anything, mock.Anything).Return(received, nil).Once()
+	mockResponsesQueue.On("Ack", mock.Anything, received).Return(nil).Once()
+
+	h := NewHandler(logger, &chatmodels.MockClient{}, mockResponsesQueue, &queue.MockQueue{}, 1, chatmodels.CHAT_MODEL_SONNET, "", nil)
+
+	resp, err := h.Invoke(context.Background(), lastResponseRequest("alice"))
+	assert.NoError(t, err)
+	assert.Contains(t, resp.Body.Card.Text, "chimney")
+	mockResponsesQueue.AssertExpectations(t)
+	mockResponsesQueue.AssertNotCalled(t, "Nack", mock.Anything, mock.Anything)
+}
+
+func TestMalformedResponseIsDropped(t *testing.T) {
+	malformed := &queue.Message{Body: []byte(`{"user_id": "alice", "response": `), Receipt: "malformed", ReceiveCount: 1}
+	mockResponsesQueue := &queue.MockQueue{}
+	mockResponsesQueue.On("ReceiveMessage", mock.Anything, mock.Anything).Return(malformed, nil).Once()
+	mockResponsesQueue.On("ReceiveMessage", mock.Anything, mock.Anything).
+		Return([]byte(utils.ToJSON(&chatmodels.LastResponse{UserID: "alice", Response: "chimney", Model: "sonnet", TimeDiff: "1"})), nil).Once()
+	mockResponsesQueue.On("Ack", mock.Anything, mock.Anything).Return(nil)
+
+	h := NewHandler(logger, &chatmodels.MockClient{}, mockResponsesQueue, &queue.MockQueue{}, 1, chatmodels.CHAT_MODEL_SONNET, "", nil)
+
+	resp, err := h.Invoke(context.Background(), lastResponseRequest("alice"))
+	assert.NoError(t, err)
+	assert.Contains(t, resp.Body.Card.Text, "chimney")
+	mockResponsesQueue.AssertCalled(t, "Ack", mock.Anything, malformed)
+	mockResponsesQueue.AssertNotCalled(t, "Nack", mock.Anything, mock.Anything)
+}
+
+func TestImageResponseWithoutImagesIsSpokenAsAnError(t *testing.T) {
+	mockResponsesQueue := &queue.MockQueue{}
+	mockResponsesQueue.On("ReceiveMessage", mock.Anything, mock.Anything).
+		Return([]byte(utils.ToJSON(&chatmodels.LastResponse{UserID: "alice", Model: chatmodels.IMAGE_MODEL_FLUX.String()})), nil).Once()
+	mockResponsesQueue.On("Ack", mock.Anything, mock.Anything).Return(nil).Once()
+
+	h := NewHandler(logger, &chatmodels.MockClient{}, mockResponsesQueue, &queue.MockQueue{}, 1, chatmodels.CHAT_MODEL_SONNET, "", nil)
+
+	resp, err := h.Invoke(context.Background(), lastResponseRequest("alice"))
+	assert.NoError(t, err)
+	assert.Equal(t, "I encountered an error processing your prompt, the image model returned no images", resp.Body.Card.Text)
+	mockResponsesQueue.AssertExpectations(t)
+}
+
+// failingStore is a user state store that can't save.
+type failingStore struct{ userstate.UserStateStore }
+
+func (failingStore) Put(context.Context, string, []byte) error {
+	return errors.New("state store is unavailable")
+}
+
+func TestResponsesAreReleasedWhenStateIsNotSaved(t *testing.T) {
+	mockResponsesQueue := &queue.MockQueue{}
+	// a stale answer parked as the last response, then the pending answer
+	mockResponsesQueue.On("ReceiveMessage", mock.Anything, mock.Anything).
+		Return([]byte(utils.ToJSON(&chatmodels.LastResponse{RequestID: "alice-1", UserID: "alice", Response: "an old answer", Model: "sonnet"})), nil).Once()
+	mockResponsesQueue.On("ReceiveMessage", mock.Anything, mock.Anything).
+		Return([]byte(utils.ToJSON(&chatmodels.LastResponse{RequestID: "alice-2", UserID: "alice", Response: "a new answer", Model: "sonnet"})), nil).Once()
+	mockResponsesQueue.On("Nack", mock.Anything, mock.Anything).Return(nil).Twice()
+	mockRequestsQueue := &queue.MockQueue{}
+	mockRequestsQueue.On("PushMessage", mock.Anything, mock.Anything).Return(nil)
+
+	h := NewHandler(logger, &chatmodels.MockClient{}, mockResponsesQueue, mockRequestsQueue, 1, chatmodels.CHAT_MODEL_SONNET, "", failingStore{userstate.NewMemoryStore()})
+
+	resp, err := h.Invoke(context.Background(), autoCompleteRequest("alice", "alice-2", "a new question"))
+	assert.NoError(t, err)
+	assert.Contains(t, resp.Body.Card.Text, "a new answer")
+	mockResponsesQueue.AssertExpectations(t)
+	mockResponsesQueue.AssertNotCalled(t, "Ack", mock.Anything, mock.Anything)
+}
+
+func TestResponseReceivedTooOftenIsDropped(t *testing.T) {
+	bobResponse := &queue.Message{
+		Body:         []byte(utils.ToJSON(&chatmodels.LastResponse{RequestID: "bob-1", UserID: "bob", Response: "paris", Model: "sonnet"})),
+		Receipt:      "bob-receipt",
+		ReceiveCount: MaxResponseReceives,
+	}
+	mockResponsesQueue := &queue.MockQueue{}
+	mockResponsesQueue.On("ReceiveMessage", mock.Anything, mock.Anything).Return(bobResponse, nil).Once()
+	mockResponsesQueue.On("ReceiveMessage", mock.Anything, mock.Anything).Return(nil, nil)
+	mockResponsesQueue.On("Ack", mock.Anything, bobResponse).Return(nil).Once()
+
+	h := NewHandler(logger, &chatmodels.MockClient{}, mockResponsesQueue, &queue.MockQueue{}, 1, chatmodels.CHAT_MODEL_SONNET, "", nil)
+
+	_, err := h.Invoke(context.Background(), lastResponseRequest("alice"))
+	assert.NoError(t, err)
+	mockResponsesQueue.AssertExpectations(t)
+	mockResponsesQueue.AssertNotCalled(t, "Nack", mock.Anything, mock.Anything)
+}
+
+func TestPartialResponseIsSpokenAndKeepsRequestPending(t *testing.T) {
+	mockRequestsQueue := &queue.MockQueue{}
+	mockRequestsQueue.On("PushMessage", mock.Anything, mock.Anything).Return(nil)
+
+	partial := &chatmodels.LastResponse{RequestID: "alice-1", UserID: "alice", Prompt: "why is the sky blue", Response: "Rayleigh scattering.", Model: "opus", TimeDiff: "5", Partial: true}
+	full := &chatmodels.LastResponse{RequestID: "alice-1", UserID: "alice", Prompt: "why is the sky blue", Response: "Rayleigh scattering. And more.", Model: "opus", TimeDiff: "12"}
+
+	mockResponsesQueue := &queue.MockQueue{}
+	mockResponsesQueue.On("Ack", mock.Anything, mock.Anything).Return(nil)
+	mockResponsesQueue.On("ReceiveMessage", mock.Anything, mock.Anything).Return([]byte(utils.ToJSON(partial)), nil).Once()
+	mockResponsesQueue.On("ReceiveMessage", mock.Anything, mock.Anything).Return([]byte(utils.ToJSON(full)), nil).Once()
+
+	h := NewHandler(logger, &chatmodels.MockClient{}, mockResponsesQueue, mockRequestsQueue, 1, chatmodels.CHAT_MODEL_OPUS, "", nil)
+
+	resp, err := h.Invoke(context.Background(), autoCompleteRequest("alice", "alice-1", "why is the sky blue"))
+	assert.NoError(t, err)
+	assert.EqualValues(t, "Rayleigh scattering. The rest of the answer from the opus model is still on its way, ask for your last response to hear all of it", resp.Body.Card.Text)
+
+	state, err := h.loadState(context.Background(), "alice")
+	assert.NoError(t, err)
+	assert.Equal(t, "alice-1", state.PendingRequestID)
+	assert.Empty(t, state.Conversation.Turns)
+
+	resp, err = h.Invoke(context.Background(), lastResponseRequest("alice"))
+	assert.NoError(t, err)
+	assert.Contains(t, resp.Body.Card.Text, "Rayleigh scattering. And more.")
+
+	state, err = h.loadState(context.Background(), "alice")
+	assert.NoError(t, err)
+	assert.Empty(t, state.PendingRequestID)
+	assert.Len(t, state.Conversation.Turns, 2)
+}
+
+func TestPartialResponseForAnsweredRequestIsDropped(t *testing.T) {
+	mockRequestsQueue := &queue.MockQueue{}
+	mockRequestsQueue.On("PushMessage", mock.Anything, mock.Anything).Return(nil)
+
+	// the full answer overtook its partial on the queue
+	full := &chatmodels.LastResponse{RequestID: "alice-1", UserID: "alice", Response: "Rayleigh scattering. And more.", Model: "opus", TimeDiff: "12"}
+	latePartial := &chatmodels.LastResponse{RequestID: "alice-1", UserID: "alice", Response: "Rayleigh scattering.", Model: "opus", TimeDiff: "5", Partial: true}
+
+	mockResponsesQueue := &queue.MockQueue{}
+	mockResponsesQueue.On("Ack", mock.Anything, mock.Anything).Return(nil)
+	mockResponsesQueue.On("ReceiveMessage", mock.Anything, mock.Anything).Return([]byte(utils.ToJSON(full)), nil).Once()
+	mockResponsesQueue.On("ReceiveMessage", mock.Anything, mock.Anything).Return([]byte(utils.ToJSON(latePartial)), nil).Once()
+	mockResponsesQueue.On("ReceiveMessage", mock.Anything, mock.Anything).Return(nil, nil)
+
+	h := NewHandler(logger, &chatmodels.MockClient{}, mockResponsesQueue, mockRequestsQueue, 1, chatmodels.CHAT_MODEL_OPUS, "", nil)
+
+	resp, err := h.Invoke(context.Background(), autoCompleteRequest("alice", "alice-1", "why is the sky blue"))
+	assert.NoError(t, err)
+	assert.Contains(t, resp.Body.Card.Text, "And more.")
+
+	resp, err = h.Invoke(context.Background(), lastResponseRequest("alice"))
+	assert.NoError(t, err)
+	assert.Contains(t, resp.Body.Card.Text, "And more.")
+	assert.NotContains(t, resp.Body.Card.Text, "still on its way")
+	mockResponsesQueue.AssertNotCalled(t, "Nack", mock.Anything, mock.Anything)
+}
+
+func TestMarkdownAnswerIsSpokenAsEscapedSSML(t *testing.T) {
+	answer := "## Tips\n- Salt & pepper\n- Use <b>butter</b>"
+	mockResponsesQueue := &queue.MockQueue{}
+	mockResponsesQueue.On("Ack", mock.Anything, mock.Anything).Return(nil)
+	mockResponsesQueue.On("ReceiveMessage", mock.Anything, mock.Anything).
+		Return([]byte(utils.ToJSON(&chatmodels.LastResponse{UserID: "alice", Response: answer, Model: "sonnet", TimeDiff: "2"})), nil).Once()
+
+	h := NewHandler(logger, &chatmodels.MockClient{}, mockResponsesQueue, &queue.MockQueue{}, 1, chatmodels.CHAT_MODEL_SONNET, "", nil)
+
+	resp, err := h.Invoke(context.Background(), lastResponseRequest("alice"))
+	assert.NoError(t, err)
+	assert.Equal(t,
+		`<speak><p><emphasis level="moderate">Tips</emphasis></p><s>Salt &amp; pepper</s><s>Use &lt;b&gt;butter&lt;/b&gt;</s><break time="400ms"/>from the sonnet model, this took 2 seconds to fetch the answer</speak>`,
+		resp.Body.OutputSpeech.SSML,
+	)
+	assert.Equal(t, answer+", from the sonnet model, this took 2 seconds to fetch the answer", resp.Body.Card.Text)
+}
+
+func TestTranslationIsSpokenInTargetLanguage(t *testing.T) {
+	tests := []struct {
+		language string
+		want     string
+	}{
+		{language: "french", want: `<speak>your translated prompt is <lang xml:lang="fr-FR">c&apos;est la vie</lang>, this took 1 seconds to fetch the answer</speak>`},
+		{language: "klingon", want: `<speak>your translated prompt is c&apos;est la vie, this took 1 seconds to fetch the answer</speak>`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.language, func(t *testing.T) {
+			mockResponsesQueue := &queue.MockQueue{}
+			mockResponsesQueue.On("Ack", mock.Anything, mock.Anything).Return(nil)
+			mockResponsesQueue.On("ReceiveMessage", mock.Anything, mock.Anything).
+				Return([]byte(utils.ToJSON(&chatmodels.LastResponse{
+					UserID:         "alice",
+					Response:       "c'est la vie",
+					Model:          chatmodels.CHAT_MODEL_TRANSLATIONS.String(),
+					TargetLanguage: tt.language,
+					TimeDiff:       "1",
+				})), nil).Once()
+
+			h := NewHandler(logger, &chatmodels.MockClient{}, mockResponsesQueue, &queue.MockQueue{}, 1, chatmodels.CHAT_MODEL_SONNET, "", nil)
+
+			resp, err := h.Invoke(context.Background(), lastResponseRequest("alice"))
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, resp.Body.OutputSpeech.SSML)
+			assert.Equal(t, "your translated prompt is c'est la vie, this took 1 seconds to fetch the answer", resp.Body.Card.Text)
+		})
+	}
+}
+
+func TestTranslationSpeaksRomanization(t *testing.T) {
+	mockResponsesQueue := &queue.MockQueue{}
+	mockResponsesQueue.On("Ack", mock.Anything, mock.Anything).Return(nil)
+	mockResponsesQueue.On("ReceiveMessage", mock.Anything, mock.Anything).
+		Return([]byte(utils.ToJSON(&chatmodels.LastResponse{
+			UserID:         "alice",
+			Response:       "건배",
+			Romanization:   "geonbae",
+			Model:          chatmodels.CHAT_MODEL_TRANSLATIONS.String(),
+			TargetLanguage: "ko",
+			TimeDiff:       "1",
+		})), nil).Once()
+
+	h := NewHandler(logger, &chatmodels.MockClient{}, mockResponsesQueue, &queue.MockQueue{}, 1, chatmodels.CHAT_MODEL_SONNET, "", nil)
+
+	resp, err := h.Invoke(context.Background(), lastResponseRequest("alice"))
+	assert.NoError(t, err)
+	assert.Equal(t, `<speak>your translated prompt is geonbae, this took 1 seconds to fetch the answer</speak>`, resp.Body.OutputSpeech.SSML)
+	assert.Equal(t, "your translated prompt is 건배 (geonbae), this took 1 seconds to fetch the answer", resp.Body.Card.Text)
+}
+
+func withAPL(req alexa.Request) alexa.Request {
+	req.Context.System.Device.SupportedInterfaces = map[string]json.RawMessage{alexa.APLInterface: json.RawMessage(`{}`)}
+	return req
+}
+
+func TestAnswerIsRenderedWithAPLOnDevicesWithScreens(t *testing.T) {
+	tests := []struct {
+		name      string
+		response  *chatmodels.LastResponse
+		req       alexa.Request
+		directive alexa.Directive
+	}{
+		{
+			name:      "chat answer",
+			response:  &chatmodels.LastResponse{UserID: "alice", Prompt: "why is the sky blue", Response: "Rayleigh scattering.", Model: "sonnet", TimeDiff: "2"},
+			req:       withAPL(lastResponseRequest("alice")),
+			directive: alexa.NewAnswerDocument("why is the sky blue", "Rayleigh scattering.", "sonnet", "2"),
+		},
+		{
+			name:      "image",
+			response:  &chatmodels.LastResponse{UserID: "alice", Prompt: "a lighthouse", Model: chatmodels.IMAGE_MODEL_FLUX.String(), ImagesResponse: []string{"small.jpg", "large.jpg"}, TimeDiff: "5"},
+			req:       withAPL(lastResponseRequest("alice")),
+			directive: alexa.NewImageDocument("a lighthouse", "large.jpg"),
+		},
+		{
+			name:     "device without a screen gets only the card",
+			response: &chatmodels.LastResponse{UserID: "alice", Prompt: "why is the sky blue", Response: "Rayleigh scattering.", Model: "sonnet", TimeDiff: "2"},
+			req:      lastResponseRequest("alice"),
+		},
+		{
+			name:     "errors are not rendered",
+			response: &chatmodels.LastResponse{UserID: "alice", Prompt: "why is the sky blue", Error: "model failed", Model: "sonnet"},
+			req:      withAPL(lastResponseRequest("alice")),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockResponsesQueue := &queue.MockQueue{}
+			mockResponsesQueue.On("Ack", mock.Anything, mock.Anything).Return(nil)
+			mockResponsesQueue.On("ReceiveMessage", mock.Anything, mock.Anything).Return([]byte(utils.ToJSON(tt.response)), nil).Once()
+			h := NewHandler(logger, &chatmodels.MockClient{}, mockResponsesQueue, &queue.MockQueue{}, 1, chatmodels.CHAT_MODEL_SONNET, "", nil)
+
+			resp, err := h.Invoke(context.Background(), tt.req)
+			assert.NoError(t, err)
+			assert.NotNil(t, resp.Body.Card)
+			if tt.directive.Type == "" {
+				assert.Empty(t, resp.Body.Directives)
+			} else {
+				assert.Equal(t, []alexa.Directive{tt.directive}, resp.Body.Directives)
+			}
+		})
+	}
+}
+
+// progressiveServer stands in for the Alexa API, recording the speech of each
+// progressive response and replying with status.
+func progressiveServer(t *testing.T, status int) (*httptest.Server, *[]string) {
+	t.Helper()
+	var speech []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/directives", r.URL.Path)
+		assert.Equal(t, "Bearer alice-token", r.Header.Get("Authorization"))
+		var body struct {
+			Directive struct {
+				Speech string `json:"speech"`
+			} `json:"directive"`
+		}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		speech = append(speech, body.Directive.Speech)
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &speech
+}
+
+func TestProgressiveResponseWhilePolling(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		token  string
+		want   []string
+	}{
+		{name: "spoken", status: http.StatusNoContent, token: "alice-token", want: []string{"<speak>thinking with sonnet…</speak>"}},
+		{name: "rejected", status: http.StatusForbidden, token: "alice-token", want: []string{"<speak>thinking with sonnet…</speak>"}},
+		{name: "no access token", status: http.StatusNoContent},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, speech := progressiveServer(t, tt.status)
+
+			mockRequestsQueue := &queue.MockQueue{}
+			mockRequestsQueue.On("PushMessage", mock.Anything, mock.Anything).Return(nil)
+			aliceResponse := &chatmodels.LastResponse{RequestID: "alice-1", UserID: "alice", Response: "chimney", Model: "sonnet", TimeDiff: "3"}
+			mockResponsesQueue := &queue.MockQueue{}
+			mockResponsesQueue.On("Ack", mock.Anything, mock.Anything).Return(nil)
+			mockResponsesQueue.On("ReceiveMessage", mock.Anything, mock.Anything).Return([]byte(utils.ToJSON(aliceResponse)), nil).Once()
+
+			h := NewHandler(logger, &chatmodels.MockClient{}, mockResponsesQueue, mockRequestsQueue, 5, chatmodels.CHAT_MODEL_SONNET, "", nil)
+			h.ProgressiveResponder = alexa.NewClient()
+
+			req := autoCompleteRequest("alice", "alice-1", "the boy fell down the")
+			req.Context.System.APIAccessToken = tt.token
+			req.Context.System.APIEndpoint = srv.URL
+
+			resp, err := h.Invoke(context.Background(), req)
+			assert.NoError(t, err)
+			assert.Contains(t, resp.Body.Card.Text, "chimney")
+			assert.Equal(t, tt.want, *speech)
+		})
+	}
+}
+
+func TestProgressiveResponseNamesImageModel(t *testing.T) {
+	srv, speech := progressiveServer(t, http.StatusNoContent)
+
+	mockRequestsQueue := &queue.MockQueue{}
+	mockRequestsQueue.On("PushMessage", mock.Anything, mock.Anything).Return(nil)
+	mockResponsesQueue := &queue.MockQueue{}
+	mockResponsesQueue.On("Ack", mock.Anything, mock.Anything).Return(nil)
+	mockResponsesQueue.On("ReceiveMessage", mock.Anything, mock.Anything).Return(nil, nil)
+
+	h := NewHandler(logger, &chatmodels.MockClient{}, mockResponsesQueue, mockRequestsQueue, 1, chatmodels.CHAT_MODEL_SONNET, chatmodels.IMAGE_MODEL_FLUX, nil)
+	h.ProgressiveResponder = alexa.NewClient()
+
+	req := alexa.Request{Body: alexa.ReqBody{
+		Type:   alexa.IntentRequestType,
+		Intent: alexa.Intent{Name: alexa.ImageIntent, Slots: map[string]alexa.Slot{"prompt": {Value: "a cat"}}},
+	}}
+	req.Session.User.UserID = "alice"
+	req.Context.System.APIAccessToken = "alice-token"
+	req.Context.System.APIEndpoint = srv.URL
+
+	resp, err := h.Invoke(context.Background(), req)
+	assert.NoError(t, err)
+	assert.Equal(t, "your response will be available shortly", resp.Body.OutputSpeech.Text)
+	assert.Equal(t, []string{"<speak>drawing your image with flux…</speak>"}, *speech)
+}
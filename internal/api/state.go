@@ -0,0 +1,103 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/jackmcguire1/alexa-chatgpt/internal/dom/chatmodels"
+	"github.com/jackmcguire1/alexa-chatgpt/internal/pkg/queue"
+	"github.com/jackmcguire1/alexa-chatgpt/internal/pkg/userstate"
+)
+
+// UserState is everything the skill remembers about a single Alexa user
+// between invocations. The Handler's fields of the same name are only used as
+// the defaults for users without any stored state.
+type UserState struct {
+	Model            chatmodels.ChatModel     `json:"model"`
+	ImageModel       chatmodels.ImageModel    `json:"image_model"`
+	SystemMessage    string                   `json:"system_message,omitempty"`
+	LastResponse     *chatmodels.LastResponse `json:"last_response,omitempty"`
+	PendingRequestID string                   `json:"pending_request_id,omitempty"`
+	// ResponseChunk is the part of the last response spoken most recently.
+	ResponseChunk int          `json:"response_chunk,omitempty"`
+	Conversation  Conversation `json:"conversation"`
+	// Glossary maps terms to the translations always used for them.
+	Glossary     map[string]string `json:"glossary,omitempty"`
+	Usage        UsageTotals       `json:"usage,omitempty"`
+	RandomNumber *RandomNumberGame `json:"random_number,omitempty"`
+	BattleShips  *Battleships      `json:"battleships,omitempty"`
+	AnimalGame   *AnimalGame       `json:"animal_game,omitempty"`
+
+	// received are the responses queue messages this invocation has kept
+	// responses from, deleted once the state is saved.
+	received []*queue.Message
+}
+
+// defaultState builds the state for a user the skill has not seen before,
+// with games of their own.
+func (h *Handler) defaultState() *UserState {
+	return &UserState{
+		Model:        h.Model,
+		ImageModel:   h.ImageModel,
+		RandomNumber: NewRandomNumberGame(100),
+		BattleShips:  NewBattleShipSetup(),
+		AnimalGame:   NewAnimalGame(),
+	}
+}
+
+// setLastResponse replaces the user's last response, so it is spoken from its
+// first chunk.
+func (s *UserState) setLastResponse(response *chatmodels.LastResponse) {
+	s.LastResponse = response
+	s.ResponseChunk = 0
+}
+
+func (h *Handler) loadState(ctx context.Context, userID string) (*UserState, error) {
+	ctx, span := trace.Start(ctx, "loadState")
+	defer span.End()
+
+	data, err := h.StateStore.Get(ctx, userID)
+	if err != nil && !errors.Is(err, userstate.NotFoundErr) {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	var state *UserState
+	if errors.Is(err, userstate.NotFoundErr) {
+		state = h.defaultState()
+	} else if err = json.Unmarshal(data, &state); err != nil {
+		h.Logger.
+			With("error", err).
+			Error("failed to unmarshal user state, falling back to defaults")
+
+		state = h.defaultState()
+	}
+
+	if state.RandomNumber == nil {
+		state.RandomNumber = NewRandomNumberGame(100)
+	}
+	if state.BattleShips == nil {
+		state.BattleShips = NewBattleShipSetup()
+	}
+	if state.AnimalGame == nil {
+		state.AnimalGame = NewAnimalGame()
+	}
+	return state, nil
+}
+
+func (h *Handler) saveState(ctx context.Context, userID string, state *UserState) error {
+	ctx, span := trace.Start(ctx, "saveState")
+	defer span.End()
+
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+
+	err = h.StateStore.Put(ctx, userID, data)
+	if err != nil {
+		span.RecordError(err)
+	}
+	return err
+}
@@ -0,0 +1,108 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/jackmcguire1/alexa-chatgpt/internal/dom/chatmodels"
+	"github.com/jackmcguire1/alexa-chatgpt/internal/pkg/alexa"
+	"github.com/jackmcguire1/alexa-chatgpt/internal/pkg/userstate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func intentRequest(userID string, intent string, slots map[string]alexa.Slot) alexa.Request {
+	req := alexa.Request{
+		Body: alexa.ReqBody{
+			Type:   alexa.IntentRequestType,
+			Intent: alexa.Intent{Name: intent, Slots: slots},
+		},
+	}
+	req.Session.User.UserID = userID
+	return req
+}
+
+func TestModelSelectionIsPerUser(t *testing.T) {
+	h := NewHandler(logger, &chatmodels.MockClient{}, nil, nil, 0, chatmodels.CHAT_MODEL_SONNET, chatmodels.IMAGE_MODEL_FLUX, nil)
+
+	_, err := h.Invoke(context.Background(), intentRequest("alice", alexa.ModelIntent, map[string]alexa.Slot{
+		"chatModel": {Name: "chatModel", Value: "opus"},
+	}))
+	assert.NoError(t, err)
+
+	resp, err := h.Invoke(context.Background(), intentRequest("alice", alexa.ModelIntent, map[string]alexa.Slot{
+		"chatModel": {Name: "chatModel", Value: "which"},
+	}))
+	assert.NoError(t, err)
+	assert.Contains(t, resp.Body.OutputSpeech.Text, "text-model opus")
+
+	resp, err = h.Invoke(context.Background(), intentRequest("bob", alexa.ModelIntent, map[string]alexa.Slot{
+		"chatModel": {Name: "chatModel", Value: "which"},
+	}))
+	assert.NoError(t, err)
+	assert.Contains(t, resp.Body.OutputSpeech.Text, "text-model sonnet")
+	assert.Equal(t, chatmodels.CHAT_MODEL_SONNET, h.Model)
+}
+
+func TestBattleshipBoardIsPerUser(t *testing.T) {
+	mockChatGptService := &chatmodels.MockClient{}
+	mockChatGptService.On("TextGeneration", mock.Anything, mock.Anything, mock.Anything).Return("ok", nil)
+
+	store := userstate.NewMemoryStore()
+	h := NewHandler(logger, mockChatGptService, nil, nil, 0, chatmodels.CHAT_MODEL_SONNET, "", store)
+
+	_, err := h.Invoke(context.Background(), intentRequest("alice", alexa.BattleShipsIntent, map[string]alexa.Slot{
+		"x": {Name: "x", Value: "0"},
+		"y": {Name: "y", Value: "0"},
+	}))
+	assert.NoError(t, err)
+
+	alice, err := h.loadState(context.Background(), "alice")
+	require.NoError(t, err)
+	hits, _ := alice.BattleShips.TotalHitsAndMisses()
+	assert.Equal(t, 1, hits)
+
+	bob, err := h.loadState(context.Background(), "bob")
+	require.NoError(t, err)
+	hits, _ = bob.BattleShips.TotalHitsAndMisses()
+	assert.Equal(t, 0, hits)
+}
+
+func TestNewUsersGetTheirOwnGames(t *testing.T) {
+	h := NewHandler(logger, &chatmodels.MockClient{}, nil, nil, 0, chatmodels.CHAT_MODEL_SONNET, "", nil)
+
+	numbers := map[int]bool{}
+	for i := range 20 {
+		state, err := h.loadState(context.Background(), fmt.Sprint("user-", i))
+		require.NoError(t, err)
+		numbers[state.RandomNumber.Number] = true
+	}
+	assert.Greater(t, len(numbers), 1, "every new user must not start with the same secret number")
+}
+
+func TestUserStateRoundTrip(t *testing.T) {
+	state := &UserState{
+		Model:        chatmodels.CHAT_MODEL_GROK,
+		RandomNumber: &RandomNumberGame{Number: 42, MaxLimit: 100},
+		BattleShips:  NewBattleShipSetup(),
+		AnimalGame:   NewAnimalGame(),
+	}
+	state.BattleShips.Attack(0, 0)
+	state.AnimalGame.RequestHint()
+
+	data, err := json.Marshal(state)
+	require.NoError(t, err)
+
+	var restored *UserState
+	require.NoError(t, json.Unmarshal(data, &restored))
+
+	assert.Equal(t, chatmodels.CHAT_MODEL_GROK, restored.Model)
+	assert.Equal(t, 42, restored.RandomNumber.Number)
+	assert.Equal(t, state.BattleShips.board, restored.BattleShips.board)
+	assert.Equal(t, len(state.BattleShips.ships), len(restored.BattleShips.ships))
+	assert.Equal(t, state.AnimalGame.GetStatus(), restored.AnimalGame.GetStatus())
+	assert.Equal(t, state.AnimalGame.animal, restored.AnimalGame.animal)
+}
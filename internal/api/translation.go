@@ -0,0 +1,247 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/jackmcguire1/alexa-chatgpt/internal/pkg/alexa"
+)
+
+var (
+	MissingTargetLanguageErr = fmt.Errorf("no language to translate into")
+	MissingPhraseErr         = fmt.Errorf("nothing to translate")
+	GlossaryEntryErr         = fmt.Errorf("glossary entry needs a term and its translation")
+)
+
+// maxGlossaryTerms is the most terms a user's glossary holds.
+const maxGlossaryTerms = 20
+
+// nonLatinScripts are the languages in languageCodes not written in the Latin
+// alphabet, whose translations can come with a romanised version.
+var nonLatinScripts = map[string]bool{
+	"ar": true, "el": true, "hi": true, "ja": true, "ko": true, "ru": true, "uk": true, "zh": true,
+}
+
+// glossarySeparators join a term and its translation in a glossary entry, by
+// the language of the request's locale.
+var glossarySeparators = map[string]string{
+	"en": " as ",
+	"de": " als ",
+	"es": " como ",
+	"fr": " comme ",
+	"ja": "を",
+}
+
+// languageCodes maps spoken language names, in English and in the language
+// itself, and ISO 639-1 codes to ISO 639-1 codes.
+var languageCodes = map[string]string{
+	"arabic": "ar", "ar": "ar",
+	"chinese": "zh", "mandarin": "zh", "mandarin chinese": "zh", "zh": "zh",
+	"dutch": "nl", "nederlands": "nl", "nl": "nl",
+	"english": "en", "en": "en",
+	"french": "fr", "français": "fr", "francais": "fr", "fr": "fr",
+	"german": "de", "deutsch": "de", "de": "de",
+	"greek": "el", "el": "el",
+	"hindi": "hi", "hi": "hi",
+	"italian": "it", "italiano": "it", "it": "it",
+	"japanese": "ja", "nihongo": "ja", "ja": "ja",
+	"korean": "ko", "ko": "ko",
+	"polish": "pl", "polski": "pl", "pl": "pl",
+	"portuguese": "pt", "brazilian portuguese": "pt", "português": "pt", "pt": "pt",
+	"russian": "ru", "ru": "ru",
+	"spanish": "es", "español": "es", "espanol": "es", "castilian": "es", "es": "es",
+	"swedish": "sv", "svenska": "sv", "sv": "sv",
+	"turkish": "tr", "türkçe": "tr", "tr": "tr",
+	"ukrainian": "uk", "uk": "uk",
+	"welsh": "cy", "cymraeg": "cy", "cy": "cy",
+}
+
+// maxLanguageWords is the most words in a name in languageCodes.
+const maxLanguageWords = 2
+
+// translationLeadIns are dropped from the front of an utterance.
+var translationLeadIns = [][]string{
+	{"how", "do", "you", "say"},
+	{"how", "would", "you", "say"},
+	{"what", "is"},
+	{"what's"},
+	{"translate"},
+	{"say"},
+}
+
+// translationRequest is what the user asked to have translated, with ISO
+// 639-1 language codes. Source is empty when the user did not say it.
+type translationRequest struct {
+	Source string
+	Target string
+	Phrase string
+}
+
+// parseTranslation reads the TranslateIntent prompt slot, accepting phrasings
+// such as "english to french good morning", "from german into english guten
+// morgen", "good morning into french" and "how do you say thank you in
+// japanese".
+func parseTranslation(utterance string) (translationRequest, error) {
+	words := strings.FieldsFunc(utterance, func(r rune) bool {
+		return unicode.IsSpace(r) || r == '?' || r == '!'
+	})
+	words = dropLeadIn(words)
+
+	// languages first: "[from] <source> to <target> <phrase>" or "to <target> <phrase>"
+	i := 0
+	if i < len(words) && isWord(words[i], "from") {
+		i++
+	}
+	if source, n, ok := matchLanguage(words, i); ok && i+n < len(words) && isWord(words[i+n], "to", "into") {
+		// "hi to french" reads better as a phrase than as Hindi with nothing to translate
+		if target, m, ok := matchLanguage(words, i+n+1); ok && (i+n+1+m < len(words) || len(words[i]) > 2) {
+			return newTranslationRequest(source, target, words[i+n+1+m:])
+		}
+	}
+	if len(words) > 0 && isWord(words[0], "to", "into", "in") {
+		if target, n, ok := matchLanguage(words, 1); ok && 1+n < len(words) {
+			return newTranslationRequest("", target, words[1+n:])
+		}
+	}
+
+	// languages last: "<phrase> [from <source>] to|into|in <target>"
+	for n := maxLanguageWords; n >= 1; n-- {
+		end := len(words) - n
+		if end < 1 || !isWord(words[end-1], "to", "into", "in") {
+			continue
+		}
+		target, m, ok := matchLanguage(words, end)
+		if !ok || m != n {
+			continue
+		}
+		phrase := words[:end-1]
+		for k := maxLanguageWords; k >= 1; k-- {
+			from := len(phrase) - k - 1
+			if from < 0 || !isWord(phrase[from], "from") {
+				continue
+			}
+			if source, l, ok := matchLanguage(phrase, from+1); ok && l == k {
+				return newTranslationRequest(source, target, phrase[:from])
+			}
+		}
+		return newTranslationRequest("", target, phrase)
+	}
+
+	return translationRequest{}, MissingTargetLanguageErr
+}
+
+func newTranslationRequest(source, target string, phrase []string) (translationRequest, error) {
+	if len(phrase) == 0 {
+		return translationRequest{}, MissingPhraseErr
+	}
+	return translationRequest{Source: source, Target: target, Phrase: strings.Join(phrase, " ")}, nil
+}
+
+// matchLanguage matches the longest language name starting at words[i],
+// returning its code and how many words it took.
+func matchLanguage(words []string, i int) (string, int, bool) {
+	for n := min(maxLanguageWords, len(words)-i); n >= 1; n-- {
+		name := strings.ToLower(strings.Trim(strings.Join(words[i:i+n], " "), ".,"))
+		if code, ok := languageCodes[name]; ok {
+			return code, n, true
+		}
+	}
+	return "", 0, false
+}
+
+func dropLeadIn(words []string) []string {
+	for _, leadIn := range translationLeadIns {
+		if len(words) <= len(leadIn) {
+			continue
+		}
+		matched := true
+		for i, word := range leadIn {
+			if !isWord(words[i], word) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return words[len(leadIn):]
+		}
+	}
+	return words
+}
+
+func isWord(word string, options ...string) bool {
+	for _, option := range options {
+		if strings.EqualFold(word, option) {
+			return true
+		}
+	}
+	return false
+}
+
+// localeLanguage returns the ISO 639-1 code of a locale such as de-DE.
+func localeLanguage(locale string) string {
+	if locale == "" {
+		locale = DefaultLocale
+	}
+	language, _, _ := strings.Cut(locale, "-")
+	return strings.ToLower(language)
+}
+
+// detectLanguage asks the user's model which language phrase is in, returning
+// its ISO 639-1 code, or false when the answer is not a language it knows.
+func (h *Handler) detectLanguage(ctx context.Context, state *UserState, phrase string) (string, bool) {
+	ctx, span := trace.Start(ctx, "detectLanguage")
+	defer span.End()
+
+	answer, err := h.generate(
+		ctx,
+		state,
+		"",
+		"Which language is the following text written in? Reply with only its ISO 639-1 code.\n\n"+phrase,
+		state.Model,
+	)
+	if err != nil {
+		span.RecordError(err)
+		h.Logger.With("error", err).Error("failed to detect language of phrase to translate")
+		return "", false
+	}
+	code, ok := languageCodes[strings.ToLower(strings.Trim(answer, " \n\t.\"'`"))]
+	return code, ok
+}
+
+// parseGlossaryEntry reads the Glossary intent prompt slot, such as "cheers as
+// santé", using the separator of the locale's language.
+func parseGlossaryEntry(utterance, locale string) (string, string, error) {
+	separator, ok := glossarySeparators[localeLanguage(locale)]
+	if !ok {
+		separator = glossarySeparators["en"]
+	}
+	term, translation, ok := strings.Cut(utterance, separator)
+	term, translation = strings.TrimSpace(term), strings.TrimSpace(translation)
+	if !ok || term == "" || translation == "" {
+		return "", "", GlossaryEntryErr
+	}
+	return strings.ToLower(term), translation, nil
+}
+
+func (h *Handler) handleGlossary(_ context.Context, req alexa.Request, state *UserState, _ string) (alexa.Response, error) {
+	prompt := req.Body.Intent.Slots["prompt"].Value
+	msgs := messagesFor(req.Body.Locale)
+	title := msgs.get(msgGlossaryTitle)
+
+	term, translation, err := parseGlossaryEntry(prompt, req.Body.Locale)
+	if err != nil {
+		h.Logger.With("prompt", prompt).With("error", err).Info("could not parse glossary entry")
+		return alexa.NewResponse(title, msgs.get(msgGlossaryUnclear), false), nil
+	}
+	if _, ok := state.Glossary[term]; !ok && len(state.Glossary) >= maxGlossaryTerms {
+		return alexa.NewResponse(title, msgs.get(msgGlossaryFull, maxGlossaryTerms), false), nil
+	}
+
+	if state.Glossary == nil {
+		state.Glossary = map[string]string{}
+	}
+	state.Glossary[term] = translation
+	return alexa.NewResponse(title, msgs.get(msgGlossarySet, term, translation), false), nil
+}
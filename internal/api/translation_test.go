@@ -0,0 +1,245 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/jackmcguire1/alexa-chatgpt/internal/dom/chatmodels"
+	"github.com/jackmcguire1/alexa-chatgpt/internal/pkg/alexa"
+	"github.com/jackmcguire1/alexa-chatgpt/internal/pkg/queue"
+	"github.com/jackmcguire1/alexa-chatgpt/internal/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTranslation(t *testing.T) {
+	tests := []struct {
+		utterance string
+		want      translationRequest
+		err       error
+	}{
+		{utterance: "english to french good morning", want: translationRequest{Source: "en", Target: "fr", Phrase: "good morning"}},
+		{utterance: "en to fr good morning", want: translationRequest{Source: "en", Target: "fr", Phrase: "good morning"}},
+		{utterance: "from German into English guten Morgen", want: translationRequest{Source: "de", Target: "en", Phrase: "guten Morgen"}},
+		{utterance: "mandarin chinese to english ni hao", want: translationRequest{Source: "zh", Target: "en", Phrase: "ni hao"}},
+		{utterance: "to spanish hello", want: translationRequest{Target: "es", Phrase: "hello"}},
+		{utterance: "translate good morning into French", want: translationRequest{Target: "fr", Phrase: "good morning"}},
+		{utterance: "how do you say thank you in japanese?", want: translationRequest{Target: "ja", Phrase: "thank you"}},
+		{utterance: "what is where is the station in italian", want: translationRequest{Target: "it", Phrase: "where is the station"}},
+		{utterance: "good morning from english to german", want: translationRequest{Source: "en", Target: "de", Phrase: "good morning"}},
+		{utterance: "bonjour from français to brazilian portuguese", want: translationRequest{Source: "fr", Target: "pt", Phrase: "bonjour"}},
+		{utterance: "hi to french", want: translationRequest{Target: "fr", Phrase: "hi"}},
+		{utterance: "say hi in french", want: translationRequest{Target: "fr", Phrase: "hi"}},
+		{utterance: "english to french", err: MissingPhraseErr},
+		{utterance: "into german", err: MissingPhraseErr},
+		{utterance: "good morning", err: MissingTargetLanguageErr},
+		{utterance: "en", err: MissingTargetLanguageErr},
+		{utterance: "", err: MissingTargetLanguageErr},
+	}
+	for _, tt := range tests {
+		t.Run(tt.utterance, func(t *testing.T) {
+			got, err := parseTranslation(tt.utterance)
+			assert.ErrorIs(t, err, tt.err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestLocaleLanguage(t *testing.T) {
+	assert.Equal(t, "de", localeLanguage("de-DE"))
+	assert.Equal(t, "pt", localeLanguage("pt-BR"))
+	assert.Equal(t, "en", localeLanguage(""))
+}
+
+func translateRequest(prompt, locale string) alexa.Request {
+	return alexa.Request{
+		Body: alexa.ReqBody{
+			RequestID: "req-1",
+			Locale:    locale,
+			Type:      alexa.IntentRequestType,
+			Intent: alexa.Intent{
+				Name:  alexa.TranslateIntent,
+				Slots: map[string]alexa.Slot{"prompt": {Name: "prompt", Value: prompt}},
+			},
+		},
+	}
+}
+
+func translationQueues(source, target, phrase string) (*queue.MockQueue, *queue.MockQueue) {
+	mockRequestsQueue := &queue.MockQueue{}
+	mockRequestsQueue.On("PushMessage", mock.Anything, mock.MatchedBy(func(r *chatmodels.Request) bool {
+		return r.SourceLanguage == source && r.TargetLanguage == target && r.Prompt == phrase
+	})).Return(nil).Once()
+
+	mockResponsesQueue := &queue.MockQueue{}
+	mockResponsesQueue.On("Ack", mock.Anything, mock.Anything).Return(nil)
+	mockResponsesQueue.On("ReceiveMessage", mock.Anything, mock.Anything).Return([]byte(utils.ToJSON(chatmodels.LastResponse{
+		RequestID: "req-1",
+		Response:  "bonjour",
+		Model:     chatmodels.CHAT_MODEL_TRANSLATIONS.String(),
+		TimeDiff:  "1",
+	})), nil)
+	return mockRequestsQueue, mockResponsesQueue
+}
+
+func TestTranslateDefaultsSourceToLocale(t *testing.T) {
+	mockRequestsQueue, mockResponsesQueue := translationQueues("de", "fr", "guten Morgen")
+	h := NewHandler(logger, &chatmodels.MockClient{}, mockResponsesQueue, mockRequestsQueue, 0, chatmodels.CHAT_MODEL_SONNET, "", nil)
+
+	_, err := h.Invoke(context.Background(), translateRequest("guten Morgen into French", "de-DE"))
+	require.NoError(t, err)
+	mockRequestsQueue.AssertExpectations(t)
+}
+
+func TestTranslateDetectsSourceLanguage(t *testing.T) {
+	mockChatGptService := &chatmodels.MockClient{}
+	mockChatGptService.On("TextGeneration", mock.Anything, mock.MatchedBy(func(prompt string) bool {
+		return assert.Contains(t, prompt, "ISO 639-1") && assert.Contains(t, prompt, "buongiorno")
+	}), chatmodels.CHAT_MODEL_SONNET).Return("it.\n", nil).Once()
+
+	mockRequestsQueue, mockResponsesQueue := translationQueues("it", "fr", "buongiorno")
+	h := NewHandler(logger, mockChatGptService, mockResponsesQueue, mockRequestsQueue, 0, chatmodels.CHAT_MODEL_SONNET, "", nil)
+	h.DetectTranslationSource = true
+
+	_, err := h.Invoke(context.Background(), translateRequest("buongiorno into French", "en-US"))
+	require.NoError(t, err)
+	mockChatGptService.AssertExpectations(t)
+	mockRequestsQueue.AssertExpectations(t)
+}
+
+func TestTranslateExplainsUnclearRequests(t *testing.T) {
+	mockRequestsQueue := &queue.MockQueue{}
+	h := NewHandler(logger, &chatmodels.MockClient{}, &queue.MockQueue{}, mockRequestsQueue, 0, chatmodels.CHAT_MODEL_SONNET, "", nil)
+
+	for _, prompt := range []string{"", "hello", "english to french"} {
+		resp, err := h.Invoke(context.Background(), translateRequest(prompt, "en-US"))
+		require.NoError(t, err)
+		assert.Contains(t, resp.Body.OutputSpeech.Text, "translate good morning into French")
+		assert.False(t, resp.Body.ShouldEndSession)
+	}
+	mockRequestsQueue.AssertNotCalled(t, "PushMessage", mock.Anything, mock.Anything)
+}
+
+func TestTranslateUsesChosenModelAndGlossary(t *testing.T) {
+	tests := []struct {
+		name             string
+		translationModel chatmodels.ChatModel
+		prompt           string
+		want             *chatmodels.Request
+	}{
+		{
+			name:   "user's model",
+			prompt: "cheers into korean",
+			want: &chatmodels.Request{
+				TranslationModel: chatmodels.CHAT_MODEL_OPUS,
+				TargetLanguage:   "ko",
+				Romanize:         true,
+			},
+		},
+		{
+			name:             "configured model",
+			translationModel: chatmodels.CHAT_MODEL_TRANSLATIONS,
+			prompt:           "cheers into french",
+			want: &chatmodels.Request{
+				TranslationModel: chatmodels.CHAT_MODEL_TRANSLATIONS,
+				TargetLanguage:   "fr",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			glossary := map[string]string{"cheers": "santé"}
+			mockRequestsQueue := &queue.MockQueue{}
+			mockRequestsQueue.On("PushMessage", mock.Anything, mock.MatchedBy(func(r *chatmodels.Request) bool {
+				return r.Model == chatmodels.CHAT_MODEL_TRANSLATIONS &&
+					r.TranslationModel == tt.want.TranslationModel &&
+					r.TargetLanguage == tt.want.TargetLanguage &&
+					r.Romanize == tt.want.Romanize &&
+					assert.Equal(t, glossary, r.Glossary)
+			})).Return(nil).Once()
+			mockResponsesQueue := &queue.MockQueue{}
+			mockResponsesQueue.On("Ack", mock.Anything, mock.Anything).Return(nil)
+			mockResponsesQueue.On("ReceiveMessage", mock.Anything, mock.Anything).Return([]byte(utils.ToJSON(chatmodels.LastResponse{
+				RequestID: "req-1",
+				UserID:    "alice",
+				Response:  "santé",
+				Model:     chatmodels.CHAT_MODEL_TRANSLATIONS.String(),
+			})), nil)
+
+			h := NewHandler(logger, &chatmodels.MockClient{}, mockResponsesQueue, mockRequestsQueue, 0, chatmodels.CHAT_MODEL_SONNET, "", nil)
+			h.TranslationModel = tt.translationModel
+			h.RomanizeTranslations = true
+			require.NoError(t, h.saveState(context.Background(), "alice", &UserState{Model: chatmodels.CHAT_MODEL_OPUS, Glossary: glossary}))
+
+			req := translateRequest(tt.prompt, "en-US")
+			req.Session.User.UserID = "alice"
+			_, err := h.Invoke(context.Background(), req)
+			require.NoError(t, err)
+			mockRequestsQueue.AssertExpectations(t)
+		})
+	}
+}
+
+func TestParseGlossaryEntry(t *testing.T) {
+	tests := []struct {
+		utterance   string
+		locale      string
+		term        string
+		translation string
+		err         error
+	}{
+		{utterance: "Cheers as santé", locale: "en-GB", term: "cheers", translation: "santé"},
+		{utterance: "Prost als cheers", locale: "de-DE", term: "prost", translation: "cheers"},
+		{utterance: "salud como cheers", locale: "es-ES", term: "salud", translation: "cheers"},
+		{utterance: "santé comme cheers", locale: "fr-FR", term: "santé", translation: "cheers"},
+		{utterance: "乾杯をcheers", locale: "ja-JP", term: "乾杯", translation: "cheers"},
+		{utterance: "cheers as santé", locale: "pt-BR", term: "cheers", translation: "santé"},
+		{utterance: "cheers", locale: "en-US", err: GlossaryEntryErr},
+		{utterance: "as santé", locale: "en-US", err: GlossaryEntryErr},
+	}
+	for _, tt := range tests {
+		t.Run(tt.utterance, func(t *testing.T) {
+			term, translation, err := parseGlossaryEntry(tt.utterance, tt.locale)
+			assert.ErrorIs(t, err, tt.err)
+			assert.Equal(t, tt.term, term)
+			assert.Equal(t, tt.translation, translation)
+		})
+	}
+}
+
+func TestGlossaryIntent(t *testing.T) {
+	h := NewHandler(logger, &chatmodels.MockClient{}, &queue.MockQueue{}, &queue.MockQueue{}, 0, chatmodels.CHAT_MODEL_SONNET, "", nil)
+	glossaryRequest := func(prompt string) alexa.Request {
+		req := translateRequest(prompt, "en-US")
+		req.Session.User.UserID = "alice"
+		req.Body.Intent.Name = alexa.GlossaryIntent
+		return req
+	}
+
+	resp, err := h.Invoke(context.Background(), glossaryRequest("cheers as santé"))
+	require.NoError(t, err)
+	assert.Equal(t, "Ok, I will always translate cheers as santé", resp.Body.OutputSpeech.Text)
+
+	resp, err = h.Invoke(context.Background(), glossaryRequest("cheers"))
+	require.NoError(t, err)
+	assert.Contains(t, resp.Body.OutputSpeech.Text, "try saying always translate")
+
+	for i := 1; i < maxGlossaryTerms; i++ {
+		_, err = h.Invoke(context.Background(), glossaryRequest(fmt.Sprintf("term %d as terme %d", i, i)))
+		require.NoError(t, err)
+	}
+	resp, err = h.Invoke(context.Background(), glossaryRequest("one more as un de plus"))
+	require.NoError(t, err)
+	assert.Equal(t, "Your glossary is full, it holds up to 20 terms", resp.Body.OutputSpeech.Text)
+
+	// terms already in the glossary can still be changed
+	resp, err = h.Invoke(context.Background(), glossaryRequest("cheers as à la vôtre"))
+	require.NoError(t, err)
+	assert.Equal(t, "Ok, I will always translate cheers as à la vôtre", resp.Body.OutputSpeech.Text)
+
+	state, err := h.loadState(context.Background(), "alice")
+	require.NoError(t, err)
+	assert.Len(t, state.Glossary, maxGlossaryTerms)
+}
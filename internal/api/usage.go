@@ -0,0 +1,99 @@
+package api
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/jackmcguire1/alexa-chatgpt/internal/dom/chatmodels"
+	"github.com/jackmcguire1/alexa-chatgpt/internal/pkg/alexa"
+)
+
+// UsageTotals are a user's running token and cost totals per model.
+type UsageTotals map[string]chatmodels.Usage
+
+// recordUsage adds what generating response used to the user's totals.
+func (state *UserState) recordUsage(response *chatmodels.LastResponse) {
+	if response.Usage == nil {
+		return
+	}
+	state.addUsage(response.Model, *response.Usage)
+}
+
+func (state *UserState) addUsage(model string, used chatmodels.Usage) {
+	if used == (chatmodels.Usage{}) {
+		return
+	}
+	if state.Usage == nil {
+		state.Usage = UsageTotals{}
+	}
+	usage := state.Usage[model]
+	usage.Add(used)
+	state.Usage[model] = usage
+}
+
+// generate asks model to answer prompt, led by system when it isn't empty,
+// and adds what the call used to the user's totals. It is for the calls the
+// skill makes itself, rather than through the requests queue and worker.
+func (h *Handler) generate(ctx context.Context, state *UserState, system, prompt string, model chatmodels.ChatModel) (string, error) {
+	generation := &chatmodels.Generation{}
+	ctx = chatmodels.WithGeneration(ctx, generation)
+
+	var answer string
+	var err error
+	if system == "" {
+		answer, err = h.ChatGptService.TextGeneration(ctx, prompt, model)
+	} else {
+		answer, err = h.ChatGptService.TextGenerationWithSystem(ctx, system, prompt, model)
+	}
+
+	if generation.Model != "" {
+		model = generation.Model
+	}
+	state.addUsage(model.String(), generation.Usage)
+	return answer, err
+}
+
+// models returns the models in the totals, most tokens first.
+func (t UsageTotals) models() []string {
+	models := make([]string, 0, len(t))
+	for model := range t {
+		models = append(models, model)
+	}
+	sort.Slice(models, func(i, j int) bool {
+		if t[models[i]].Tokens() != t[models[j]].Tokens() {
+			return t[models[i]].Tokens() > t[models[j]].Tokens()
+		}
+		return models[i] < models[j]
+	})
+	return models
+}
+
+func (t UsageTotals) total() chatmodels.Usage {
+	var total chatmodels.Usage
+	for _, usage := range t {
+		total.Add(usage)
+	}
+	return total
+}
+
+// handleUsage tells the user how many tokens they have used and what they
+// cost, with a line per model on the card.
+func (h *Handler) handleUsage(_ context.Context, req alexa.Request, state *UserState, _ string) (alexa.Response, error) {
+	msgs := messagesFor(req.Body.Locale)
+	title := msgs.get(msgUsageTitle)
+
+	total := state.Usage.total()
+	if total.Tokens() == 0 {
+		return alexa.NewResponse(title, msgs.get(msgUsageNone), false), nil
+	}
+
+	models := state.Usage.models()
+	text := msgs.get(msgUsageTotal, total.Tokens(), total.Cost, models[0])
+	lines := []string{text}
+	for _, model := range models {
+		usage := state.Usage[model]
+		lines = append(lines, msgs.get(msgUsageModel, model, usage.Tokens(), usage.Cost))
+	}
+	return alexa.NewSSMLResponse(title, strings.Join(lines, "\n"), alexa.NewSSML().Text(text), false), nil
+}
@@ -0,0 +1,96 @@
+package api
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackmcguire1/alexa-chatgpt/internal/dom/chatmodels"
+	"github.com/jackmcguire1/alexa-chatgpt/internal/pkg/alexa"
+	"github.com/jackmcguire1/alexa-chatgpt/internal/pkg/queue"
+	"github.com/jackmcguire1/alexa-chatgpt/internal/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUsageIsTotalledPerUserAndModel(t *testing.T) {
+	mockResponsesQueue := &queue.MockQueue{}
+	mockResponsesQueue.On("Ack", mock.Anything, mock.Anything).Return(nil)
+	for _, response := range []*chatmodels.LastResponse{
+		{UserID: "alice", Response: "one", Model: "sonnet", Usage: &chatmodels.Usage{InputTokens: 100, OutputTokens: 50, Cost: 0.01}},
+		{UserID: "alice", Response: "two", Model: "opus", Usage: &chatmodels.Usage{InputTokens: 400, OutputTokens: 200, Cost: 0.25}},
+		{UserID: "alice", Response: "three", Model: "sonnet", Usage: &chatmodels.Usage{InputTokens: 100, OutputTokens: 50, Cost: 0.01}},
+		{UserID: "alice", Response: "four", Model: "llama"},
+	} {
+		mockResponsesQueue.On("ReceiveMessage", mock.Anything, mock.Anything).Return([]byte(utils.ToJSON(response)), nil).Once()
+	}
+
+	h := NewHandler(logger, &chatmodels.MockClient{}, mockResponsesQueue, &queue.MockQueue{}, 1, chatmodels.CHAT_MODEL_SONNET, "", nil)
+
+	resp, err := h.Invoke(context.Background(), intentRequest("alice", alexa.UsageIntent, nil))
+	require.NoError(t, err)
+	assert.Equal(t, "You haven't used any tokens yet", resp.Body.OutputSpeech.Text)
+
+	for range 4 {
+		_, err = h.Invoke(context.Background(), lastResponseRequest("alice"))
+		require.NoError(t, err)
+	}
+	// speaking the last response again doesn't count it twice
+	mockResponsesQueue.On("ReceiveMessage", mock.Anything, mock.Anything).Return(nil, queue.EmptyMessageErr)
+	_, err = h.Invoke(context.Background(), lastResponseRequest("alice"))
+	require.NoError(t, err)
+
+	state, err := h.loadState(context.Background(), "alice")
+	require.NoError(t, err)
+	assert.Equal(t, UsageTotals{
+		"sonnet": {InputTokens: 200, OutputTokens: 100, Cost: 0.02},
+		"opus":   {InputTokens: 400, OutputTokens: 200, Cost: 0.25},
+	}, state.Usage)
+
+	resp, err = h.Invoke(context.Background(), intentRequest("alice", alexa.UsageIntent, nil))
+	require.NoError(t, err)
+	assert.Equal(t, "<speak>You have used 900 tokens, costing about $0.27, mostly with opus</speak>", resp.Body.OutputSpeech.SSML)
+	assert.Equal(t, "You have used 900 tokens, costing about $0.27, mostly with opus\nopus: 600 tokens, $0.2500\nsonnet: 300 tokens, $0.0200", resp.Body.Card.Text)
+
+	resp, err = h.Invoke(context.Background(), intentRequest("bob", alexa.UsageIntent, nil))
+	require.NoError(t, err)
+	assert.Equal(t, "You haven't used any tokens yet", resp.Body.OutputSpeech.Text)
+}
+
+func TestSkillGenerationsAddToUsage(t *testing.T) {
+	tests := []struct {
+		name string
+		req  alexa.Request
+	}{
+		{name: "battleship turn", req: intentRequest("alice", alexa.BattleShipsIntent, map[string]alexa.Slot{
+			"x": {Name: "x", Value: "0"},
+			"y": {Name: "y", Value: "0"},
+		})},
+		{name: "random number guess", req: intentRequest("alice", alexa.RandomNumberIntent, map[string]alexa.Slot{
+			"number": {Name: "number", Value: "1000"},
+		})},
+		{name: "random fact", req: intentRequest("alice", alexa.RandomFactIntent, nil)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockChatGptService := &chatmodels.MockClient{}
+			mockChatGptService.On("TextGeneration", mock.Anything, mock.Anything, chatmodels.CHAT_MODEL_SONNET).
+				Run(func(args mock.Arguments) {
+					// the answer came from the fallback model
+					generation := chatmodels.GenerationFrom(args.Get(0).(context.Context))
+					generation.Model = chatmodels.CHAT_MODEL_OPUS
+					generation.Usage.Add(chatmodels.Usage{InputTokens: 30, OutputTokens: 12, Cost: 0.001})
+				}).
+				Return("a game host line", nil)
+
+			h := NewHandler(logger, mockChatGptService, nil, nil, 0, chatmodels.CHAT_MODEL_SONNET, "", nil)
+
+			_, err := h.Invoke(context.Background(), tt.req)
+			require.NoError(t, err)
+
+			state, err := h.loadState(context.Background(), "alice")
+			require.NoError(t, err)
+			assert.Equal(t, UsageTotals{"opus": {InputTokens: 30, OutputTokens: 12, Cost: 0.001}}, state.Usage)
+		})
+	}
+}
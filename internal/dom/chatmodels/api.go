@@ -17,8 +17,8 @@ const (
 
 // Message is a single turn in a conversation.
 type Message struct {
-	Role    MessageRole
-	Content string
+	Role    MessageRole `json:"role"`
+	Content string      `json:"content"`
 }
 
 // GenerateOptions configures a content generation call.
@@ -32,11 +32,40 @@ type GenerateOptions struct {
 // GenerateResponse holds the result of a generation call.
 type GenerateResponse struct {
 	Content string
+	Usage   Usage
+	// StopReason is why the model stopped, in the provider's own words, such
+	// as end_turn, max_tokens or length.
+	StopReason string
+}
+
+// Usage counts the tokens generation calls used and, once priced with the
+// model's Pricing, what they cost in US dollars.
+type Usage struct {
+	InputTokens  int     `json:"input_tokens"`
+	OutputTokens int     `json:"output_tokens"`
+	Cost         float64 `json:"cost,omitempty"`
 }
 
+// Add adds other's tokens and cost to u.
+func (u *Usage) Add(other Usage) {
+	u.InputTokens += other.InputTokens
+	u.OutputTokens += other.OutputTokens
+	u.Cost += other.Cost
+}
+
+// Tokens is the number of input and output tokens used.
+func (u Usage) Tokens() int {
+	return u.InputTokens + u.OutputTokens
+}
+
+// DeltaFunc receives each piece of text as a streamed generation produces it.
+// Returning an error stops the stream and StreamContent returns that error.
+type DeltaFunc func(delta string) error
+
 // BedrockAPI is the single interface for all AI operations via AWS Bedrock.
 type BedrockAPI interface {
 	GenerateContent(ctx context.Context, messages []Message, opts GenerateOptions) (*GenerateResponse, error)
+	StreamContent(ctx context.Context, messages []Message, opts GenerateOptions, onDelta DeltaFunc) (*GenerateResponse, error)
 	GenerateImage(ctx context.Context, prompt string, model string) ([]byte, error)
 }
 
@@ -44,11 +73,13 @@ type BedrockAPI interface {
 // (OpenAI-compatible API for third-party models such as xAI Grok and OpenAI GPT).
 type MantleAPI interface {
 	GenerateContent(ctx context.Context, messages []Message, opts GenerateOptions) (*GenerateResponse, error)
+	StreamContent(ctx context.Context, messages []Message, opts GenerateOptions, onDelta DeltaFunc) (*GenerateResponse, error)
 }
 
 // CloudflareAPI is the interface for chat and image operations via Cloudflare Workers AI.
 type CloudflareAPI interface {
 	GenerateContent(ctx context.Context, messages []Message, opts GenerateOptions) (*GenerateResponse, error)
+	StreamContent(ctx context.Context, messages []Message, opts GenerateOptions, onDelta DeltaFunc) (*GenerateResponse, error)
 	GenerateImage(ctx context.Context, prompt string, model string) ([]byte, error)
 }
 
@@ -64,6 +95,10 @@ func (m *mockBedrockAPI) GenerateContent(ctx context.Context, messages []Message
 	return nil, args.Error(1)
 }
 
+func (m *mockBedrockAPI) StreamContent(ctx context.Context, messages []Message, opts GenerateOptions, onDelta DeltaFunc) (*GenerateResponse, error) {
+	return mockStream(m.Called(ctx, messages, opts), onDelta)
+}
+
 func (m *mockBedrockAPI) GenerateImage(ctx context.Context, prompt string, model string) (res []byte, err error) {
 	args := m.Called(ctx, prompt, model)
 	if args.Get(0) != nil {
@@ -84,6 +119,10 @@ func (m *mockMantleAPI) GenerateContent(ctx context.Context, messages []Message,
 	return nil, args.Error(1)
 }
 
+func (m *mockMantleAPI) StreamContent(ctx context.Context, messages []Message, opts GenerateOptions, onDelta DeltaFunc) (*GenerateResponse, error) {
+	return mockStream(m.Called(ctx, messages, opts), onDelta)
+}
+
 type mockCloudflareAPI struct {
 	mock.Mock
 }
@@ -96,6 +135,10 @@ func (m *mockCloudflareAPI) GenerateContent(ctx context.Context, messages []Mess
 	return nil, args.Error(1)
 }
 
+func (m *mockCloudflareAPI) StreamContent(ctx context.Context, messages []Message, opts GenerateOptions, onDelta DeltaFunc) (*GenerateResponse, error) {
+	return mockStream(m.Called(ctx, messages, opts), onDelta)
+}
+
 func (m *mockCloudflareAPI) GenerateImage(ctx context.Context, prompt string, model string) (res []byte, err error) {
 	args := m.Called(ctx, prompt, model)
 	if args.Get(0) != nil {
@@ -103,3 +146,19 @@ func (m *mockCloudflareAPI) GenerateImage(ctx context.Context, prompt string, mo
 	}
 	return res, args.Error(1)
 }
+
+// mockStream hands each string in the mock's optional third return value to
+// onDelta before returning the response and error.
+func mockStream(args mock.Arguments, onDelta DeltaFunc) (*GenerateResponse, error) {
+	if len(args) > 2 {
+		for _, delta := range args.Get(2).([]string) {
+			if err := onDelta(delta); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if args.Get(0) != nil {
+		return args.Get(0).(*GenerateResponse), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
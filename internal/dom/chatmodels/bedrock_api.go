@@ -28,8 +28,10 @@ func NewBedrockApiClient() *BedrockApiClient {
 		),
 	}
 
+	// retries are left to the shared RetryPolicy
 	cfg, err := config.LoadDefaultConfig(context.Background(),
 		config.WithHTTPClient(httpClient),
+		config.WithRetryer(func() aws.Retryer { return aws.NopRetryer{} }),
 	)
 	if err != nil {
 		panic(fmt.Sprintf("failed to load AWS config for Bedrock: %v", err))
@@ -39,12 +41,9 @@ func NewBedrockApiClient() *BedrockApiClient {
 	}
 }
 
-// GenerateContent calls the Bedrock Converse API.
-func (api *BedrockApiClient) GenerateContent(
-	ctx context.Context,
-	messages []Message,
-	opts GenerateOptions,
-) (*GenerateResponse, error) {
+// converseParts maps the conversation and options onto the messages, system
+// prompts and inference configuration shared by Converse and ConverseStream.
+func converseParts(messages []Message, opts GenerateOptions) ([]bedrocktypes.Message, []bedrocktypes.SystemContentBlock, *bedrocktypes.InferenceConfiguration) {
 	var bedrockMessages []bedrocktypes.Message
 	var systemPrompts []bedrocktypes.SystemContentBlock
 
@@ -71,17 +70,9 @@ func (api *BedrockApiClient) GenerateContent(
 		}
 	}
 
-	input := &bedrockruntime.ConverseInput{
-		ModelId:  aws.String(opts.Model),
-		Messages: bedrockMessages,
-	}
-
-	if len(systemPrompts) > 0 {
-		input.System = systemPrompts
-	}
-
+	var inferenceConfig *bedrocktypes.InferenceConfiguration
 	if opts.Temperature > 0 || opts.MaxTokens > 0 {
-		inferenceConfig := &bedrocktypes.InferenceConfiguration{}
+		inferenceConfig = &bedrocktypes.InferenceConfiguration{}
 		if opts.Temperature > 0 {
 			temp := float32(opts.Temperature)
 			inferenceConfig.Temperature = &temp
@@ -90,7 +81,27 @@ func (api *BedrockApiClient) GenerateContent(
 			maxTokens := int32(opts.MaxTokens)
 			inferenceConfig.MaxTokens = &maxTokens
 		}
-		input.InferenceConfig = inferenceConfig
+	}
+
+	return bedrockMessages, systemPrompts, inferenceConfig
+}
+
+// GenerateContent calls the Bedrock Converse API.
+func (api *BedrockApiClient) GenerateContent(
+	ctx context.Context,
+	messages []Message,
+	opts GenerateOptions,
+) (*GenerateResponse, error) {
+	bedrockMessages, systemPrompts, inferenceConfig := converseParts(messages, opts)
+
+	input := &bedrockruntime.ConverseInput{
+		ModelId:         aws.String(opts.Model),
+		Messages:        bedrockMessages,
+		InferenceConfig: inferenceConfig,
+	}
+
+	if len(systemPrompts) > 0 {
+		input.System = systemPrompts
 	}
 
 	resp, err := api.Client.Converse(ctx, input)
@@ -107,7 +118,83 @@ func (api *BedrockApiClient) GenerateContent(
 		}
 	}
 
-	return &GenerateResponse{Content: responseText.String()}, nil
+	return &GenerateResponse{
+		Content:    responseText.String(),
+		Usage:      bedrockUsage(resp.Usage),
+		StopReason: string(resp.StopReason),
+	}, nil
+}
+
+func bedrockUsage(usage *bedrocktypes.TokenUsage) Usage {
+	if usage == nil {
+		return Usage{}
+	}
+	return Usage{
+		InputTokens:  int(aws.ToInt32(usage.InputTokens)),
+		OutputTokens: int(aws.ToInt32(usage.OutputTokens)),
+	}
+}
+
+// StreamContent calls the Bedrock ConverseStream API, handing each text delta
+// to onDelta as it arrives.
+func (api *BedrockApiClient) StreamContent(
+	ctx context.Context,
+	messages []Message,
+	opts GenerateOptions,
+	onDelta DeltaFunc,
+) (*GenerateResponse, error) {
+	bedrockMessages, systemPrompts, inferenceConfig := converseParts(messages, opts)
+
+	input := &bedrockruntime.ConverseStreamInput{
+		ModelId:         aws.String(opts.Model),
+		Messages:        bedrockMessages,
+		InferenceConfig: inferenceConfig,
+	}
+
+	if len(systemPrompts) > 0 {
+		input.System = systemPrompts
+	}
+
+	resp, err := api.Client.ConverseStream(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("bedrock converse stream error: %w", err)
+	}
+
+	stream := resp.GetStream()
+	defer stream.Close()
+
+	var responseText strings.Builder
+	result := &GenerateResponse{}
+	for event := range stream.Events() {
+		switch event := event.(type) {
+		case *bedrocktypes.ConverseStreamOutputMemberMessageStop:
+			result.StopReason = string(event.Value.StopReason)
+			continue
+		case *bedrocktypes.ConverseStreamOutputMemberMetadata:
+			result.Usage = bedrockUsage(event.Value.Usage)
+			continue
+		}
+
+		blockDelta, ok := event.(*bedrocktypes.ConverseStreamOutputMemberContentBlockDelta)
+		if !ok {
+			continue
+		}
+		textDelta, ok := blockDelta.Value.Delta.(*bedrocktypes.ContentBlockDeltaMemberText)
+		if !ok {
+			continue
+		}
+
+		responseText.WriteString(textDelta.Value)
+		if err := onDelta(textDelta.Value); err != nil {
+			return nil, err
+		}
+	}
+	if err := stream.Err(); err != nil {
+		return nil, fmt.Errorf("bedrock converse stream error: %w", err)
+	}
+
+	result.Content = responseText.String()
+	return result, nil
 }
 
 // GenerateImage calls the Bedrock InvokeModel API.
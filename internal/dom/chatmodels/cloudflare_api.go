@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 
 	openai "github.com/openai/openai-go"
 	"github.com/openai/openai-go/option"
@@ -18,7 +19,7 @@ import (
 // CloudflareApiClient calls Cloudflare Workers AI using the OpenAI-compatible
 // Chat Completions endpoint for text and a direct REST call for image generation.
 type CloudflareApiClient struct {
-	accountID  string
+	baseURL    string
 	apiKey     string
 	chatClient openai.Client
 	httpClient *http.Client
@@ -32,22 +33,31 @@ func NewCloudflareApiClient(accountID, apiKey string) *CloudflareApiClient {
 		),
 	}
 
-	baseURL := fmt.Sprintf("https://api.cloudflare.com/client/v4/accounts/%s/ai/v1", accountID)
+	baseURL := fmt.Sprintf("https://api.cloudflare.com/client/v4/accounts/%s/ai", accountID)
+	return newCloudflareApiClient(baseURL, apiKey, instrumented)
+}
+
+// newCloudflareApiClient builds a client against the account's AI base URL.
+// Retries are left to the shared RetryPolicy.
+func newCloudflareApiClient(baseURL, apiKey string, httpClient *http.Client) *CloudflareApiClient {
 	chatClient := openai.NewClient(
-		option.WithBaseURL(baseURL),
+		option.WithBaseURL(baseURL+"/v1"),
 		option.WithAPIKey(apiKey),
-		option.WithHTTPClient(instrumented),
+		option.WithHTTPClient(httpClient),
+		option.WithMaxRetries(0),
 	)
 
 	return &CloudflareApiClient{
-		accountID:  accountID,
+		baseURL:    baseURL,
 		apiKey:     apiKey,
 		chatClient: chatClient,
-		httpClient: instrumented,
+		httpClient: httpClient,
 	}
 }
 
-func (api *CloudflareApiClient) GenerateContent(ctx context.Context, messages []Message, opts GenerateOptions) (*GenerateResponse, error) {
+// chatCompletionParams builds a Chat Completions request. Streams ask for the
+// final usage chunk, which OpenAI-compatible endpoints only send when asked.
+func chatCompletionParams(messages []Message, opts GenerateOptions, stream bool) openai.ChatCompletionNewParams {
 	var params openai.ChatCompletionNewParams
 	params.Model = opts.Model
 
@@ -65,8 +75,14 @@ func (api *CloudflareApiClient) GenerateContent(ctx context.Context, messages []
 	if opts.MaxTokens > 0 {
 		params.MaxTokens = openai.Int(int64(opts.MaxTokens))
 	}
+	if stream {
+		params.StreamOptions.IncludeUsage = openai.Bool(true)
+	}
+	return params
+}
 
-	resp, err := api.chatClient.Chat.Completions.New(ctx, params)
+func (api *CloudflareApiClient) GenerateContent(ctx context.Context, messages []Message, opts GenerateOptions) (*GenerateResponse, error) {
+	resp, err := api.chatClient.Chat.Completions.New(ctx, chatCompletionParams(messages, opts, false))
 	if err != nil {
 		return nil, fmt.Errorf("cloudflare chat error: %w", err)
 	}
@@ -74,13 +90,55 @@ func (api *CloudflareApiClient) GenerateContent(ctx context.Context, messages []
 		return nil, fmt.Errorf("cloudflare chat: no choices in response")
 	}
 
-	return &GenerateResponse{Content: resp.Choices[0].Message.Content}, nil
+	return &GenerateResponse{
+		Content:    resp.Choices[0].Message.Content,
+		Usage:      completionUsage(resp.Usage),
+		StopReason: resp.Choices[0].FinishReason,
+	}, nil
+}
+
+func completionUsage(usage openai.CompletionUsage) Usage {
+	return Usage{InputTokens: int(usage.PromptTokens), OutputTokens: int(usage.CompletionTokens)}
+}
+
+// StreamContent calls the streaming Chat Completions endpoint, handing each
+// content delta to onDelta as it arrives.
+func (api *CloudflareApiClient) StreamContent(ctx context.Context, messages []Message, opts GenerateOptions, onDelta DeltaFunc) (*GenerateResponse, error) {
+	stream := api.chatClient.Chat.Completions.NewStreaming(ctx, chatCompletionParams(messages, opts, true))
+	defer stream.Close()
+
+	var responseText strings.Builder
+	result := &GenerateResponse{}
+	for stream.Next() {
+		chunk := stream.Current()
+		if chunk.Usage.PromptTokens > 0 || chunk.Usage.CompletionTokens > 0 {
+			result.Usage = completionUsage(chunk.Usage)
+		}
+		if len(chunk.Choices) > 0 && chunk.Choices[0].FinishReason != "" {
+			result.StopReason = chunk.Choices[0].FinishReason
+		}
+		if len(chunk.Choices) == 0 || chunk.Choices[0].Delta.Content == "" {
+			continue
+		}
+
+		delta := chunk.Choices[0].Delta.Content
+		responseText.WriteString(delta)
+		if err := onDelta(delta); err != nil {
+			return nil, err
+		}
+	}
+	if err := stream.Err(); err != nil {
+		return nil, fmt.Errorf("cloudflare chat error: %w", err)
+	}
+
+	result.Content = responseText.String()
+	return result, nil
 }
 
 // GenerateImage calls the Cloudflare Workers AI image generation endpoint.
 // The REST API returns JSON: {"result":{"image":"<base64_jpeg>"},"success":true,...}
 func (api *CloudflareApiClient) GenerateImage(ctx context.Context, prompt string, model string) ([]byte, error) {
-	url := fmt.Sprintf("https://api.cloudflare.com/client/v4/accounts/%s/ai/run/%s", api.accountID, model)
+	url := fmt.Sprintf("%s/run/%s", api.baseURL, model)
 
 	body, err := json.Marshal(map[string]string{"prompt": prompt})
 	if err != nil {
@@ -106,7 +164,7 @@ func (api *CloudflareApiClient) GenerateImage(ctx context.Context, prompt string
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("cloudflare image: HTTP %d: %s", resp.StatusCode, string(respBody))
+		return nil, &statusError{Op: "cloudflare image", StatusCode: resp.StatusCode, Header: resp.Header, Body: string(respBody)}
 	}
 
 	var apiResp struct {
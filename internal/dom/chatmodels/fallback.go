@@ -0,0 +1,64 @@
+package chatmodels
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/aws/smithy-go"
+)
+
+var ModelUnavailableErr = errors.New("model is not available")
+
+// Generation records what a generation call actually did, for callers that
+// need more than the returned text. Attach one to the context with
+// WithGeneration before calling a Service method.
+type Generation struct {
+	// Model is the model that produced the answer, which differs from the
+	// requested model when a fallback was used.
+	Model ChatModel
+	// Usage adds up the tokens and cost of every call that answered.
+	Usage Usage
+	// StopReason is why the model that answered last stopped.
+	StopReason string
+}
+
+type generationKey struct{}
+
+// WithGeneration returns a context that reports into g.
+func WithGeneration(ctx context.Context, g *Generation) context.Context {
+	return context.WithValue(ctx, generationKey{}, g)
+}
+
+// GenerationFrom returns the Generation attached with WithGeneration, or nil.
+func GenerationFrom(ctx context.Context) *Generation {
+	g, _ := ctx.Value(generationKey{}).(*Generation)
+	return g
+}
+
+// unavailableBedrockErrors are the Bedrock error codes that say the model
+// itself can't serve the request, though another model may well succeed.
+var unavailableBedrockErrors = map[string]bool{
+	"ServiceQuotaExceededException": true,
+	"ResourceNotFoundException":     true,
+}
+
+// isRetryable reports whether err is worth retrying against another model:
+// anything transient for the same model, and models that are not available.
+func isRetryable(err error) bool {
+	if errors.Is(err, ModelUnavailableErr) || errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	if isTransient(ProviderBedrock, err) {
+		return true
+	}
+
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		return unavailableBedrockErrors[apiErr.ErrorCode()]
+	}
+
+	code, ok := statusCode(err)
+	return ok && code == http.StatusNotFound
+}
@@ -0,0 +1,203 @@
+package chatmodels
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/aws/smithy-go"
+	openai "github.com/openai/openai-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// noRetries makes every model give up after one attempt, so fallbacks are
+// tried straight away.
+func noRetries(t *testing.T) {
+	policy := DefaultRetryPolicy
+	DefaultRetryPolicy = RetryPolicy{MaxAttempts: 1}
+	t.Cleanup(func() { DefaultRetryPolicy = policy })
+}
+
+func withModel(id string) any {
+	return mock.MatchedBy(func(opts GenerateOptions) bool { return opts.Model == id })
+}
+
+func cloudflareError(code int) error {
+	req, _ := http.NewRequest(http.MethodPost, "https://api.cloudflare.com/client/v4/accounts/test/ai/v1/chat/completions", nil)
+	return &openai.Error{StatusCode: code, Request: req, Response: &http.Response{StatusCode: code}}
+}
+
+func TestBedrockThrottlingFallsBack(t *testing.T) {
+	noRetries(t)
+
+	sonnet, _ := GetChatModelConfig(CHAT_MODEL_SONNET)
+	novaPro, _ := GetChatModelConfig(CHAT_MODEL_NOVA_PRO)
+
+	mockBedrock := &mockBedrockAPI{}
+	mockBedrock.On("GenerateContent", mock.Anything, mock.Anything, withModel(sonnet.ProviderModelID)).
+		Return(nil, fmt.Errorf("bedrock converse error: %w", &smithy.GenericAPIError{Code: "ThrottlingException"})).Once()
+	mockBedrock.On("GenerateContent", mock.Anything, mock.Anything, withModel(novaPro.ProviderModelID)).
+		Return(&GenerateResponse{Content: "hello from nova"}, nil).Once()
+
+	generation := &Generation{}
+	c := Client{&Resources{BedrockAPI: mockBedrock}}
+	resp, err := c.TextGeneration(WithGeneration(context.Background(), generation), "steve", CHAT_MODEL_SONNET)
+	assert.NoError(t, err)
+	assert.Equal(t, "hello from nova", resp)
+	assert.Equal(t, CHAT_MODEL_NOVA_PRO, generation.Model)
+	mockBedrock.AssertExpectations(t)
+}
+
+func TestCloudflareOutageFallsBackAcrossProviders(t *testing.T) {
+	noRetries(t)
+
+	llama, _ := GetChatModelConfig(CHAT_MODEL_LLAMA)
+	gemma, _ := GetChatModelConfig(CHAT_MODEL_GEMMA)
+	sonnet, _ := GetChatModelConfig(CHAT_MODEL_SONNET)
+
+	mockCloudflare := &mockCloudflareAPI{}
+	mockCloudflare.On("GenerateContent", mock.Anything, mock.Anything, withModel(llama.ProviderModelID)).
+		Return(nil, fmt.Errorf("cloudflare chat error: %w", cloudflareError(http.StatusServiceUnavailable))).Once()
+	mockCloudflare.On("GenerateContent", mock.Anything, mock.Anything, withModel(gemma.ProviderModelID)).
+		Return(nil, fmt.Errorf("cloudflare chat error: %w", cloudflareError(http.StatusTooManyRequests))).Once()
+
+	mockBedrock := &mockBedrockAPI{}
+	mockBedrock.On("GenerateContent", mock.Anything, mock.Anything, withModel(sonnet.ProviderModelID)).
+		Return(&GenerateResponse{Content: "hello from sonnet"}, nil).Once()
+
+	generation := &Generation{}
+	c := Client{&Resources{BedrockAPI: mockBedrock, CloudflareAPI: mockCloudflare}}
+	resp, err := c.TextGeneration(WithGeneration(context.Background(), generation), "steve", CHAT_MODEL_LLAMA)
+	assert.NoError(t, err)
+	assert.Equal(t, "hello from sonnet", resp)
+	assert.Equal(t, CHAT_MODEL_SONNET, generation.Model)
+	mockCloudflare.AssertExpectations(t)
+	mockBedrock.AssertExpectations(t)
+}
+
+func TestUnconfiguredProviderFallsBack(t *testing.T) {
+	mockBedrock := &mockBedrockAPI{}
+	mockBedrock.On("GenerateContent", mock.Anything, mock.Anything, mock.Anything).
+		Return(&GenerateResponse{Content: "hello from sonnet"}, nil).Once()
+
+	generation := &Generation{}
+	c := Client{&Resources{BedrockAPI: mockBedrock}}
+	resp, err := c.TextGeneration(WithGeneration(context.Background(), generation), "steve", CHAT_MODEL_KIMI)
+	assert.NoError(t, err)
+	assert.Equal(t, "hello from sonnet", resp)
+	assert.Equal(t, CHAT_MODEL_SONNET, generation.Model)
+}
+
+func TestNonRetryableErrorDoesNotFallBack(t *testing.T) {
+	mockBedrock := &mockBedrockAPI{}
+	mockBedrock.On("GenerateContent", mock.Anything, mock.Anything, mock.Anything).
+		Return(nil, fmt.Errorf("bedrock converse error: %w", &smithy.GenericAPIError{Code: "ValidationException"})).Once()
+
+	c := Client{&Resources{BedrockAPI: mockBedrock}}
+	_, err := c.TextGeneration(context.Background(), "steve", CHAT_MODEL_SONNET)
+	assert.Error(t, err)
+	mockBedrock.AssertNumberOfCalls(t, "GenerateContent", 1)
+}
+
+func TestCancelledContextDoesNotFallBack(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	mockBedrock := &mockBedrockAPI{}
+	mockBedrock.On("GenerateContent", mock.Anything, mock.Anything, mock.Anything).
+		Return(nil, context.DeadlineExceeded).Once()
+
+	c := Client{&Resources{BedrockAPI: mockBedrock}}
+	_, err := c.TextGeneration(ctx, "steve", CHAT_MODEL_SONNET)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	mockBedrock.AssertNumberOfCalls(t, "GenerateContent", 1)
+}
+
+func TestAllFallbacksFailingReturnsLastError(t *testing.T) {
+	noRetries(t)
+
+	mockBedrock := &mockBedrockAPI{}
+	mockBedrock.On("GenerateContent", mock.Anything, mock.Anything, mock.Anything).
+		Return(nil, &smithy.GenericAPIError{Code: "ServiceUnavailableException"})
+
+	generation := &Generation{}
+	c := Client{&Resources{BedrockAPI: mockBedrock}}
+	_, err := c.TextGeneration(WithGeneration(context.Background(), generation), "steve", CHAT_MODEL_SONNET)
+
+	var apiErr smithy.APIError
+	assert.True(t, errors.As(err, &apiErr))
+	assert.Empty(t, generation.Model)
+	mockBedrock.AssertNumberOfCalls(t, "GenerateContent", 2)
+}
+
+func TestFallbackIsTracedAsSpanEvent(t *testing.T) {
+	noRetries(t)
+
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	mockBedrock := &mockBedrockAPI{}
+	mockBedrock.On("GenerateContent", mock.Anything, mock.Anything, mock.Anything).
+		Return(nil, &smithy.GenericAPIError{Code: "ThrottlingException"}).Once()
+	mockBedrock.On("GenerateContent", mock.Anything, mock.Anything, mock.Anything).
+		Return(&GenerateResponse{Content: "hello"}, nil).Once()
+
+	c := Client{&Resources{BedrockAPI: mockBedrock}}
+	_, err := c.TextGeneration(context.Background(), "steve", CHAT_MODEL_OPUS)
+	assert.NoError(t, err)
+
+	spans := recorder.Ended()
+	if !assert.Len(t, spans, 1) {
+		return
+	}
+	events := spans[0].Events()
+	if !assert.Len(t, events, 1) {
+		return
+	}
+	assert.Equal(t, "model-fallback", events[0].Name)
+
+	attrs := map[string]string{}
+	for _, kv := range events[0].Attributes {
+		attrs[string(kv.Key)] = kv.Value.AsString()
+	}
+	assert.Equal(t, "opus", attrs["from"])
+	assert.Equal(t, "sonnet", attrs["to"])
+	assert.Contains(t, attrs["error"], "ThrottlingException")
+}
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"throttled", &smithy.GenericAPIError{Code: "ThrottlingException"}, true},
+		{"model not ready", &smithy.GenericAPIError{Code: "ModelNotReadyException"}, true},
+		{"quota exceeded", &smithy.GenericAPIError{Code: "ServiceQuotaExceededException"}, true},
+		{"model not found", &smithy.GenericAPIError{Code: "ResourceNotFoundException"}, true},
+		{"validation", &smithy.GenericAPIError{Code: "ValidationException"}, false},
+		{"unavailable", fmt.Errorf("%w: gpt", ModelUnavailableErr), true},
+		{"timeout", fmt.Errorf("bedrock converse error: %w", context.DeadlineExceeded), true},
+		{"server error", cloudflareError(http.StatusBadGateway), true},
+		{"not found", cloudflareError(http.StatusNotFound), true},
+		{"bad request", cloudflareError(http.StatusBadRequest), false},
+		{"missing content", MissingContentError, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, isRetryable(tt.err))
+		})
+	}
+}
+
+func TestTransientBedrockErrorsFallBack(t *testing.T) {
+	for code := range transientBedrockErrors {
+		assert.True(t, isRetryable(&smithy.GenericAPIError{Code: code}), code)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -89,11 +90,12 @@ func newRegionalMantleClient(region string, creds aws.CredentialsProvider) opena
 		option.WithBaseURL(fmt.Sprintf("https://bedrock-mantle.%s.api.aws/openai/v1", region)),
 		option.WithHTTPClient(httpClient),
 		option.WithAPIKey("x"), // auth is via SigV4; SDK requires a non-empty value
+		option.WithMaxRetries(0),
 	)
 }
 
 // NewMantleApiClient builds one signed OpenAI client per distinct region required
-// by the mantle models registered in allModelConfigs.
+// by the mantle models in the loaded model registry.
 func NewMantleApiClient() *MantleApiClient {
 	cfg, err := config.LoadDefaultConfig(context.Background())
 	if err != nil {
@@ -101,39 +103,39 @@ func NewMantleApiClient() *MantleApiClient {
 	}
 
 	clients := make(map[string]openai.Client)
-	for _, mc := range allModelConfigs {
-		if mc.Provider == ProviderBedrockMantle && mc.MantleRegion != "" {
-			if _, exists := clients[mc.MantleRegion]; !exists {
-				clients[mc.MantleRegion] = newRegionalMantleClient(mc.MantleRegion, cfg.Credentials)
-			}
-		}
+	for _, region := range MantleRegions() {
+		clients[region] = newRegionalMantleClient(region, cfg.Credentials)
 	}
 
 	return &MantleApiClient{clients: clients}
 }
 
-func (api *MantleApiClient) GenerateContent(ctx context.Context, messages []Message, opts GenerateOptions) (*GenerateResponse, error) {
-	cl, ok := api.clients[opts.MantleRegion]
-	if !ok {
-		return nil, fmt.Errorf("mantle: no client configured for region %q", opts.MantleRegion)
-	}
-
+// responseParams lays the conversation out as an ordered list of user and
+// assistant input items. System messages are joined, in order, into the
+// request instructions.
+func responseParams(messages []Message, opts GenerateOptions) responses.ResponseNewParams {
 	params := responses.ResponseNewParams{
 		Model: opts.Model,
 	}
 
-	var userContent string
+	var instructions []string
+	var input responses.ResponseInputParam
 	for _, msg := range messages {
 		switch msg.Role {
 		case RoleSystem:
-			params.Instructions = openai.String(msg.Content)
+			instructions = append(instructions, msg.Content)
 		case RoleUser:
-			userContent = msg.Content
+			input = append(input, responses.ResponseInputItemParamOfMessage(msg.Content, responses.EasyInputMessageRoleUser))
+		case RoleAssistant:
+			input = append(input, responses.ResponseInputItemParamOfMessage(msg.Content, responses.EasyInputMessageRoleAssistant))
 		}
 	}
 
+	if len(instructions) > 0 {
+		params.Instructions = openai.String(strings.Join(instructions, "\n\n"))
+	}
 	params.Input = responses.ResponseNewParamsInputUnion{
-		OfString: openai.String(userContent),
+		OfInputItemList: input,
 	}
 
 	if opts.Temperature > 0 {
@@ -142,11 +144,79 @@ func (api *MantleApiClient) GenerateContent(ctx context.Context, messages []Mess
 	if opts.MaxTokens > 0 {
 		params.MaxOutputTokens = openai.Int(int64(opts.MaxTokens))
 	}
+	return params
+}
+
+// GenerateContent calls the Responses API with the conversation laid out by
+// responseParams.
+func (api *MantleApiClient) GenerateContent(ctx context.Context, messages []Message, opts GenerateOptions) (*GenerateResponse, error) {
+	cl, ok := api.clients[opts.MantleRegion]
+	if !ok {
+		return nil, fmt.Errorf("mantle: no client configured for region %q", opts.MantleRegion)
+	}
 
-	resp, err := cl.Responses.New(ctx, params)
+	resp, err := cl.Responses.New(ctx, responseParams(messages, opts))
 	if err != nil {
 		return nil, fmt.Errorf("mantle: responses API error: %w", err)
 	}
 
-	return &GenerateResponse{Content: resp.OutputText()}, nil
+	return &GenerateResponse{
+		Content:    resp.OutputText(),
+		Usage:      responsesUsage(resp.Usage),
+		StopReason: responsesStopReason(resp),
+	}, nil
+}
+
+func responsesUsage(usage responses.ResponseUsage) Usage {
+	return Usage{InputTokens: int(usage.InputTokens), OutputTokens: int(usage.OutputTokens)}
+}
+
+// responsesStopReason is the response's status, or why it is incomplete.
+func responsesStopReason(resp *responses.Response) string {
+	if resp.IncompleteDetails.Reason != "" {
+		return resp.IncompleteDetails.Reason
+	}
+	return string(resp.Status)
+}
+
+// StreamContent calls the streaming Responses API, handing each output text
+// delta to onDelta as it arrives.
+func (api *MantleApiClient) StreamContent(ctx context.Context, messages []Message, opts GenerateOptions, onDelta DeltaFunc) (*GenerateResponse, error) {
+	cl, ok := api.clients[opts.MantleRegion]
+	if !ok {
+		return nil, fmt.Errorf("mantle: no client configured for region %q", opts.MantleRegion)
+	}
+
+	stream := cl.Responses.NewStreaming(ctx, responseParams(messages, opts))
+	defer stream.Close()
+
+	var responseText strings.Builder
+	result := &GenerateResponse{}
+	for stream.Next() {
+		event := stream.Current()
+		switch event.Type {
+		case "response.completed":
+			completed := event.AsResponseCompleted().Response
+			result.Usage, result.StopReason = responsesUsage(completed.Usage), responsesStopReason(&completed)
+		case "response.incomplete":
+			incomplete := event.AsResponseIncomplete().Response
+			result.Usage, result.StopReason = responsesUsage(incomplete.Usage), responsesStopReason(&incomplete)
+		case "response.output_text.delta":
+			delta := event.AsResponseOutputTextDelta().Delta
+			responseText.WriteString(delta)
+			if err := onDelta(delta); err != nil {
+				return nil, err
+			}
+		case "error":
+			return nil, fmt.Errorf("mantle: responses stream error: %s", event.AsError().Message)
+		case "response.failed":
+			return nil, fmt.Errorf("mantle: responses stream failed: %s", event.AsResponseFailed().Response.Error.Message)
+		}
+	}
+	if err := stream.Err(); err != nil {
+		return nil, fmt.Errorf("mantle: responses API error: %w", err)
+	}
+
+	result.Content = responseText.String()
+	return result, nil
 }
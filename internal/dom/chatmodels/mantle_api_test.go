@@ -0,0 +1,147 @@
+package chatmodels
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	openai "github.com/openai/openai-go"
+	"github.com/openai/openai-go/option"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const mantleTestResponse = `{
+	"id": "resp_1",
+	"object": "response",
+	"created_at": 1700000000,
+	"model": "xai.grok-4.3",
+	"status": "completed",
+	"output": [{
+		"type": "message",
+		"id": "msg_1",
+		"role": "assistant",
+		"status": "completed",
+		"content": [{"type": "output_text", "text": "hello there", "annotations": []}]
+	}],
+	"usage": {
+		"input_tokens": 21,
+		"input_tokens_details": {"cached_tokens": 0},
+		"output_tokens": 3,
+		"output_tokens_details": {"reasoning_tokens": 0},
+		"total_tokens": 24
+	}
+}`
+
+func newTestMantleClient(t *testing.T, handler http.HandlerFunc) *MantleApiClient {
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	return &MantleApiClient{clients: map[string]openai.Client{
+		"us-west-2": openai.NewClient(
+			option.WithBaseURL(srv.URL),
+			option.WithAPIKey("x"),
+			option.WithMaxRetries(0),
+		),
+	}}
+}
+
+func TestMantleGenerateContentRequestBody(t *testing.T) {
+	tests := []struct {
+		name     string
+		messages []Message
+		opts     GenerateOptions
+		expected string
+	}{
+		{
+			name:     "single user prompt",
+			messages: []Message{{Role: RoleUser, Content: "hi"}},
+			expected: `{
+				"model": "xai.grok-4.3",
+				"input": [{"role": "user", "content": "hi"}]
+			}`,
+		},
+		{
+			name: "system prompt and multi turn conversation",
+			messages: []Message{
+				{Role: RoleSystem, Content: "you are a pirate"},
+				{Role: RoleUser, Content: "why is the sky blue"},
+				{Role: RoleAssistant, Content: "rayleigh scattering, arr"},
+				{Role: RoleUser, Content: "and why is that"},
+			},
+			expected: `{
+				"model": "xai.grok-4.3",
+				"instructions": "you are a pirate",
+				"input": [
+					{"role": "user", "content": "why is the sky blue"},
+					{"role": "assistant", "content": "rayleigh scattering, arr"},
+					{"role": "user", "content": "and why is that"}
+				]
+			}`,
+		},
+		{
+			name: "multiple system instructions are kept in order",
+			messages: []Message{
+				{Role: RoleSystem, Content: "you are a pirate"},
+				{Role: RoleSystem, Content: "answer in one sentence"},
+				{Role: RoleUser, Content: "hi"},
+			},
+			expected: `{
+				"model": "xai.grok-4.3",
+				"instructions": "you are a pirate\n\nanswer in one sentence",
+				"input": [{"role": "user", "content": "hi"}]
+			}`,
+		},
+		{
+			name: "few shot examples with generation options",
+			messages: []Message{
+				{Role: RoleUser, Content: "cat"},
+				{Role: RoleAssistant, Content: "meow"},
+				{Role: RoleUser, Content: "dog"},
+			},
+			opts: GenerateOptions{Temperature: 0.5, MaxTokens: 64},
+			expected: `{
+				"model": "xai.grok-4.3",
+				"temperature": 0.5,
+				"max_output_tokens": 64,
+				"input": [
+					{"role": "user", "content": "cat"},
+					{"role": "assistant", "content": "meow"},
+					{"role": "user", "content": "dog"}
+				]
+			}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body []byte
+			client := newTestMantleClient(t, func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/responses", r.URL.Path)
+				body, _ = io.ReadAll(r.Body)
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(mantleTestResponse))
+			})
+
+			opts := tt.opts
+			opts.Model = "xai.grok-4.3"
+			opts.MantleRegion = "us-west-2"
+
+			resp, err := client.GenerateContent(context.Background(), tt.messages, opts)
+			require.NoError(t, err)
+			assert.Equal(t, "hello there", resp.Content)
+			assert.Equal(t, Usage{InputTokens: 21, OutputTokens: 3}, resp.Usage)
+			assert.Equal(t, "completed", resp.StopReason)
+			assert.JSONEq(t, tt.expected, string(body))
+		})
+	}
+}
+
+func TestMantleGenerateContentUnknownRegion(t *testing.T) {
+	client := &MantleApiClient{clients: map[string]openai.Client{}}
+
+	_, err := client.GenerateContent(context.Background(), []Message{{Role: RoleUser, Content: "hi"}}, GenerateOptions{MantleRegion: "eu-west-1"})
+	assert.Error(t, err)
+}
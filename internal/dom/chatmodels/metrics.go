@@ -0,0 +1,44 @@
+package chatmodels
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+var meter = otel.Meter("chatmodels")
+
+var (
+	tokenCounter, _ = meter.Int64Counter(
+		"chatmodels.tokens",
+		metric.WithDescription("Tokens sent to and generated by chat models"),
+		metric.WithUnit("{token}"),
+	)
+	costCounter, _ = meter.Float64Counter(
+		"chatmodels.cost",
+		metric.WithDescription("Cost of chat model calls, from the model registry's pricing"),
+		metric.WithUnit("USD"),
+	)
+	callDuration, _ = meter.Float64Histogram(
+		"chatmodels.call.duration",
+		metric.WithDescription("Duration of each call to a model provider, retries included separately"),
+		metric.WithUnit("s"),
+	)
+)
+
+// recordCall records how long a provider call to cfg's model took and
+// whether it failed. Error rates are calls with outcome error over all calls.
+func recordCall(ctx context.Context, cfg ModelConfig, start time.Time, err error) {
+	outcome := "success"
+	if err != nil {
+		outcome = "error"
+	}
+	callDuration.Record(ctx, time.Since(start).Seconds(), metric.WithAttributes(
+		attribute.String("model", cfg.ChatModel.String()),
+		attribute.String("provider", string(cfg.Provider)),
+		attribute.String("outcome", outcome),
+	))
+}
@@ -21,6 +21,25 @@ func (client *MockClient) TextGenerationWithSystem(ctx context.Context, system s
 	return args.String(0), args.Error(1)
 }
 
+func (client *MockClient) ChatGeneration(ctx context.Context, messages []Message, model ChatModel) (string, error) {
+	args := client.Called(ctx, messages, model)
+	return args.String(0), args.Error(1)
+}
+
+// StreamGeneration hands each string in the mock's optional third return value
+// to onDelta before returning the answer and error.
+func (client *MockClient) StreamGeneration(ctx context.Context, messages []Message, model ChatModel, onDelta DeltaFunc) (string, error) {
+	args := client.Called(ctx, messages, model)
+	if len(args) > 2 {
+		for _, delta := range args.Get(2).([]string) {
+			if err := onDelta(delta); err != nil {
+				return "", err
+			}
+		}
+	}
+	return args.String(0), args.Error(1)
+}
+
 func (client *MockClient) GenerateImage(ctx context.Context, prompt string, model ImageModel) (res []byte, err error) {
 	args := client.Called(ctx, prompt, model)
 	if args.Get(0) != nil {
@@ -29,7 +48,7 @@ func (client *MockClient) GenerateImage(ctx context.Context, prompt string, mode
 	return res, args.Error(1)
 }
 
-func (client *MockClient) Translate(ctx context.Context, prompt string, sourceLang string, targetLang string, model ChatModel) (string, error) {
-	args := client.Called(ctx, prompt, sourceLang, targetLang, model)
-	return args.String(0), args.Error(1)
+func (client *MockClient) Translate(ctx context.Context, prompt string, sourceLang string, targetLang string, model ChatModel, opts TranslateOptions) (Translation, error) {
+	args := client.Called(ctx, prompt, sourceLang, targetLang, model, opts)
+	return args.Get(0).(Translation), args.Error(1)
 }
@@ -69,9 +69,51 @@ type ModelConfig struct {
 	// Alexa voice command aliases (what users say to select this model).
 	Aliases []string
 
+	// Fallbacks are tried in order when a call to this model fails with a
+	// retryable error. Only the requested model's fallbacks are used.
+	Fallbacks []ChatModel
+
+	// Retry overrides DefaultRetryPolicy for calls to this model.
+	Retry *RetryPolicy
+
+	// Generation defaults sent with every call; zero leaves the provider's own.
+	Temperature float64
+	MaxTokens   int
+
+	// Pricing is what the provider charges for the model's tokens.
+	Pricing Pricing
+
 	ErrorMessage string
 }
 
+// Pricing is a model's price in US dollars per million tokens. Zero prices
+// leave the model's usage counted but not costed.
+type Pricing struct {
+	InputPerMillion  float64
+	OutputPerMillion float64
+}
+
+// Cost is what usage costs at these prices.
+func (p Pricing) Cost(usage Usage) float64 {
+	return (float64(usage.InputTokens)*p.InputPerMillion + float64(usage.OutputTokens)*p.OutputPerMillion) / 1_000_000
+}
+
+func (cfg ModelConfig) generateOptions() GenerateOptions {
+	return GenerateOptions{
+		Model:        cfg.ProviderModelID,
+		Temperature:  cfg.Temperature,
+		MaxTokens:    cfg.MaxTokens,
+		MantleRegion: cfg.MantleRegion,
+	}
+}
+
+func (cfg ModelConfig) retryPolicy() RetryPolicy {
+	if cfg.Retry != nil {
+		return *cfg.Retry
+	}
+	return DefaultRetryPolicy
+}
+
 // ModelRegistry holds all model configurations.
 type ModelRegistry struct {
 	configs       []ModelConfig
@@ -84,117 +126,9 @@ type ModelRegistry struct {
 
 var registry = &ModelRegistry{}
 
-// allModelConfigs is the single source of truth for all models.
-// All models use AWS Bedrock cross-region inference profile IDs.
-var allModelConfigs = []ModelConfig{
-	// Claude models
-	{
-		ChatModel:       CHAT_MODEL_SONNET,
-		Type:            ModelTypeChat,
-		Provider:        ProviderBedrock,
-		ProviderModelID: "us.anthropic.claude-sonnet-4-6",
-		Aliases:         []string{string(CHAT_MODEL_SONNET)},
-		ErrorMessage:    "Sonnet model is not available - Bedrock not configured",
-	},
-	{
-		ChatModel:       CHAT_MODEL_OPUS,
-		Type:            ModelTypeChat,
-		Provider:        ProviderBedrock,
-		ProviderModelID: "us.anthropic.claude-opus-4-8",
-		Aliases:         []string{string(CHAT_MODEL_OPUS)},
-		ErrorMessage:    "Opus model is not available - Bedrock not configured",
-	},
-	{
-		ChatModel:       CHAT_MODEL_FABLE,
-		Type:            ModelTypeChat,
-		Provider:        ProviderBedrock,
-		ProviderModelID: "us.anthropic.claude-fable-5",
-		Aliases:         []string{string(CHAT_MODEL_FABLE)},
-		ErrorMessage:    "Fable model is not available - Bedrock not configured",
-	},
-
-	// Amazon Nova
-	{
-		ChatModel:       CHAT_MODEL_NOVA_LITE,
-		Type:            ModelTypeChat,
-		Provider:        ProviderBedrock,
-		ProviderModelID: "us.amazon.nova-lite-v1:0",
-		Aliases:         []string{string(CHAT_MODEL_NOVA_LITE)},
-		ErrorMessage:    "Nova Lite model is not available - Bedrock not configured",
-	},
-	{
-		ChatModel:       CHAT_MODEL_NOVA_PRO,
-		Type:            ModelTypeChat,
-		Provider:        ProviderBedrock,
-		ProviderModelID: "us.amazon.nova-pro-v1:0",
-		Aliases:         []string{string(CHAT_MODEL_NOVA_PRO)},
-		ErrorMessage:    "Nova Pro model is not available - Bedrock not configured",
-	},
-
-	// Translation routed through Sonnet with a system prompt.
-	{
-		ChatModel:       CHAT_MODEL_TRANSLATIONS,
-		Type:            ModelTypeChat,
-		Provider:        ProviderBedrock,
-		ProviderModelID: "us.anthropic.claude-sonnet-4-6",
-		Aliases:         []string{string(CHAT_MODEL_TRANSLATIONS)},
-		ErrorMessage:    "Translation model is not available - Bedrock not configured",
-	},
-
-	// Bedrock Mantle models (OpenAI-compatible endpoint for third-party providers)
-	{
-		ChatModel:       CHAT_MODEL_GROK,
-		Type:            ModelTypeChat,
-		Provider:        ProviderBedrockMantle,
-		ProviderModelID: "xai.grok-4.3",
-		MantleRegion:    "us-west-2",
-		Aliases:         []string{string(CHAT_MODEL_GROK)},
-		ErrorMessage:    "Grok model is not available - Bedrock not configured",
-	},
-	{
-		ChatModel:       CHAT_MODEL_GPT,
-		Type:            ModelTypeChat,
-		Provider:        ProviderBedrockMantle,
-		ProviderModelID: "openai.gpt-5.5",
-		MantleRegion:    "us-east-1",
-		Aliases:         []string{string(CHAT_MODEL_GPT)},
-		ErrorMessage:    "GPT model is not available - Bedrock not configured",
-	},
-
-	// Cloudflare Workers AI models
-	{
-		ChatModel:       CHAT_MODEL_LLAMA,
-		Type:            ModelTypeChat,
-		Provider:        ProviderCloudflare,
-		ProviderModelID: "@cf/meta/llama-3.3-70b-instruct-fp8-fast",
-		Aliases:         []string{string(CHAT_MODEL_LLAMA)},
-		ErrorMessage:    "Llama model is not available - Cloudflare not configured",
-	},
-	{
-		ChatModel:       CHAT_MODEL_GEMMA,
-		Type:            ModelTypeChat,
-		Provider:        ProviderCloudflare,
-		ProviderModelID: "@cf/google/gemma-4-26b-a4b-it",
-		Aliases:         []string{string(CHAT_MODEL_GEMMA)},
-		ErrorMessage:    "Gemma model is not available - Cloudflare not configured",
-	},
-	{
-		ChatModel:       CHAT_MODEL_KIMI,
-		Type:            ModelTypeChat,
-		Provider:        ProviderCloudflare,
-		ProviderModelID: "@cf/moonshotai/kimi-k2.7-code",
-		Aliases:         []string{string(CHAT_MODEL_KIMI)},
-		ErrorMessage:    "Kimi model is not available - Cloudflare not configured",
-	},
-	{
-		ImageModel:      IMAGE_MODEL_FLUX,
-		Type:            ModelTypeImage,
-		Provider:        ProviderCloudflare,
-		ProviderModelID: "@cf/black-forest-labs/flux-1-schnell",
-		Aliases:         []string{string(IMAGE_MODEL_FLUX)},
-		ErrorMessage:    "Flux model is not available - Cloudflare not configured",
-	},
-}
+// allModelConfigs is the single source of truth for all models. It starts out
+// as the embedded models.yaml and can be replaced through UseModelConfigs.
+var allModelConfigs = mustLoadModelConfigs(defaultModelRegistry)
 
 // RegisterAvailableClients initialises the model registry.
 func RegisterAvailableClients(cloudflareAvailable bool) {
@@ -267,6 +201,16 @@ func GetImageModelByAlias(alias string) (ModelConfig, bool) {
 	return cfg, ok
 }
 
+// GetModelAliases returns the aliases of every model in the registry, whether
+// or not its provider is configured.
+func GetModelAliases() []string {
+	var aliases []string
+	for _, cfg := range allModelConfigs {
+		aliases = append(aliases, cfg.Aliases...)
+	}
+	return aliases
+}
+
 // GetAvailableChatModels returns all available chat model aliases.
 func GetAvailableChatModels() []string {
 	var models []string
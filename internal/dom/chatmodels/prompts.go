@@ -3,45 +3,94 @@ package chatmodels
 import (
 	"context"
 	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
-// generateContent routes to the appropriate backend based on the model's provider.
+var tracer = otel.Tracer("chatmodels")
+
+// generateContent calls the model and, when it fails with a retryable error,
+// each of its configured fallbacks in turn. The model that answered is
+// reported through the context's Generation.
 func (client *Client) generateContent(ctx context.Context, messages []Message, model ChatModel) (string, error) {
+	ctx, span := tracer.Start(ctx, "generateContent")
+	defer span.End()
+	span.SetAttributes(attribute.String("model", model.String()))
+
 	cfg, ok := GetChatModelConfig(model)
 	if !ok {
 		return "", fmt.Errorf("model %s is not configured", model)
 	}
-	opts := GenerateOptions{Model: cfg.ProviderModelID, MantleRegion: cfg.MantleRegion}
 
+	used := model
+	content, err := client.callModel(ctx, messages, cfg)
+	for _, fallback := range cfg.Fallbacks {
+		if err == nil || !isRetryable(err) || ctx.Err() != nil {
+			break
+		}
+		fallbackCfg, ok := GetChatModelConfig(fallback)
+		if !ok {
+			continue
+		}
+
+		span.AddEvent("model-fallback", trace.WithAttributes(
+			attribute.String("from", used.String()),
+			attribute.String("to", fallback.String()),
+			attribute.String("error", err.Error()),
+		))
+		used = fallback
+		content, err = client.callModel(ctx, messages, fallbackCfg)
+	}
+	if err != nil {
+		span.RecordError(err)
+		return "", err
+	}
+
+	span.SetAttributes(attribute.String("model-used", used.String()))
+	if g := GenerationFrom(ctx); g != nil {
+		g.Model = used
+	}
+	return content, nil
+}
+
+// callModel routes to the appropriate backend based on the model's provider,
+// retrying transient failures according to the model's retry policy.
+func (client *Client) callModel(ctx context.Context, messages []Message, cfg ModelConfig) (string, error) {
+	opts := cfg.generateOptions()
+
+	var generate func(context.Context, []Message, GenerateOptions) (*GenerateResponse, error)
 	switch cfg.Provider {
 	case ProviderBedrockMantle:
 		if client.MantleAPI == nil {
-			return "", fmt.Errorf("model %s is not available: Mantle client not configured", model)
-		}
-		resp, err := client.MantleAPI.GenerateContent(ctx, messages, opts)
-		if err != nil {
-			return "", err
+			return "", fmt.Errorf("%w: %s, Mantle client not configured", ModelUnavailableErr, cfg.ChatModel)
 		}
-		return resp.Content, nil
+		generate = client.MantleAPI.GenerateContent
 	case ProviderCloudflare:
 		if client.CloudflareAPI == nil {
-			return "", fmt.Errorf("model %s is not available: Cloudflare client not configured", model)
-		}
-		resp, err := client.CloudflareAPI.GenerateContent(ctx, messages, opts)
-		if err != nil {
-			return "", err
+			return "", fmt.Errorf("%w: %s, Cloudflare client not configured", ModelUnavailableErr, cfg.ChatModel)
 		}
-		return resp.Content, nil
+		generate = client.CloudflareAPI.GenerateContent
 	default:
 		if client.BedrockAPI == nil {
-			return "", fmt.Errorf("model %s is not available: Bedrock client not configured", model)
+			return "", fmt.Errorf("%w: %s, Bedrock client not configured", ModelUnavailableErr, cfg.ChatModel)
 		}
-		resp, err := client.BedrockAPI.GenerateContent(ctx, messages, opts)
-		if err != nil {
-			return "", err
-		}
-		return resp.Content, nil
+		generate = client.BedrockAPI.GenerateContent
+	}
+
+	resp, err := withRetry(ctx, cfg.retryPolicy(), cfg.Provider, func() (*GenerateResponse, error) {
+		start := time.Now()
+		resp, err := generate(ctx, messages, opts)
+		recordCall(ctx, cfg, start, err)
+		return resp, err
+	})
+	if err != nil {
+		return "", err
 	}
+	recordUsage(ctx, cfg, resp)
+	return resp.Content, nil
 }
 
 func (client *Client) TextGeneration(ctx context.Context, prompt string, model ChatModel) (string, error) {
@@ -57,6 +106,70 @@ func (client *Client) TextGenerationWithSystem(ctx context.Context, system strin
 	}, model)
 }
 
+// ChatGeneration sends a full conversation, optionally led by a system message,
+// so the model can answer the final user turn with the earlier turns as context.
+func (client *Client) ChatGeneration(ctx context.Context, messages []Message, model ChatModel) (string, error) {
+	return client.generateContent(ctx, messages, model)
+}
+
+// StreamGeneration streams the model's answer to the conversation, handing
+// each piece of text to onDelta as it arrives, and returns the full answer.
+// When the stream fails before any text was delivered the answer is generated
+// without streaming instead, with the usual retries and fallbacks. Once text
+// has been delivered a failure is returned as is.
+func (client *Client) StreamGeneration(ctx context.Context, messages []Message, model ChatModel, onDelta DeltaFunc) (string, error) {
+	ctx, span := tracer.Start(ctx, "StreamGeneration")
+	defer span.End()
+	span.SetAttributes(attribute.String("model", model.String()))
+
+	cfg, ok := GetChatModelConfig(model)
+	if !ok {
+		return "", fmt.Errorf("model %s is not configured", model)
+	}
+
+	var stream func(context.Context, []Message, GenerateOptions, DeltaFunc) (*GenerateResponse, error)
+	switch cfg.Provider {
+	case ProviderBedrockMantle:
+		if client.MantleAPI != nil {
+			stream = client.MantleAPI.StreamContent
+		}
+	case ProviderCloudflare:
+		if client.CloudflareAPI != nil {
+			stream = client.CloudflareAPI.StreamContent
+		}
+	default:
+		if client.BedrockAPI != nil {
+			stream = client.BedrockAPI.StreamContent
+		}
+	}
+	if stream == nil {
+		return client.generateContent(ctx, messages, model)
+	}
+
+	deltas := 0
+	start := time.Now()
+	resp, err := stream(ctx, messages, cfg.generateOptions(), func(delta string) error {
+		deltas++
+		return onDelta(delta)
+	})
+	recordCall(ctx, cfg, start, err)
+	span.SetAttributes(attribute.Int("deltas", deltas))
+	if err != nil && deltas == 0 && ctx.Err() == nil {
+		span.AddEvent("stream-failed", trace.WithAttributes(attribute.String("error", err.Error())))
+		return client.generateContent(ctx, messages, model)
+	}
+	if err != nil {
+		span.RecordError(err)
+		return "", err
+	}
+
+	recordUsage(ctx, cfg, resp)
+	if g := GenerationFrom(ctx); g != nil {
+		g.Model = model
+	}
+	return resp.Content, nil
+}
+
 func (client *Client) GenerateImage(ctx context.Context, prompt string, model ImageModel) ([]byte, error) {
 	cfg, ok := GetImageModelConfig(model)
 	if !ok {
@@ -68,31 +181,15 @@ func (client *Client) GenerateImage(ctx context.Context, prompt string, model Im
 		if client.CloudflareAPI == nil {
 			return nil, fmt.Errorf("image model %s is not available: Cloudflare client not configured", model)
 		}
-		return client.CloudflareAPI.GenerateImage(ctx, prompt, cfg.ProviderModelID)
+		return withRetry(ctx, cfg.retryPolicy(), cfg.Provider, func() ([]byte, error) {
+			return client.CloudflareAPI.GenerateImage(ctx, prompt, cfg.ProviderModelID)
+		})
 	default:
 		if client.BedrockAPI == nil {
 			return nil, fmt.Errorf("image model %s is not available: Bedrock client not configured", model)
 		}
-		return client.BedrockAPI.GenerateImage(ctx, prompt, cfg.ProviderModelID)
-	}
-}
-
-func (client *Client) Translate(
-	ctx context.Context,
-	prompt string,
-	sourceLang string,
-	targetLang string,
-	_ ChatModel,
-) (string, error) {
-	if sourceLang == "" {
-		sourceLang = "en"
-	}
-	if targetLang == "" {
-		targetLang = "jp"
+		return withRetry(ctx, cfg.retryPolicy(), cfg.Provider, func() ([]byte, error) {
+			return client.BedrockAPI.GenerateImage(ctx, prompt, cfg.ProviderModelID)
+		})
 	}
-	systemPrompt := fmt.Sprintf(
-		"You are a translator. Translate the following text from %s to %s. Output only the translated text, nothing else.",
-		sourceLang, targetLang,
-	)
-	return client.TextGenerationWithSystem(ctx, systemPrompt, prompt, CHAT_MODEL_SONNET)
 }
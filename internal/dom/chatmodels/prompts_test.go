@@ -47,3 +47,21 @@ func TestTextGenerationNilBedrock(t *testing.T) {
 	_, err := c.TextGeneration(context.Background(), "steve", CHAT_MODEL_SONNET)
 	assert.Error(t, err)
 }
+
+func TestChatGenerationSendsTurnsInOrder(t *testing.T) {
+	messages := []Message{
+		{Role: RoleUser, Content: "why is the sky blue"},
+		{Role: RoleAssistant, Content: "rayleigh scattering"},
+		{Role: RoleUser, Content: "and why is that"},
+	}
+
+	mockBedrock := &mockBedrockAPI{}
+	mockBedrock.On("GenerateContent", mock.Anything, messages, mock.Anything).
+		Return(&GenerateResponse{Content: "shorter wavelengths"}, nil)
+
+	c := Client{&Resources{BedrockAPI: mockBedrock}}
+	resp, err := c.ChatGeneration(context.Background(), messages, CHAT_MODEL_SONNET)
+	assert.NoError(t, err)
+	assert.Equal(t, "shorter wavelengths", resp)
+	mockBedrock.AssertExpectations(t)
+}
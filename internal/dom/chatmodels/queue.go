@@ -1,6 +1,14 @@
 package chatmodels
 
+import "time"
+
+// LastResponse is a generated answer pushed onto the responses queue. A
+// Partial response holds only the first complete sentences of an answer that
+// is still being generated; the full answer follows with the same RequestID.
 type LastResponse struct {
+	RequestID      string   `json:"request_id"`
+	UserID         string   `json:"user_id"`
+	SessionID      string   `json:"session_id"`
 	Prompt         string   `json:"prompt"`
 	Response       string   `json:"response"`
 	TimeDiff       string   `json:"time_diff"`
@@ -9,14 +17,37 @@ type LastResponse struct {
 	Error          string   `json:"error_message"`
 	SystemPrompt   string   `json:"system_prompt"`
 	TraceID        string   `json:"trace_id"`
+	TargetLanguage string   `json:"target_language,omitempty"`
+	Romanization   string   `json:"romanization,omitempty"`
+	// RequestedAt is when the request was pushed onto the requests queue.
+	RequestedAt time.Time `json:"requested_at,omitzero"`
+	// Usage is what generating the response used, when it is known.
+	Usage   *Usage `json:"usage,omitempty"`
+	Partial bool   `json:"partial,omitempty"`
 }
 
+// Request is a prompt pushed onto the requests queue. RequestID correlates it
+// with the LastResponse produced for it, and History holds the earlier turns
+// of the user's conversation, oldest first.
 type Request struct {
-	SystemPrompt   string      `json:"system_prompt"`
-	Prompt         string      `json:"prompt"`
-	TargetLanguage string      `json:"target_language,omitempty"`
-	SourceLanguage string      `json:"source_language,omitempty"`
-	Model          ChatModel   `json:"model"`
-	ImageModel     *ImageModel `json:"image_model"`
-	TraceID        string      `json:"trace_id"`
+	RequestID      string    `json:"request_id"`
+	UserID         string    `json:"user_id"`
+	SessionID      string    `json:"session_id"`
+	SystemPrompt   string    `json:"system_prompt"`
+	Prompt         string    `json:"prompt"`
+	History        []Message `json:"history,omitempty"`
+	TargetLanguage string    `json:"target_language,omitempty"`
+	SourceLanguage string    `json:"source_language,omitempty"`
+	// TranslationModel translates translation requests, which have the
+	// Model CHAT_MODEL_TRANSLATIONS.
+	TranslationModel ChatModel         `json:"translation_model,omitempty"`
+	Glossary         map[string]string `json:"glossary,omitempty"`
+	Romanize         bool              `json:"romanize,omitempty"`
+	Model            ChatModel         `json:"model"`
+	ImageModel       *ImageModel       `json:"image_model"`
+	TraceID          string            `json:"trace_id"`
+	// SpanID is the span that pushed the request, which the worker's spans
+	// are parented to so they continue the trace TraceID.
+	SpanID      string    `json:"span_id,omitempty"`
+	RequestedAt time.Time `json:"requested_at,omitzero"`
 }
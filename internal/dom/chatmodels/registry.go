@@ -0,0 +1,213 @@
+package chatmodels
+
+import (
+	"bytes"
+	_ "embed"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed models.yaml
+var defaultModelRegistry []byte
+
+// registryFile is the schema of a model registry file. JSON files are read
+// with the same keys.
+type registryFile struct {
+	Models []registryEntry `yaml:"models"`
+}
+
+type registryEntry struct {
+	Name            string           `yaml:"name"`
+	Type            ModelType        `yaml:"type"`
+	Provider        Provider         `yaml:"provider"`
+	ProviderModelID string           `yaml:"provider_model_id"`
+	MantleRegion    string           `yaml:"mantle_region"`
+	Aliases         []string         `yaml:"aliases"`
+	Fallbacks       []string         `yaml:"fallbacks"`
+	ErrorMessage    string           `yaml:"error_message"`
+	Defaults        registryDefaults `yaml:"defaults"`
+	Retry           *registryRetry   `yaml:"retry"`
+	Pricing         registryPricing  `yaml:"pricing"`
+}
+
+type registryDefaults struct {
+	Temperature float64 `yaml:"temperature"`
+	MaxTokens   int     `yaml:"max_tokens"`
+}
+
+type registryPricing struct {
+	InputPerMillion  float64 `yaml:"input_per_million"`
+	OutputPerMillion float64 `yaml:"output_per_million"`
+}
+
+type registryRetry struct {
+	MaxAttempts int           `yaml:"max_attempts"`
+	BaseDelay   time.Duration `yaml:"base_delay"`
+	MaxDelay    time.Duration `yaml:"max_delay"`
+}
+
+// LoadModelConfigs parses and validates a model registry file, in YAML or
+// JSON. Every problem found is reported, not just the first.
+func LoadModelConfigs(data []byte) ([]ModelConfig, error) {
+	var file registryFile
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&file); err != nil {
+		return nil, fmt.Errorf("model registry: %w", err)
+	}
+	if len(file.Models) == 0 {
+		return nil, errors.New("model registry: no models defined")
+	}
+
+	var errs []error
+	names := make(map[string]ModelType)
+	aliases := make(map[string]string)
+	for _, entry := range file.Models {
+		if entry.Name == "" {
+			errs = append(errs, errors.New("model registry: model without a name"))
+			continue
+		}
+		if _, exists := names[entry.Name]; exists {
+			errs = append(errs, fmt.Errorf("model %q: defined more than once", entry.Name))
+		}
+		names[entry.Name] = entry.Type
+
+		for _, alias := range entry.Aliases {
+			if owner, exists := aliases[alias]; exists {
+				errs = append(errs, fmt.Errorf("model %q: alias %q is already used by model %q", entry.Name, alias, owner))
+				continue
+			}
+			aliases[alias] = entry.Name
+		}
+		errs = append(errs, entry.validate()...)
+	}
+
+	configs := make([]ModelConfig, 0, len(file.Models))
+	for _, entry := range file.Models {
+		for _, fallback := range entry.Fallbacks {
+			switch {
+			case fallback == entry.Name:
+				errs = append(errs, fmt.Errorf("model %q: falls back to itself", entry.Name))
+			case names[fallback] != ModelTypeChat:
+				errs = append(errs, fmt.Errorf("model %q: fallback %q is not a configured chat model", entry.Name, fallback))
+			}
+		}
+		configs = append(configs, entry.config())
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return configs, nil
+}
+
+func (entry registryEntry) validate() []error {
+	var errs []error
+	invalid := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf("model %q: "+format, append([]any{entry.Name}, args...)...))
+	}
+
+	if entry.Type != ModelTypeChat && entry.Type != ModelTypeImage {
+		invalid("unknown type %q", entry.Type)
+	}
+	switch entry.Provider {
+	case ProviderBedrock, ProviderCloudflare:
+		if entry.MantleRegion != "" {
+			invalid("mantle_region is only used by %s models", ProviderBedrockMantle)
+		}
+	case ProviderBedrockMantle:
+		if entry.MantleRegion == "" {
+			invalid("mantle_region is required for %s models", ProviderBedrockMantle)
+		}
+		if entry.Type == ModelTypeImage {
+			invalid("%s does not serve image models", ProviderBedrockMantle)
+		}
+	default:
+		invalid("unknown provider %q", entry.Provider)
+	}
+	if entry.ProviderModelID == "" {
+		invalid("provider_model_id is required")
+	}
+	if len(entry.Aliases) == 0 || slices.Contains(entry.Aliases, "") {
+		invalid("at least one non-empty alias is required")
+	}
+	if entry.ErrorMessage == "" {
+		invalid("error_message is required")
+	}
+	if entry.Type == ModelTypeImage && len(entry.Fallbacks) > 0 {
+		invalid("image models cannot have fallbacks")
+	}
+	if entry.Defaults.Temperature < 0 || entry.Defaults.MaxTokens < 0 {
+		invalid("defaults cannot be negative")
+	}
+	if entry.Pricing.InputPerMillion < 0 || entry.Pricing.OutputPerMillion < 0 {
+		invalid("pricing cannot be negative")
+	}
+	if entry.Retry != nil && (entry.Retry.MaxAttempts < 1 || entry.Retry.BaseDelay < 0 || entry.Retry.MaxDelay < entry.Retry.BaseDelay) {
+		invalid("retry needs max_attempts of at least 1 and a max_delay no shorter than base_delay")
+	}
+	return errs
+}
+
+func (entry registryEntry) config() ModelConfig {
+	cfg := ModelConfig{
+		Type:            entry.Type,
+		Provider:        entry.Provider,
+		ProviderModelID: entry.ProviderModelID,
+		MantleRegion:    entry.MantleRegion,
+		Aliases:         entry.Aliases,
+		Temperature:     entry.Defaults.Temperature,
+		MaxTokens:       entry.Defaults.MaxTokens,
+		Pricing: Pricing{
+			InputPerMillion:  entry.Pricing.InputPerMillion,
+			OutputPerMillion: entry.Pricing.OutputPerMillion,
+		},
+		ErrorMessage: entry.ErrorMessage,
+	}
+	if entry.Type == ModelTypeImage {
+		cfg.ImageModel = ImageModel(entry.Name)
+	} else {
+		cfg.ChatModel = ChatModel(entry.Name)
+	}
+	for _, fallback := range entry.Fallbacks {
+		cfg.Fallbacks = append(cfg.Fallbacks, ChatModel(fallback))
+	}
+	if entry.Retry != nil {
+		cfg.Retry = &RetryPolicy{
+			MaxAttempts: entry.Retry.MaxAttempts,
+			BaseDelay:   entry.Retry.BaseDelay,
+			MaxDelay:    entry.Retry.MaxDelay,
+		}
+	}
+	return cfg
+}
+
+// UseModelConfigs replaces the model registry, e.g. with configs loaded from
+// an override file. It must be called before the provider clients are built
+// and RegisterAvailableClients runs.
+func UseModelConfigs(configs []ModelConfig) {
+	allModelConfigs = configs
+}
+
+// MantleRegions returns the distinct regions of the registry's Mantle models.
+func MantleRegions() []string {
+	var regions []string
+	for _, cfg := range allModelConfigs {
+		if cfg.Provider == ProviderBedrockMantle && !slices.Contains(regions, cfg.MantleRegion) {
+			regions = append(regions, cfg.MantleRegion)
+		}
+	}
+	return regions
+}
+
+func mustLoadModelConfigs(data []byte) []ModelConfig {
+	configs, err := LoadModelConfigs(data)
+	if err != nil {
+		panic(fmt.Sprintf("invalid embedded model registry: %v", err))
+	}
+	return configs
+}
@@ -0,0 +1,205 @@
+package chatmodels
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// useTestRegistry swaps the registry for the test's duration.
+func useTestRegistry(t *testing.T, configs []ModelConfig) {
+	previous := allModelConfigs
+	UseModelConfigs(configs)
+	t.Cleanup(func() { UseModelConfigs(previous) })
+}
+
+func TestEmbeddedRegistryCoversEveryModel(t *testing.T) {
+	for _, model := range []ChatModel{
+		CHAT_MODEL_SONNET, CHAT_MODEL_OPUS, CHAT_MODEL_FABLE, CHAT_MODEL_TRANSLATIONS,
+		CHAT_MODEL_NOVA_LITE, CHAT_MODEL_NOVA_PRO, CHAT_MODEL_GROK, CHAT_MODEL_GPT,
+		CHAT_MODEL_LLAMA, CHAT_MODEL_GEMMA, CHAT_MODEL_KIMI,
+	} {
+		_, ok := GetChatModelConfig(model)
+		assert.True(t, ok, "chat model %s", model)
+	}
+	_, ok := GetImageModelConfig(IMAGE_MODEL_FLUX)
+	assert.True(t, ok)
+
+	grok, _ := GetChatModelConfig(CHAT_MODEL_GROK)
+	assert.Equal(t, ProviderBedrockMantle, grok.Provider)
+	assert.Equal(t, "us-west-2", grok.MantleRegion)
+	assert.Equal(t, []ChatModel{CHAT_MODEL_GPT, CHAT_MODEL_SONNET}, grok.Fallbacks)
+	sonnet, _ := GetChatModelConfig(CHAT_MODEL_SONNET)
+	assert.Equal(t, Pricing{InputPerMillion: 3, OutputPerMillion: 15}, sonnet.Pricing)
+	assert.ElementsMatch(t, []string{"us-west-2", "us-east-1"}, MantleRegions())
+}
+
+func TestLoadModelConfigsFromJSON(t *testing.T) {
+	configs, err := LoadModelConfigs([]byte(`{
+		"models": [
+			{
+				"name": "mini",
+				"type": "chat",
+				"provider": "bedrock-mantle",
+				"provider_model_id": "openai.gpt-mini",
+				"mantle_region": "eu-west-1",
+				"aliases": ["mini", "gpt mini"],
+				"error_message": "Mini is not available",
+				"defaults": {"temperature": 0.2, "max_tokens": 512},
+				"retry": {"max_attempts": 5, "base_delay": "100ms", "max_delay": "2s"},
+				"pricing": {"input_per_million": 0.5, "output_per_million": 2}
+			}
+		]
+	}`))
+	require.NoError(t, err)
+	require.Len(t, configs, 1)
+
+	assert.Equal(t, ModelConfig{
+		ChatModel:       "mini",
+		Type:            ModelTypeChat,
+		Provider:        ProviderBedrockMantle,
+		ProviderModelID: "openai.gpt-mini",
+		MantleRegion:    "eu-west-1",
+		Aliases:         []string{"mini", "gpt mini"},
+		Retry:           &RetryPolicy{MaxAttempts: 5, BaseDelay: 100 * time.Millisecond, MaxDelay: 2 * time.Second},
+		Temperature:     0.2,
+		MaxTokens:       512,
+		Pricing:         Pricing{InputPerMillion: 0.5, OutputPerMillion: 2},
+		ErrorMessage:    "Mini is not available",
+	}, configs[0])
+}
+
+func TestLoadModelConfigsValidation(t *testing.T) {
+	tests := []struct {
+		name     string
+		registry string
+		errors   []string
+	}{
+		{
+			name:     "no models",
+			registry: `models: []`,
+			errors:   []string{"no models defined"},
+		},
+		{
+			name:     "unknown field",
+			registry: "models:\n  - name: a\n    modle_id: x\n",
+			errors:   []string{"field modle_id not found"},
+		},
+		{
+			name: "duplicate alias",
+			registry: `
+models:
+  - {name: a, type: chat, provider: bedrock, provider_model_id: a, aliases: [smart], error_message: a}
+  - {name: b, type: chat, provider: bedrock, provider_model_id: b, aliases: [b, smart], error_message: b}
+`,
+			errors: []string{`model "b": alias "smart" is already used by model "a"`},
+		},
+		{
+			name: "unknown provider and type",
+			registry: `
+models:
+  - {name: a, type: video, provider: openai, provider_model_id: a, aliases: [a], error_message: a}
+`,
+			errors: []string{`unknown provider "openai"`, `unknown type "video"`},
+		},
+		{
+			name: "mantle region",
+			registry: `
+models:
+  - {name: a, type: chat, provider: bedrock-mantle, provider_model_id: a, aliases: [a], error_message: a}
+  - {name: b, type: chat, provider: bedrock, provider_model_id: b, mantle_region: us-east-1, aliases: [b], error_message: b}
+`,
+			errors: []string{`model "a": mantle_region is required`, `model "b": mantle_region is only used`},
+		},
+		{
+			name: "missing fields",
+			registry: `
+models:
+  - {name: a, type: chat, provider: bedrock}
+`,
+			errors: []string{"provider_model_id is required", "non-empty alias is required", "error_message is required"},
+		},
+		{
+			name: "bad fallbacks",
+			registry: `
+models:
+  - {name: a, type: chat, provider: bedrock, provider_model_id: a, aliases: [a], fallbacks: [a, missing, img], error_message: a}
+  - {name: img, type: image, provider: cloudflare, provider_model_id: img, aliases: [img], error_message: img}
+`,
+			errors: []string{`falls back to itself`, `fallback "missing" is not a configured chat model`, `fallback "img" is not a configured chat model`},
+		},
+		{
+			name: "bad retry",
+			registry: `
+models:
+  - {name: a, type: chat, provider: bedrock, provider_model_id: a, aliases: [a], error_message: a, retry: {max_attempts: 0}}
+`,
+			errors: []string{"retry needs max_attempts of at least 1"},
+		},
+		{
+			name: "negative pricing",
+			registry: `
+models:
+  - {name: a, type: chat, provider: bedrock, provider_model_id: a, aliases: [a], error_message: a, pricing: {input_per_million: -1}}
+`,
+			errors: []string{"pricing cannot be negative"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadModelConfigs([]byte(tt.registry))
+			require.Error(t, err)
+			for _, msg := range tt.errors {
+				assert.ErrorContains(t, err, msg)
+			}
+		})
+	}
+}
+
+func TestLoadedRegistryDrivesClients(t *testing.T) {
+	configs, err := LoadModelConfigs([]byte(`
+models:
+  - name: sonnet
+    type: chat
+    provider: bedrock
+    provider_model_id: eu.anthropic.claude-sonnet-4-6
+    aliases: [sonnet, claude]
+    error_message: Sonnet is not available
+    defaults: {temperature: 0.5, max_tokens: 300}
+  - name: gpt
+    type: chat
+    provider: bedrock-mantle
+    provider_model_id: openai.gpt-5.5
+    mantle_region: eu-north-1
+    aliases: [gpt]
+    error_message: GPT is not available
+`))
+	require.NoError(t, err)
+	useTestRegistry(t, configs)
+	RegisterAvailableClients(false)
+	t.Cleanup(func() { RegisterAvailableClients(false) })
+
+	assert.Equal(t, []string{"eu-north-1"}, MantleRegions())
+	assert.Equal(t, []string{"sonnet", "claude", "gpt"}, GetModelAliases())
+	cfg, ok := GetChatModelByAlias("claude")
+	assert.True(t, ok)
+	assert.Equal(t, CHAT_MODEL_SONNET, cfg.ChatModel)
+	assert.False(t, IsModelAvailable(CHAT_MODEL_OPUS))
+
+	mockBedrock := &mockBedrockAPI{}
+	mockBedrock.On("GenerateContent", mock.Anything, mock.Anything, GenerateOptions{
+		Model:       "eu.anthropic.claude-sonnet-4-6",
+		Temperature: 0.5,
+		MaxTokens:   300,
+	}).Return(&GenerateResponse{Content: "hello"}, nil).Once()
+
+	c := Client{&Resources{BedrockAPI: mockBedrock}}
+	resp, err := c.TextGeneration(context.Background(), "hi", CHAT_MODEL_SONNET)
+	require.NoError(t, err)
+	assert.Equal(t, "hello", resp)
+	mockBedrock.AssertExpectations(t)
+}
@@ -0,0 +1,185 @@
+package chatmodels
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/smithy-go"
+	openai "github.com/openai/openai-go"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// RetryPolicy controls how a failed provider call is retried before giving up
+// and moving on to the model's fallbacks.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of calls made, including the first.
+	MaxAttempts int
+	// BaseDelay is doubled after every attempt, up to MaxDelay, and a random
+	// delay up to that value is waited before the next attempt.
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+}
+
+// DefaultRetryPolicy is used for models that do not configure their own.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	BaseDelay:   250 * time.Millisecond,
+	MaxDelay:    4 * time.Second,
+}
+
+// deadlineMargin is the time a retried call is assumed to need at the least;
+// no retry is made when less than the delay plus this margin remains.
+const deadlineMargin = time.Second
+
+// statusError is returned for non-2xx responses from providers called without
+// an SDK, keeping the headers so Retry-After can be honoured.
+type statusError struct {
+	Op         string
+	StatusCode int
+	Header     http.Header
+	Body       string
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("%s: HTTP %d: %s", e.Op, e.StatusCode, e.Body)
+}
+
+// transientBedrockErrors are the Bedrock error codes worth retrying against
+// the same model.
+var transientBedrockErrors = map[string]bool{
+	"ThrottlingException":         true,
+	"ServiceUnavailableException": true,
+	"InternalServerException":     true,
+	"ModelNotReadyException":      true,
+	"ModelTimeoutException":       true,
+}
+
+// isTransient reports whether err is worth retrying against the same model.
+func isTransient(provider Provider, err error) bool {
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+
+	if provider == ProviderBedrock {
+		var apiErr smithy.APIError
+		if errors.As(err, &apiErr) {
+			return transientBedrockErrors[apiErr.ErrorCode()]
+		}
+	}
+
+	code, ok := statusCode(err)
+	if !ok {
+		return false
+	}
+	return code == http.StatusRequestTimeout || code == http.StatusTooManyRequests || code >= http.StatusInternalServerError
+}
+
+// statusCode extracts the HTTP status code from a provider error.
+func statusCode(err error) (int, bool) {
+	var respErr *awshttp.ResponseError
+	if errors.As(err, &respErr) {
+		return respErr.HTTPStatusCode(), true
+	}
+
+	var openaiErr *openai.Error
+	if errors.As(err, &openaiErr) {
+		return openaiErr.StatusCode, true
+	}
+
+	var sErr *statusError
+	if errors.As(err, &sErr) {
+		return sErr.StatusCode, true
+	}
+	return 0, false
+}
+
+// retryAfter reads the Retry-After header, in seconds or as an HTTP date, from
+// the response carried by a provider error.
+func retryAfter(err error) (time.Duration, bool) {
+	var header http.Header
+
+	var respErr *awshttp.ResponseError
+	var openaiErr *openai.Error
+	var sErr *statusError
+	switch {
+	case errors.As(err, &respErr) && respErr.Response != nil:
+		header = respErr.Response.Header
+	case errors.As(err, &openaiErr) && openaiErr.Response != nil:
+		header = openaiErr.Response.Header
+	case errors.As(err, &sErr):
+		header = sErr.Header
+	}
+
+	value := header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return max(time.Until(at), 0), true
+	}
+	return 0, false
+}
+
+// backoff returns a random delay between zero and the exponential ceiling for
+// the given attempt.
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	ceiling := p.BaseDelay
+	for i := 1; i < attempt && ceiling < p.MaxDelay; i++ {
+		ceiling *= 2
+	}
+	ceiling = min(ceiling, p.MaxDelay)
+	if ceiling <= 0 {
+		return 0
+	}
+	return rand.N(ceiling + 1)
+}
+
+// withRetry calls fn until it succeeds, fails with an error that is not
+// transient for the provider, or the policy's attempts run out. A Retry-After
+// longer than MaxDelay, or a context deadline closer than the next delay, ends
+// the retries early so the caller can move on to a fallback.
+func withRetry[T any](ctx context.Context, policy RetryPolicy, provider Provider, fn func() (T, error)) (T, error) {
+	span := trace.SpanFromContext(ctx)
+
+	for attempt := 1; ; attempt++ {
+		res, err := fn()
+		if err == nil || attempt >= policy.MaxAttempts || !isTransient(provider, err) {
+			return res, err
+		}
+
+		delay := policy.backoff(attempt)
+		if after, ok := retryAfter(err); ok {
+			if after > policy.MaxDelay {
+				return res, err
+			}
+			delay = max(delay, after)
+		}
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay+deadlineMargin {
+			return res, err
+		}
+
+		span.AddEvent("retry", trace.WithAttributes(
+			attribute.Int("attempt", attempt),
+			attribute.String("delay", delay.String()),
+			attribute.String("error", err.Error()),
+		))
+
+		select {
+		case <-ctx.Done():
+			return res, err
+		case <-time.After(delay):
+		}
+	}
+}
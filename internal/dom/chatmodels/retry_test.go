@@ -0,0 +1,180 @@
+package chatmodels
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const cloudflareChatResponse = `{
+	"id": "chatcmpl-1",
+	"object": "chat.completion",
+	"created": 1700000000,
+	"model": "@cf/meta/llama-3.3-70b-instruct-fp8-fast",
+	"choices": [{"index": 0, "finish_reason": "stop", "message": {"role": "assistant", "content": "hello there"}}]
+}`
+
+const cloudflareImageResponse = `{"result": {"image": "aGVsbG8="}, "success": true, "errors": []}`
+
+var fastRetries = &RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 10 * time.Millisecond}
+
+// flakyHandler fails the first failures requests with status and the given
+// headers, then answers with body.
+func flakyHandler(calls *atomic.Int32, failures int32, status int, header http.Header, body string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body)
+		if calls.Add(1) <= failures {
+			for k, v := range header {
+				w.Header()[k] = v
+			}
+			w.WriteHeader(status)
+			_, _ = w.Write([]byte(`{"error": "try again"}`))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(body))
+	}
+}
+
+func newTestCloudflareClient(t *testing.T, handler http.HandlerFunc) *CloudflareApiClient {
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	return newCloudflareApiClient(srv.URL, "key", srv.Client())
+}
+
+func llamaConfig(policy *RetryPolicy) ModelConfig {
+	cfg, _ := GetChatModelConfig(CHAT_MODEL_LLAMA)
+	cfg.Retry = policy
+	return cfg
+}
+
+func grokConfig(policy *RetryPolicy) ModelConfig {
+	cfg, _ := GetChatModelConfig(CHAT_MODEL_GROK)
+	cfg.Retry = policy
+	return cfg
+}
+
+var hello = []Message{{Role: RoleUser, Content: "hi"}}
+
+func TestCloudflareChatRetriesUntilSuccess(t *testing.T) {
+	var calls atomic.Int32
+	cf := newTestCloudflareClient(t, flakyHandler(&calls, 2, http.StatusServiceUnavailable, nil, cloudflareChatResponse))
+
+	c := Client{&Resources{CloudflareAPI: cf}}
+	resp, err := c.callModel(context.Background(), hello, llamaConfig(fastRetries))
+	require.NoError(t, err)
+	assert.Equal(t, "hello there", resp)
+	assert.EqualValues(t, 3, calls.Load())
+}
+
+func TestCloudflareImageRetriesUntilSuccess(t *testing.T) {
+	var calls atomic.Int32
+	cf := newTestCloudflareClient(t, flakyHandler(&calls, 1, http.StatusTooManyRequests, nil, cloudflareImageResponse))
+
+	policy := DefaultRetryPolicy
+	DefaultRetryPolicy = *fastRetries
+	t.Cleanup(func() { DefaultRetryPolicy = policy })
+
+	c := Client{&Resources{CloudflareAPI: cf}}
+	image, err := c.GenerateImage(context.Background(), "a cat", IMAGE_MODEL_FLUX)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("hello"), image)
+	assert.EqualValues(t, 2, calls.Load())
+}
+
+func TestMantleRetriesUntilSuccess(t *testing.T) {
+	var calls atomic.Int32
+	mantle := newTestMantleClient(t, flakyHandler(&calls, 2, http.StatusInternalServerError, nil, mantleTestResponse))
+
+	c := Client{&Resources{MantleAPI: mantle}}
+	resp, err := c.callModel(context.Background(), hello, grokConfig(fastRetries))
+	require.NoError(t, err)
+	assert.Equal(t, "hello there", resp)
+	assert.EqualValues(t, 3, calls.Load())
+}
+
+func TestRetryGivesUpAfterMaxAttempts(t *testing.T) {
+	var calls atomic.Int32
+	mantle := newTestMantleClient(t, flakyHandler(&calls, 10, http.StatusBadGateway, nil, mantleTestResponse))
+
+	c := Client{&Resources{MantleAPI: mantle}}
+	_, err := c.callModel(context.Background(), hello, grokConfig(fastRetries))
+	assert.Error(t, err)
+	assert.EqualValues(t, 3, calls.Load())
+}
+
+func TestClientErrorIsNotRetried(t *testing.T) {
+	var calls atomic.Int32
+	cf := newTestCloudflareClient(t, flakyHandler(&calls, 10, http.StatusBadRequest, nil, cloudflareChatResponse))
+
+	c := Client{&Resources{CloudflareAPI: cf}}
+	_, err := c.callModel(context.Background(), hello, llamaConfig(fastRetries))
+	assert.Error(t, err)
+	assert.EqualValues(t, 1, calls.Load())
+}
+
+func TestRetryAfterIsHonoured(t *testing.T) {
+	var calls atomic.Int32
+	header := http.Header{"Retry-After": []string{"1"}}
+	cf := newTestCloudflareClient(t, flakyHandler(&calls, 1, http.StatusTooManyRequests, header, cloudflareChatResponse))
+
+	c := Client{&Resources{CloudflareAPI: cf}}
+	start := time.Now()
+	_, err := c.callModel(context.Background(), hello, llamaConfig(&RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond, MaxDelay: 2 * time.Second}))
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, time.Since(start), time.Second)
+	assert.EqualValues(t, 2, calls.Load())
+}
+
+func TestRetryAfterBeyondMaxDelayStopsRetrying(t *testing.T) {
+	var calls atomic.Int32
+	header := http.Header{"Retry-After": []string{"30"}}
+	cf := newTestCloudflareClient(t, flakyHandler(&calls, 1, http.StatusTooManyRequests, header, cloudflareChatResponse))
+
+	c := Client{&Resources{CloudflareAPI: cf}}
+	_, err := c.callModel(context.Background(), hello, llamaConfig(fastRetries))
+	assert.Error(t, err)
+	assert.EqualValues(t, 1, calls.Load())
+}
+
+func TestRetryStopsNearContextDeadline(t *testing.T) {
+	var calls atomic.Int32
+	mantle := newTestMantleClient(t, flakyHandler(&calls, 10, http.StatusServiceUnavailable, nil, mantleTestResponse))
+
+	ctx, cancel := context.WithTimeout(context.Background(), deadlineMargin/2)
+	defer cancel()
+
+	c := Client{&Resources{MantleAPI: mantle}}
+	_, err := c.callModel(ctx, hello, grokConfig(fastRetries))
+	assert.Error(t, err)
+	assert.EqualValues(t, 1, calls.Load())
+}
+
+func TestBackoffStaysWithinCeiling(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 10, BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+	tests := []struct {
+		attempt int
+		ceiling time.Duration
+	}{
+		{1, 100 * time.Millisecond},
+		{2, 200 * time.Millisecond},
+		{3, 400 * time.Millisecond},
+		{4, 800 * time.Millisecond},
+		{5, time.Second},
+		{60, time.Second},
+	}
+	for _, tt := range tests {
+		for range 50 {
+			delay := policy.backoff(tt.attempt)
+			assert.GreaterOrEqual(t, delay, time.Duration(0))
+			assert.LessOrEqual(t, delay, tt.ceiling)
+		}
+	}
+}
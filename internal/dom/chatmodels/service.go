@@ -11,6 +11,8 @@ type Resources struct {
 type Service interface {
 	TextGeneration(context.Context, string, ChatModel) (string, error)
 	TextGenerationWithSystem(context.Context, string, string, ChatModel) (string, error)
+	ChatGeneration(context.Context, []Message, ChatModel) (string, error)
+	StreamGeneration(context.Context, []Message, ChatModel, DeltaFunc) (string, error)
 	GenerateImage(context.Context, string, ImageModel) ([]byte, error)
 	Translate(
 		ctx context.Context,
@@ -18,7 +20,8 @@ type Service interface {
 		sourceLang string,
 		targetLang string,
 		model ChatModel,
-	) (string, error)
+		opts TranslateOptions,
+	) (Translation, error)
 }
 
 type Client struct {
@@ -0,0 +1,173 @@
+package chatmodels
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/aws/smithy-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// sseHandler writes each event as a server-sent event.
+func sseHandler(events ...string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, event := range events {
+			fmt.Fprintf(w, "data: %s\n\n", event)
+		}
+	}
+}
+
+func chatChunk(content string) string {
+	return fmt.Sprintf(`{"id":"chatcmpl-1","object":"chat.completion.chunk","created":1700000000,"model":"m","choices":[{"index":0,"delta":{"content":%q},"finish_reason":null}]}`, content)
+}
+
+func textDelta(seq int, delta string) string {
+	return fmt.Sprintf(`{"type":"response.output_text.delta","item_id":"msg_1","output_index":0,"content_index":0,"delta":%q,"sequence_number":%d,"logprobs":[]}`, delta, seq)
+}
+
+func collect(deltas *[]string) DeltaFunc {
+	return func(delta string) error {
+		*deltas = append(*deltas, delta)
+		return nil
+	}
+}
+
+func TestCloudflareStreamContent(t *testing.T) {
+	cf := newTestCloudflareClient(t, sseHandler(chatChunk("The sky "), chatChunk("is blue."), "[DONE]"))
+
+	var deltas []string
+	resp, err := cf.StreamContent(context.Background(), hello, GenerateOptions{Model: "m"}, collect(&deltas))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"The sky ", "is blue."}, deltas)
+	assert.Equal(t, "The sky is blue.", resp.Content)
+}
+
+func TestCloudflareStreamContentRecordsUsage(t *testing.T) {
+	usage := `{"id":"chatcmpl-1","object":"chat.completion.chunk","created":1700000000,"model":"m","choices":[],"usage":{"prompt_tokens":12,"completion_tokens":5,"total_tokens":17}}`
+	cf := newTestCloudflareClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			StreamOptions struct {
+				IncludeUsage bool `json:"include_usage"`
+			} `json:"stream_options"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		// like OpenAI, the usage chunk is only sent when asked for
+		if body.StreamOptions.IncludeUsage {
+			sseHandler(chatChunk("The sky is blue."), usage, "[DONE]")(w, r)
+			return
+		}
+		sseHandler(chatChunk("The sky is blue."), "[DONE]")(w, r)
+	})
+
+	var deltas []string
+	resp, err := cf.StreamContent(context.Background(), hello, GenerateOptions{Model: "m"}, collect(&deltas))
+	require.NoError(t, err)
+	assert.Equal(t, Usage{InputTokens: 12, OutputTokens: 5}, resp.Usage)
+}
+
+func TestMantleStreamContent(t *testing.T) {
+	mantle := newTestMantleClient(t, sseHandler(
+		`{"type":"response.created","sequence_number":0,"response":{"id":"resp_1","object":"response","created_at":1700000000,"model":"xai.grok-4.3","status":"in_progress","output":[]}}`,
+		textDelta(1, "The sky "),
+		textDelta(2, "is blue."),
+	))
+
+	var deltas []string
+	resp, err := mantle.StreamContent(context.Background(), hello, GenerateOptions{Model: "xai.grok-4.3", MantleRegion: "us-west-2"}, collect(&deltas))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"The sky ", "is blue."}, deltas)
+	assert.Equal(t, "The sky is blue.", resp.Content)
+}
+
+func TestMantleStreamContentError(t *testing.T) {
+	mantle := newTestMantleClient(t, sseHandler(
+		textDelta(1, "The sky "),
+		`{"type":"error","code":"server_error","message":"model crashed","param":null,"sequence_number":2}`,
+	))
+
+	var deltas []string
+	_, err := mantle.StreamContent(context.Background(), hello, GenerateOptions{Model: "xai.grok-4.3", MantleRegion: "us-west-2"}, collect(&deltas))
+	assert.ErrorContains(t, err, "model crashed")
+	assert.Equal(t, []string{"The sky "}, deltas)
+}
+
+func TestStreamContentStopsWhenDeltaFuncFails(t *testing.T) {
+	cf := newTestCloudflareClient(t, sseHandler(chatChunk("one "), chatChunk("two "), chatChunk("three"), "[DONE]"))
+
+	stop := errors.New("stop")
+	calls := 0
+	_, err := cf.StreamContent(context.Background(), hello, GenerateOptions{Model: "m"}, func(string) error {
+		calls++
+		return stop
+	})
+	assert.ErrorIs(t, err, stop)
+	assert.Equal(t, 1, calls)
+}
+
+func TestStreamGenerationDeliversDeltas(t *testing.T) {
+	mockBedrock := &mockBedrockAPI{}
+	mockBedrock.On("StreamContent", mock.Anything, hello, mock.Anything).
+		Return(&GenerateResponse{Content: "The sky is blue."}, nil, []string{"The sky ", "is blue."})
+
+	generation := &Generation{}
+	var deltas []string
+	c := Client{&Resources{BedrockAPI: mockBedrock}}
+	resp, err := c.StreamGeneration(WithGeneration(context.Background(), generation), hello, CHAT_MODEL_SONNET, collect(&deltas))
+	require.NoError(t, err)
+	assert.Equal(t, "The sky is blue.", resp)
+	assert.Equal(t, "The sky is blue.", strings.Join(deltas, ""))
+	assert.Equal(t, CHAT_MODEL_SONNET, generation.Model)
+	mockBedrock.AssertNotCalled(t, "GenerateContent", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestStreamGenerationFailingBeforeAnyTextFallsBackToGenerate(t *testing.T) {
+	noRetries(t)
+
+	mockBedrock := &mockBedrockAPI{}
+	mockBedrock.On("StreamContent", mock.Anything, hello, mock.Anything).
+		Return(nil, &smithy.GenericAPIError{Code: "ValidationException", Message: "streaming is not supported"})
+	mockBedrock.On("GenerateContent", mock.Anything, hello, mock.Anything).
+		Return(&GenerateResponse{Content: "The sky is blue."}, nil).Once()
+
+	var deltas []string
+	c := Client{&Resources{BedrockAPI: mockBedrock}}
+	resp, err := c.StreamGeneration(context.Background(), hello, CHAT_MODEL_SONNET, collect(&deltas))
+	require.NoError(t, err)
+	assert.Equal(t, "The sky is blue.", resp)
+	assert.Empty(t, deltas)
+	mockBedrock.AssertExpectations(t)
+}
+
+func TestStreamGenerationFailingAfterTextReturnsError(t *testing.T) {
+	mockBedrock := &mockBedrockAPI{}
+	mockBedrock.On("StreamContent", mock.Anything, hello, mock.Anything).
+		Return(nil, &smithy.GenericAPIError{Code: "ModelStreamErrorException"}, []string{"The sky "})
+
+	var deltas []string
+	c := Client{&Resources{BedrockAPI: mockBedrock}}
+	_, err := c.StreamGeneration(context.Background(), hello, CHAT_MODEL_SONNET, collect(&deltas))
+	assert.Error(t, err)
+	assert.Equal(t, []string{"The sky "}, deltas)
+	mockBedrock.AssertNotCalled(t, "GenerateContent", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestStreamGenerationWithoutProviderClientUsesFallbacks(t *testing.T) {
+	mockBedrock := &mockBedrockAPI{}
+	mockBedrock.On("GenerateContent", mock.Anything, hello, mock.Anything).
+		Return(&GenerateResponse{Content: "hello from sonnet"}, nil).Once()
+
+	generation := &Generation{}
+	c := Client{&Resources{BedrockAPI: mockBedrock}}
+	resp, err := c.StreamGeneration(WithGeneration(context.Background(), generation), hello, CHAT_MODEL_LLAMA, collect(new([]string)))
+	require.NoError(t, err)
+	assert.Equal(t, "hello from sonnet", resp)
+	assert.Equal(t, CHAT_MODEL_SONNET, generation.Model)
+}
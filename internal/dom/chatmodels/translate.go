@@ -0,0 +1,114 @@
+package chatmodels
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// romanizationLabel starts the line holding the romanised translation.
+const romanizationLabel = "Romanization:"
+
+// TranslateOptions adjusts how Translate translates.
+type TranslateOptions struct {
+	// Glossary maps terms to the translations that must always be used for them.
+	Glossary map[string]string
+	// Romanize asks for a romanised version alongside non-Latin scripts.
+	Romanize bool
+}
+
+// Translation is translated text and, when asked for and the target language
+// is not written in the Latin alphabet, how to pronounce it.
+type Translation struct {
+	Text         string
+	Romanization string
+}
+
+// Translate translates prompt with model, or CHAT_MODEL_TRANSLATIONS when
+// model is empty. Languages are ISO 639-1 codes.
+func (client *Client) Translate(
+	ctx context.Context,
+	prompt string,
+	sourceLang string,
+	targetLang string,
+	model ChatModel,
+	opts TranslateOptions,
+) (Translation, error) {
+	ctx, span := tracer.Start(ctx, "Translate")
+	defer span.End()
+
+	if sourceLang == "" {
+		sourceLang = "en"
+	}
+	if targetLang == "" {
+		targetLang = "ja"
+	}
+	if model == "" {
+		model = CHAT_MODEL_TRANSLATIONS
+	}
+	span.SetAttributes(
+		attribute.String("model", model.String()),
+		attribute.Int("glossary-terms", len(opts.Glossary)),
+		attribute.Bool("romanize", opts.Romanize),
+	)
+
+	answer, err := client.TextGenerationWithSystem(ctx, translationPrompt(sourceLang, targetLang, opts), prompt, model)
+	if err != nil {
+		return Translation{}, err
+	}
+	if !opts.Romanize {
+		return Translation{Text: strings.TrimSpace(answer)}, nil
+	}
+	return parseTranslation(answer), nil
+}
+
+func translationPrompt(sourceLang, targetLang string, opts TranslateOptions) string {
+	var prompt strings.Builder
+	fmt.Fprintf(&prompt,
+		"You are a translator. Translate the following text from %s to %s. Output only the translated text, nothing else.",
+		sourceLang, targetLang,
+	)
+
+	if len(opts.Glossary) > 0 {
+		terms := make([]string, 0, len(opts.Glossary))
+		for term := range opts.Glossary {
+			terms = append(terms, term)
+		}
+		sort.Strings(terms)
+
+		prompt.WriteString("\n\nAlways use these translations for the following terms:")
+		for _, term := range terms {
+			fmt.Fprintf(&prompt, "\n- %s: %s", term, opts.Glossary[term])
+		}
+	}
+
+	if opts.Romanize {
+		fmt.Fprintf(&prompt,
+			"\n\nIf %s is not written in the Latin alphabet, add a second line starting with %q giving the translation romanised so an English speaker can pronounce it.",
+			targetLang, romanizationLabel,
+		)
+	}
+	return prompt.String()
+}
+
+// parseTranslation splits an answer into the translation and the romanisation
+// line, if the model wrote one.
+func parseTranslation(answer string) Translation {
+	var text []string
+	var translation Translation
+	for _, line := range strings.Split(answer, "\n") {
+		if rest, ok := strings.CutPrefix(strings.TrimSpace(line), romanizationLabel); ok {
+			translation.Romanization = strings.TrimSpace(rest)
+			continue
+		}
+		text = append(text, line)
+	}
+	translation.Text = strings.TrimSpace(strings.Join(text, "\n"))
+	if translation.Romanization == translation.Text {
+		translation.Romanization = ""
+	}
+	return translation
+}
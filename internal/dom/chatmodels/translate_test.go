@@ -0,0 +1,96 @@
+package chatmodels
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTranslateUsesRequestedModel(t *testing.T) {
+	opus, _ := GetChatModelConfig(CHAT_MODEL_OPUS)
+	translations, _ := GetChatModelConfig(CHAT_MODEL_TRANSLATIONS)
+
+	tests := []struct {
+		model ChatModel
+		want  string
+	}{
+		{model: CHAT_MODEL_OPUS, want: opus.ProviderModelID},
+		{model: "", want: translations.ProviderModelID},
+	}
+	for _, tt := range tests {
+		t.Run(tt.model.String(), func(t *testing.T) {
+			mockBedrock := &mockBedrockAPI{}
+			mockBedrock.On("GenerateContent", mock.Anything, mock.Anything, mock.MatchedBy(func(opts GenerateOptions) bool {
+				return opts.Model == tt.want
+			})).Return(&GenerateResponse{Content: " bonjour\n"}, nil).Once()
+
+			c := Client{&Resources{BedrockAPI: mockBedrock}}
+			translation, err := c.Translate(context.Background(), "hello", "en", "fr", tt.model, TranslateOptions{})
+			require.NoError(t, err)
+			assert.Equal(t, Translation{Text: "bonjour"}, translation)
+			mockBedrock.AssertExpectations(t)
+		})
+	}
+}
+
+func TestTranslateDefaultsToISOCodes(t *testing.T) {
+	mockBedrock := &mockBedrockAPI{}
+	mockBedrock.On("GenerateContent", mock.Anything, mock.MatchedBy(func(messages []Message) bool {
+		return messages[0].Role == RoleSystem &&
+			assert.Contains(t, messages[0].Content, "Translate the following text from en to ja.")
+	}), mock.Anything).Return(&GenerateResponse{Content: "こんにちは"}, nil).Once()
+
+	c := Client{&Resources{BedrockAPI: mockBedrock}}
+	_, err := c.Translate(context.Background(), "hello", "", "", CHAT_MODEL_SONNET, TranslateOptions{})
+	require.NoError(t, err)
+	mockBedrock.AssertExpectations(t)
+}
+
+func TestTranslationPromptListsGlossary(t *testing.T) {
+	prompt := translationPrompt("en", "fr", TranslateOptions{Glossary: map[string]string{
+		"jack's cafe": "Jack's Cafe",
+		"cheers":      "santé",
+	}})
+	assert.Contains(t, prompt, "Always use these translations for the following terms:\n- cheers: santé\n- jack's cafe: Jack's Cafe")
+	assert.NotContains(t, prompt, romanizationLabel)
+}
+
+func TestTranslateRomanizesNonLatinScripts(t *testing.T) {
+	tests := []struct {
+		name   string
+		answer string
+		want   Translation
+	}{
+		{
+			name:   "romanized",
+			answer: "안녕하세요\nRomanization: annyeonghaseyo",
+			want:   Translation{Text: "안녕하세요", Romanization: "annyeonghaseyo"},
+		},
+		{
+			name:   "latin script",
+			answer: "bonjour",
+			want:   Translation{Text: "bonjour"},
+		},
+		{
+			name:   "romanization repeats text",
+			answer: "bonjour\nRomanization: bonjour",
+			want:   Translation{Text: "bonjour"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockBedrock := &mockBedrockAPI{}
+			mockBedrock.On("GenerateContent", mock.Anything, mock.MatchedBy(func(messages []Message) bool {
+				return assert.Contains(t, messages[0].Content, `a second line starting with "Romanization:"`)
+			}), mock.Anything).Return(&GenerateResponse{Content: tt.answer}, nil).Once()
+
+			c := Client{&Resources{BedrockAPI: mockBedrock}}
+			translation, err := c.Translate(context.Background(), "hello", "en", "ko", CHAT_MODEL_SONNET, TranslateOptions{Romanize: true})
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, translation)
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package chatmodels
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// recordUsage prices the usage of a call to cfg's model, records it on the
+// current span and in the token and cost metrics, and adds it to the
+// context's Generation.
+func recordUsage(ctx context.Context, cfg ModelConfig, resp *GenerateResponse) {
+	usage := resp.Usage
+	usage.Cost = cfg.Pricing.Cost(usage)
+
+	trace.SpanFromContext(ctx).SetAttributes(
+		attribute.Int("input-tokens", usage.InputTokens),
+		attribute.Int("output-tokens", usage.OutputTokens),
+		attribute.Float64("cost-usd", usage.Cost),
+		attribute.String("stop-reason", resp.StopReason),
+	)
+
+	model := attribute.String("model", cfg.ChatModel.String())
+	provider := attribute.String("provider", string(cfg.Provider))
+	tokenCounter.Add(ctx, int64(usage.InputTokens), metric.WithAttributes(model, provider, attribute.String("direction", "input")))
+	tokenCounter.Add(ctx, int64(usage.OutputTokens), metric.WithAttributes(model, provider, attribute.String("direction", "output")))
+	costCounter.Add(ctx, usage.Cost, metric.WithAttributes(model, provider))
+
+	if g := GenerationFrom(ctx); g != nil {
+		g.Usage.Add(usage)
+		g.StopReason = resp.StopReason
+	}
+}
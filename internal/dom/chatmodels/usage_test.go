@@ -0,0 +1,70 @@
+package chatmodels
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackmcguire1/alexa-chatgpt/internal/otel/metrictest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+func TestPricingCost(t *testing.T) {
+	pricing := Pricing{InputPerMillion: 3, OutputPerMillion: 15}
+	assert.InDelta(t, 0.0105, pricing.Cost(Usage{InputTokens: 1000, OutputTokens: 500}), 1e-9)
+	assert.Zero(t, Pricing{}.Cost(Usage{InputTokens: 1000, OutputTokens: 500}))
+}
+
+func TestGenerationRecordsUsage(t *testing.T) {
+	model := attribute.String("model", CHAT_MODEL_SONNET.String())
+	input, output := attribute.String("direction", "input"), attribute.String("direction", "output")
+	inputBefore := metrictest.Sum(t, "chatmodels.tokens", model, input)
+	outputBefore := metrictest.Sum(t, "chatmodels.tokens", model, output)
+	costBefore := metrictest.Sum(t, "chatmodels.cost", model)
+
+	mockBedrock := &mockBedrockAPI{}
+	mockBedrock.On("GenerateContent", mock.Anything, mock.Anything, mock.Anything).Return(&GenerateResponse{
+		Content:    "hello",
+		Usage:      Usage{InputTokens: 1000, OutputTokens: 500},
+		StopReason: "end_turn",
+	}, nil).Twice()
+
+	c := Client{&Resources{BedrockAPI: mockBedrock}}
+	generation := &Generation{}
+	ctx := WithGeneration(context.Background(), generation)
+	for range 2 {
+		_, err := c.TextGeneration(ctx, "hi", CHAT_MODEL_SONNET)
+		require.NoError(t, err)
+	}
+
+	assert.Equal(t, 2000, generation.Usage.InputTokens)
+	assert.Equal(t, 1000, generation.Usage.OutputTokens)
+	assert.InDelta(t, 0.021, generation.Usage.Cost, 1e-9)
+	assert.Equal(t, "end_turn", generation.StopReason)
+
+	assert.Equal(t, float64(2000), metrictest.Sum(t, "chatmodels.tokens", model, input)-inputBefore)
+	assert.Equal(t, float64(1000), metrictest.Sum(t, "chatmodels.tokens", model, output)-outputBefore)
+	assert.InDelta(t, 0.021, metrictest.Sum(t, "chatmodels.cost", model)-costBefore, 1e-9)
+}
+
+func TestProviderCallsAreMeasuredPerModel(t *testing.T) {
+	opus := attribute.String("model", CHAT_MODEL_OPUS.String())
+	success, failure := attribute.String("outcome", "success"), attribute.String("outcome", "error")
+	successBefore := metrictest.Sum(t, "chatmodels.call.duration", opus, success)
+	failureBefore := metrictest.Sum(t, "chatmodels.call.duration", opus, failure)
+
+	mockBedrock := &mockBedrockAPI{}
+	mockBedrock.On("GenerateContent", mock.Anything, mock.Anything, mock.Anything).
+		Return((*GenerateResponse)(nil), &statusError{Op: "bedrock", StatusCode: 503}).Once()
+	mockBedrock.On("GenerateContent", mock.Anything, mock.Anything, mock.Anything).
+		Return(&GenerateResponse{Content: "hello"}, nil).Once()
+
+	c := Client{&Resources{BedrockAPI: mockBedrock}}
+	_, err := c.TextGeneration(context.Background(), "hi", CHAT_MODEL_OPUS)
+	require.NoError(t, err)
+
+	assert.Equal(t, float64(1), metrictest.Sum(t, "chatmodels.call.duration", opus, success)-successBefore)
+	assert.Equal(t, float64(1), metrictest.Sum(t, "chatmodels.call.duration", opus, failure)-failureBefore)
+}
@@ -0,0 +1,86 @@
+package otel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdoutmetric"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+)
+
+// Metrics exporters accepted by SetupMetrics.
+const (
+	MetricsExporterOTLP   = "otlp"
+	MetricsExporterStdout = "stdout"
+	MetricsExporterNone   = "none"
+)
+
+var UnknownMetricsExporterErr = fmt.Errorf("unknown metrics exporter")
+
+// SetupMetrics installs a global meter provider that periodically exports
+// with exporter. OTLP is sent over gRPC unless OTEL_EXPORTER_OTLP_PROTOCOL
+// is http/protobuf, to the endpoint in the standard OTEL_EXPORTER_OTLP_*
+// variables. With MetricsExporterNone, or no exporter, measurements are
+// recorded but never exported.
+func SetupMetrics(ctx context.Context, exporter string) (*sdkmetric.MeterProvider, error) {
+	var readers []sdkmetric.Reader
+	switch exporter {
+	case MetricsExporterNone, "":
+	case MetricsExporterStdout:
+		exp, err := stdoutmetric.New()
+		if err != nil {
+			return nil, err
+		}
+		readers = append(readers, sdkmetric.NewPeriodicReader(exp))
+	case MetricsExporterOTLP:
+		exp, err := newOTLPMetricExporter(ctx)
+		if err != nil {
+			return nil, err
+		}
+		readers = append(readers, sdkmetric.NewPeriodicReader(exp))
+	default:
+		return nil, fmt.Errorf("%w: %q", UnknownMetricsExporterErr, exporter)
+	}
+	return NewMeterProvider(readers...), nil
+}
+
+func newOTLPMetricExporter(ctx context.Context) (sdkmetric.Exporter, error) {
+	protocol := os.Getenv("OTEL_EXPORTER_OTLP_METRICS_PROTOCOL")
+	if protocol == "" {
+		protocol = os.Getenv("OTEL_EXPORTER_OTLP_PROTOCOL")
+	}
+	if protocol == "http/protobuf" {
+		return otlpmetrichttp.New(ctx)
+	}
+	return otlpmetricgrpc.New(ctx)
+}
+
+// NewMeterProvider installs a global meter provider reading with readers.
+func NewMeterProvider(readers ...sdkmetric.Reader) *sdkmetric.MeterProvider {
+	var opts []sdkmetric.Option
+	for _, reader := range readers {
+		opts = append(opts, sdkmetric.WithReader(reader))
+	}
+	mp := sdkmetric.NewMeterProvider(opts...)
+	otel.SetMeterProvider(mp)
+	return mp
+}
+
+// Flushers force flushes each provider in turn, such as the tracer and meter
+// providers at the end of a Lambda invocation.
+type Flushers []interface {
+	ForceFlush(context.Context) error
+}
+
+func (f Flushers) ForceFlush(ctx context.Context) error {
+	var errs []error
+	for _, flusher := range f {
+		errs = append(errs, flusher.ForceFlush(ctx))
+	}
+	return errors.Join(errs...)
+}
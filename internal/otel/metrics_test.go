@@ -0,0 +1,43 @@
+package otel
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetupMetricsSelectsExporter(t *testing.T) {
+	for _, exporter := range []string{"", MetricsExporterNone, MetricsExporterStdout, MetricsExporterOTLP} {
+		t.Run(exporter, func(t *testing.T) {
+			mp, err := SetupMetrics(context.Background(), exporter)
+			require.NoError(t, err)
+			assert.NotNil(t, mp)
+		})
+	}
+
+	_, err := SetupMetrics(context.Background(), "prometheus")
+	assert.ErrorIs(t, err, UnknownMetricsExporterErr)
+}
+
+type flusher struct {
+	flushed bool
+	err     error
+}
+
+func (f *flusher) ForceFlush(context.Context) error {
+	f.flushed = true
+	return f.err
+}
+
+func TestFlushersFlushEveryProvider(t *testing.T) {
+	failing := &flusher{err: errors.New("collector unreachable")}
+	ok := &flusher{}
+
+	err := Flushers{failing, ok}.ForceFlush(context.Background())
+	assert.ErrorContains(t, err, "collector unreachable")
+	assert.True(t, failing.flushed)
+	assert.True(t, ok.flushed)
+}
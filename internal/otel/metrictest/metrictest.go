@@ -0,0 +1,78 @@
+// Package metrictest reads the measurements recorded through the global meter
+// provider in tests.
+package metrictest
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	otelsetup "github.com/jackmcguire1/alexa-chatgpt/internal/otel"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+var (
+	once   sync.Once
+	reader *sdkmetric.ManualReader
+)
+
+// Reader returns an in-memory reader of the global meter provider. The global
+// provider only takes over instruments made before it once, so every test in
+// a binary shares the reader, and tests compare sums before and after.
+func Reader() *sdkmetric.ManualReader {
+	once.Do(func() {
+		reader = sdkmetric.NewManualReader()
+		otelsetup.NewMeterProvider(reader)
+	})
+	return reader
+}
+
+// Sum adds up the points of the counter or histogram called name whose
+// attributes include attrs. Histograms are summed by count.
+func Sum(t *testing.T, name string, attrs ...attribute.KeyValue) float64 {
+	t.Helper()
+	var metrics metricdata.ResourceMetrics
+	require.NoError(t, Reader().Collect(context.Background(), &metrics))
+
+	var sum float64
+	for _, scope := range metrics.ScopeMetrics {
+		for _, m := range scope.Metrics {
+			if m.Name != name {
+				continue
+			}
+			switch data := m.Data.(type) {
+			case metricdata.Sum[int64]:
+				for _, point := range data.DataPoints {
+					if matches(point.Attributes, attrs) {
+						sum += float64(point.Value)
+					}
+				}
+			case metricdata.Sum[float64]:
+				for _, point := range data.DataPoints {
+					if matches(point.Attributes, attrs) {
+						sum += point.Value
+					}
+				}
+			case metricdata.Histogram[float64]:
+				for _, point := range data.DataPoints {
+					if matches(point.Attributes, attrs) {
+						sum += float64(point.Count)
+					}
+				}
+			}
+		}
+	}
+	return sum
+}
+
+func matches(set attribute.Set, attrs []attribute.KeyValue) bool {
+	for _, attr := range attrs {
+		if value, ok := set.Value(attr.Key); !ok || value != attr.Value {
+			return false
+		}
+	}
+	return true
+}
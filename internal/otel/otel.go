@@ -5,24 +5,9 @@ import (
 	"fmt"
 	"net/http"
 
-	"go.opentelemetry.io/contrib/instrumentation/github.com/aws/aws-lambda-go/otellambda/xrayconfig"
-	"go.opentelemetry.io/contrib/propagators/aws/xray"
-	"go.opentelemetry.io/otel"
-	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	"go.opentelemetry.io/otel/trace"
 )
 
-func SetupXrayOtel(ctx context.Context) (*sdktrace.TracerProvider, error) {
-	tp, err := xrayconfig.NewTracerProvider(ctx)
-	if err != nil {
-		return nil, err
-	}
-
-	otel.SetTracerProvider(tp)
-	otel.SetTextMapPropagator(xray.Propagator{})
-	return tp, nil
-}
-
 func GetXRayTraceID(ctx context.Context) string {
 	spanCtx := trace.SpanContextFromContext(ctx)
 	if !spanCtx.IsValid() {
@@ -34,6 +19,15 @@ func GetXRayTraceID(ctx context.Context) string {
 	return fmt.Sprintf("1-%s-%s", traceID[0:8], traceID[8:])
 }
 
+// GetSpanID returns the hex ID of the span in ctx, or "" when there is none.
+func GetSpanID(ctx context.Context) string {
+	spanCtx := trace.SpanContextFromContext(ctx)
+	if !spanCtx.HasSpanID() {
+		return ""
+	}
+	return spanCtx.SpanID().String()
+}
+
 func DefaultTransportFormatter(_ string, r *http.Request) string {
 	return r.URL.Host
 }
@@ -0,0 +1,173 @@
+package otel
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/jackmcguire1/alexa-chatgpt/internal/pkg/redact"
+	lambdadetector "go.opentelemetry.io/contrib/detectors/aws/lambda"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/aws/aws-lambda-go/otellambda"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/aws/aws-lambda-go/otellambda/xrayconfig"
+	"go.opentelemetry.io/contrib/propagators/aws/xray"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Trace exporters accepted by SetupTracing.
+const (
+	TracesExporterXRay   = "xray"
+	TracesExporterOTLP   = "otlp"
+	TracesExporterStdout = "stdout"
+	TracesExporterNone   = "none"
+)
+
+// Propagators accepted by NewPropagator.
+const (
+	PropagatorXRay         = "xray"
+	PropagatorTraceContext = "tracecontext"
+	PropagatorBaggage      = "baggage"
+)
+
+var (
+	UnknownTracesExporterErr = fmt.Errorf("unknown traces exporter")
+	UnknownPropagatorErr     = fmt.Errorf("unknown propagator")
+)
+
+// SetupTracing installs a global tracer provider exporting with exporter and
+// a global propagator made of propagators, X-Ray when none are given. Spans
+// are masked by redactor before they are exported, unless it is nil.
+//
+// TracesExporterXRay sends X-Ray compatible spans to the collector Lambda
+// layer, so only works on Lambda. TracesExporterOTLP is sent over gRPC unless
+// OTEL_EXPORTER_OTLP_PROTOCOL is http/protobuf, to the endpoint in the
+// standard OTEL_EXPORTER_OTLP_* variables. With TracesExporterNone, or no
+// exporter, spans are created and propagated but never exported.
+func SetupTracing(ctx context.Context, exporter string, redactor *redact.Redactor, propagators ...string) (*sdktrace.TracerProvider, error) {
+	propagator, err := NewPropagator(propagators...)
+	if err != nil {
+		return nil, err
+	}
+
+	var exp sdktrace.SpanExporter
+	var opts []sdktrace.TracerProviderOption
+	switch exporter {
+	case TracesExporterNone, "":
+	case TracesExporterXRay:
+		// as xrayconfig.NewTracerProvider, which can't have its exporter wrapped
+		if os.Getenv("AWS_LAMBDA_FUNCTION_NAME") == "" {
+			return nil, fmt.Errorf("the %s traces exporter only runs on Lambda", exporter)
+		}
+		exp, err = otlptracegrpc.New(ctx, otlptracegrpc.WithInsecure())
+		opts = append(opts, sdktrace.WithIDGenerator(xray.NewIDGenerator()))
+	case TracesExporterStdout:
+		exp, err = stdouttrace.New()
+	case TracesExporterOTLP:
+		exp, err = newOTLPTraceExporter(ctx)
+	default:
+		return nil, fmt.Errorf("%w: %q", UnknownTracesExporterErr, exporter)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if exp != nil {
+		if redactor != nil {
+			exp = redact.NewSpanExporter(exp, redactor)
+		}
+		opts = append(opts, sdktrace.WithBatcher(exp))
+	}
+
+	res, err := newResource(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tp := sdktrace.NewTracerProvider(append(opts, sdktrace.WithResource(res))...)
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagator)
+	return tp, nil
+}
+
+func newOTLPTraceExporter(ctx context.Context) (sdktrace.SpanExporter, error) {
+	protocol := os.Getenv("OTEL_EXPORTER_OTLP_TRACES_PROTOCOL")
+	if protocol == "" {
+		protocol = os.Getenv("OTEL_EXPORTER_OTLP_PROTOCOL")
+	}
+	if protocol == "http/protobuf" {
+		return otlptracehttp.New(ctx)
+	}
+	return otlptracegrpc.New(ctx)
+}
+
+// newResource describes the Lambda function when running on Lambda, and the
+// process otherwise.
+func newResource(ctx context.Context) (*resource.Resource, error) {
+	if os.Getenv("AWS_LAMBDA_FUNCTION_NAME") != "" {
+		return lambdadetector.NewResourceDetector().Detect(ctx)
+	}
+	return resource.Default(), nil
+}
+
+// NewPropagator combines the named propagators, defaulting to X-Ray.
+func NewPropagator(names ...string) (propagation.TextMapPropagator, error) {
+	var propagators []propagation.TextMapPropagator
+	for _, name := range names {
+		switch strings.TrimSpace(name) {
+		case "":
+		case PropagatorXRay:
+			propagators = append(propagators, xray.Propagator{})
+		case PropagatorTraceContext:
+			propagators = append(propagators, propagation.TraceContext{})
+		case PropagatorBaggage:
+			propagators = append(propagators, propagation.Baggage{})
+		default:
+			return nil, fmt.Errorf("%w: %q", UnknownPropagatorErr, name)
+		}
+	}
+	if len(propagators) == 0 {
+		return xray.Propagator{}, nil
+	}
+	return propagation.NewCompositeTextMapPropagator(propagators...), nil
+}
+
+// LambdaOptions instruments a Lambda handler with tp, extracting the X-Ray
+// trace header Lambda sets with the global propagator and flushing flusher
+// at the end of each invocation.
+func LambdaOptions(tp *sdktrace.TracerProvider, flusher otellambda.Flusher) []otellambda.Option {
+	return []otellambda.Option{
+		xrayconfig.WithEventToCarrier(),
+		otellambda.WithPropagator(otel.GetTextMapPropagator()),
+		otellambda.WithTracerProvider(tp),
+		otellambda.WithFlusher(flusher),
+	}
+}
+
+// ContextWithRemoteParent returns ctx with span spanID of trace traceID, as
+// carried on a queued request, as its remote parent, so spans started from it
+// continue that trace. traceID is an X-Ray trace ID or 32 hex digits. Without
+// a valid spanID the parent is given a random one, so spans still join the
+// trace, though not under the span that queued the request. ok is false when
+// traceID can't be parsed.
+func ContextWithRemoteParent(ctx context.Context, traceID, spanID string) (context.Context, bool) {
+	tid, err := trace.TraceIDFromHex(strings.ReplaceAll(strings.TrimPrefix(traceID, "1-"), "-", ""))
+	if err != nil {
+		return ctx, false
+	}
+	sid, err := trace.SpanIDFromHex(spanID)
+	if err != nil {
+		rand.Read(sid[:])
+	}
+	return trace.ContextWithRemoteSpanContext(ctx, trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    tid,
+		SpanID:     sid,
+		TraceFlags: trace.FlagsSampled,
+		Remote:     true,
+	})), true
+}
@@ -0,0 +1,108 @@
+package otel
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/jackmcguire1/alexa-chatgpt/internal/pkg/redact"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestSetupTracingSelectsExporter(t *testing.T) {
+	t.Setenv("AWS_LAMBDA_FUNCTION_NAME", "")
+	for _, exporter := range []string{"", TracesExporterNone, TracesExporterStdout, TracesExporterOTLP} {
+		t.Run(exporter, func(t *testing.T) {
+			tp, err := SetupTracing(context.Background(), exporter, &redact.Redactor{})
+			require.NoError(t, err)
+			assert.NotNil(t, tp)
+		})
+	}
+
+	_, err := SetupTracing(context.Background(), "zipkin", nil)
+	assert.ErrorIs(t, err, UnknownTracesExporterErr)
+
+	_, err = SetupTracing(context.Background(), TracesExporterNone, nil, "b3")
+	assert.ErrorIs(t, err, UnknownPropagatorErr)
+
+	// X-Ray needs the Lambda environment
+	_, err = SetupTracing(context.Background(), TracesExporterXRay, nil)
+	assert.Error(t, err)
+}
+
+func TestNewPropagator(t *testing.T) {
+	traceID, _ := trace.TraceIDFromHex("5759e988bd862e3fe1be46a994272793")
+	spanID, _ := trace.SpanIDFromHex("53995c3f42cd8ad8")
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: trace.FlagsSampled,
+	}))
+
+	tests := []struct {
+		names   []string
+		headers []string
+	}{
+		{names: nil, headers: []string{"X-Amzn-Trace-Id"}},
+		{names: []string{""}, headers: []string{"X-Amzn-Trace-Id"}},
+		{names: []string{PropagatorTraceContext}, headers: []string{"Traceparent"}},
+		{names: []string{PropagatorXRay, " tracecontext"}, headers: []string{"X-Amzn-Trace-Id", "Traceparent"}},
+	}
+	for _, tt := range tests {
+		propagator, err := NewPropagator(tt.names...)
+		require.NoError(t, err)
+
+		carrier := propagation.HeaderCarrier(http.Header{})
+		propagator.Inject(ctx, carrier)
+		for _, header := range tt.headers {
+			assert.NotEmpty(t, carrier.Get(header), "%v should set %s", tt.names, header)
+		}
+		assert.Equal(t, traceID, trace.SpanContextFromContext(propagator.Extract(context.Background(), carrier)).TraceID())
+	}
+}
+
+func TestContextWithRemoteParent(t *testing.T) {
+	want, _ := trace.TraceIDFromHex("5759e988bd862e3fe1be46a994272793")
+	tests := []struct {
+		traceID string
+		spanID  string
+		ok      bool
+	}{
+		{traceID: "1-5759e988-bd862e3fe1be46a994272793", spanID: "53995c3f42cd8ad8", ok: true},
+		{traceID: "5759e988bd862e3fe1be46a994272793", spanID: "53995c3f42cd8ad8", ok: true},
+		{traceID: "1-5759e988-bd862e3fe1be46a994272793", ok: true},
+		{traceID: ""},
+		{traceID: "1-5759e988"},
+		{traceID: "1-00000000-000000000000000000000000"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.traceID, func(t *testing.T) {
+			ctx, ok := ContextWithRemoteParent(context.Background(), tt.traceID, tt.spanID)
+			assert.Equal(t, tt.ok, ok)
+
+			parent := trace.SpanContextFromContext(ctx)
+			if !tt.ok {
+				assert.False(t, parent.IsValid())
+				return
+			}
+			assert.True(t, parent.IsValid())
+			assert.True(t, parent.IsRemote())
+			assert.True(t, parent.IsSampled())
+			assert.Equal(t, want, parent.TraceID())
+			if tt.spanID != "" {
+				assert.Equal(t, tt.spanID, parent.SpanID().String())
+			}
+		})
+	}
+}
+
+func TestGetSpanID(t *testing.T) {
+	assert.Empty(t, GetSpanID(context.Background()))
+
+	ctx, _ := ContextWithRemoteParent(context.Background(), "1-5759e988-bd862e3fe1be46a994272793", "53995c3f42cd8ad8")
+	assert.Equal(t, "53995c3f42cd8ad8", GetSpanID(ctx))
+	assert.Equal(t, "1-5759e988-bd862e3fe1be46a994272793", GetXRayTraceID(ctx))
+}
@@ -0,0 +1,108 @@
+package alexa
+
+import (
+	"embed"
+	"encoding/json"
+)
+
+const (
+	// APLInterface is the supported interface of devices with a screen that
+	// can render APL documents.
+	APLInterface = "Alexa.Presentation.APL"
+	// RenderDocumentDirective renders an APL document on the device.
+	RenderDocumentDirective = "Alexa.Presentation.APL.RenderDocument"
+)
+
+//go:embed apl/*.json
+var aplDocuments embed.FS
+
+// Directive is an instruction for the device returned alongside the speech.
+type Directive struct {
+	Type        string          `json:"type"`
+	Token       string          `json:"token,omitempty"`
+	Document    json.RawMessage `json:"document,omitempty"`
+	DataSources map[string]any  `json:"datasources,omitempty"`
+}
+
+// BattleshipCell is what the player knows about a square of the board.
+type BattleshipCell string
+
+const (
+	CellWater BattleshipCell = "water"
+	CellMiss  BattleshipCell = "miss"
+	CellHit   BattleshipCell = "hit"
+	CellSunk  BattleshipCell = "sunk"
+)
+
+// SupportsAPL reports whether the requesting device can render APL documents.
+func (r Request) SupportsAPL() bool {
+	_, ok := r.Context.System.Device.SupportedInterfaces[APLInterface]
+	return ok
+}
+
+// AddDirective appends d to the directives sent back to the device.
+func (r *Response) AddDirective(d Directive) {
+	r.Body.Directives = append(r.Body.Directives, d)
+}
+
+// NewImageDocument shows a generated image full screen.
+func NewImageDocument(title, imageURL string) Directive {
+	return renderDocument("image", "image", map[string]any{
+		"title": title,
+		"url":   imageURL,
+	})
+}
+
+// NewAnswerDocument shows a chat answer as scrollable text, with the model
+// that wrote it and how many seconds it took.
+func NewAnswerDocument(title, answer, model, latency string) Directive {
+	return renderDocument("answer", "answer", map[string]any{
+		"title":   title,
+		"text":    answer,
+		"model":   model,
+		"latency": latency,
+	})
+}
+
+// NewBattleshipDocument shows the board as a grid of the player's shots, one
+// slice of cells per row.
+func NewBattleshipDocument(title string, board [][]BattleshipCell) Directive {
+	rows := make([]map[string]any, len(board))
+	for i, cells := range board {
+		rows[i] = map[string]any{"cells": cells}
+	}
+	return renderDocument("battleship", "board", map[string]any{
+		"title": title,
+		"rows":  rows,
+	})
+}
+
+// NewHintsDocument shows the hints given so far in the animal game.
+func NewHintsDocument(title string, hints []string, guessesLeft, hintsLeft int) Directive {
+	if hints == nil {
+		hints = []string{}
+	}
+	return renderDocument("hints", "game", map[string]any{
+		"title":       title,
+		"hints":       hints,
+		"guessesLeft": guessesLeft,
+		"hintsLeft":   hintsLeft,
+	})
+}
+
+// renderDocument builds a RenderDocument directive for the embedded document
+// name, binding data to payload.<key> where the document reads it from.
+func renderDocument(name string, key string, data map[string]any) Directive {
+	document, err := aplDocuments.ReadFile("apl/" + name + ".json")
+	if err != nil {
+		panic(err)
+	}
+	return Directive{
+		Type:     RenderDocumentDirective,
+		Token:    name,
+		Document: document,
+		DataSources: map[string]any{
+			"payload": map[string]any{key: data},
+		},
+	}
+}
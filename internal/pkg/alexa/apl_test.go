@@ -0,0 +1,92 @@
+package alexa
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// assertGolden compares the directive's JSON with testdata/apl/<name>.golden,
+// rewriting the file instead when the tests run with -update.
+func assertGolden(t *testing.T, name string, d Directive) {
+	t.Helper()
+	got, err := json.MarshalIndent(d, "", "  ")
+	require.NoError(t, err)
+
+	path := filepath.Join("testdata", "apl", name+".golden")
+	if *update {
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, append(got, '\n'), 0o644))
+	}
+
+	want, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.JSONEq(t, string(want), string(got))
+}
+
+func TestAPLDocuments(t *testing.T) {
+	tests := []struct {
+		name      string
+		directive Directive
+	}{
+		{
+			name:      "image",
+			directive: NewImageDocument("a lighthouse at dusk", "https://s3.amazonaws.com/bucket/images/req-1/1200x800.jpg"),
+		},
+		{
+			name:      "answer",
+			directive: NewAnswerDocument("why is the sky blue", "Sunlight is scattered by the air.\n\nBlue light scatters most.", "sonnet", "3"),
+		},
+		{
+			name: "battleship",
+			directive: NewBattleshipDocument("BattleShips", [][]BattleshipCell{
+				{CellWater, CellMiss, CellWater},
+				{CellHit, CellWater, CellWater},
+				{CellSunk, CellSunk, CellMiss},
+			}),
+		},
+		{
+			name:      "hints",
+			directive: NewHintsDocument("Animal Game", []string{"It lives on a farm.", "It says moo."}, 8, 3),
+		},
+		{
+			name:      "hints_empty",
+			directive: NewHintsDocument("Animal Game", nil, 10, 5),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, RenderDocumentDirective, tt.directive.Type)
+			assertGolden(t, tt.name, tt.directive)
+		})
+	}
+}
+
+func TestSupportsAPL(t *testing.T) {
+	var req Request
+	require.NoError(t, json.Unmarshal([]byte(`{"context":{"System":{"device":{"supportedInterfaces":{"Alexa.Presentation.APL":{"runtime":{"maxVersion":"2024.3"}}}}}}}`), &req))
+	assert.True(t, req.SupportsAPL())
+
+	var audioOnly Request
+	require.NoError(t, json.Unmarshal([]byte(`{"context":{"System":{"device":{"supportedInterfaces":{"AudioPlayer":{}}}}}}`), &audioOnly))
+	assert.False(t, audioOnly.SupportsAPL())
+}
+
+func TestResponseWithoutDirectivesOmitsThem(t *testing.T) {
+	data, err := json.Marshal(NewResponse("title", "text", false))
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "directives")
+
+	res := NewResponse("title", "text", false)
+	res.AddDirective(NewAnswerDocument("title", "text", "sonnet", "1"))
+	data, err = json.Marshal(res)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"directives":[{"type":"Alexa.Presentation.APL.RenderDocument"`)
+}
@@ -6,11 +6,16 @@ const (
 	NoIntent                 = "AMAZON.NoIntent"
 	StopIntent               = "AMAZON.StopIntent"
 	FallbackIntent           = "AMAZON.FallbackIntent"
+	NavigateHomeIntent       = "AMAZON.NavigateHomeIntent"
+	NextIntent               = "AMAZON.NextIntent"
+	RepeatIntent             = "AMAZON.RepeatIntent"
+	PreviousIntent           = "AMAZON.PreviousIntent"
 	AutoCompleteIntent       = "AutoCompleteIntent"
 	ImageIntent              = "ImageIntent"
 	SystemMessageIntent      = "SystemMessage"
 	SystemAutoCompleteIntent = "SystemAutoComplete"
 	TranslateIntent          = "TranslateIntent"
+	GlossaryIntent           = "Glossary"
 	RandomFactIntent         = "RandomFactIntent"
 	BattleShipsIntent        = "Battleship"
 	BattleshipStatusIntent   = "BattleshipStatus"
@@ -21,4 +26,7 @@ const (
 	RandomNumberIntent       = "Guess"
 	PurgeIntent              = "Purge"
 	LastResponseIntent       = "LastResponseIntent"
+	NewConversationIntent    = "NewConversation"
+	ForgetIntent             = "Forget"
+	UsageIntent              = "Usage"
 )
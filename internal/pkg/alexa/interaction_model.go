@@ -0,0 +1,88 @@
+package alexa
+
+import "strings"
+
+// InteractionModel is the skill.json document describing the invocation name,
+// intents and custom slot types of a locale.
+type InteractionModel struct {
+	InteractionModel struct {
+		LanguageModel LanguageModel `json:"languageModel"`
+	} `json:"interactionModel"`
+}
+
+type LanguageModel struct {
+	InvocationName string              `json:"invocationName"`
+	Intents        []InteractionIntent `json:"intents"`
+	Types          []SlotType          `json:"types,omitempty"`
+}
+
+type InteractionIntent struct {
+	Name    string            `json:"name"`
+	Slots   []InteractionSlot `json:"slots,omitempty"`
+	Samples []string          `json:"samples"`
+}
+
+type InteractionSlot struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// SlotType is a custom slot type and the values Alexa should expect for it.
+type SlotType struct {
+	Name   string          `json:"name"`
+	Values []SlotTypeValue `json:"values"`
+}
+
+type SlotTypeValue struct {
+	Name struct {
+		Value    string   `json:"value"`
+		Synonyms []string `json:"synonyms,omitempty"`
+	} `json:"name"`
+}
+
+// NewSlotType builds a custom slot type with values and no synonyms.
+func NewSlotType(name string, values []string) SlotType {
+	slotType := SlotType{Name: name, Values: make([]SlotTypeValue, len(values))}
+	for i, value := range values {
+		slotType.Values[i].Name.Value = value
+	}
+	return slotType
+}
+
+// IntentDefinition is the Go side definition of an intent, from which the
+// interaction model of each locale is generated.
+type IntentDefinition struct {
+	Name  string
+	Slots []InteractionSlot
+	// Samples holds the utterances by locale, e.g. en-GB, or by language,
+	// e.g. en, for utterances shared by all of its locales.
+	Samples map[string][]string
+}
+
+// SamplesFor returns the utterances of locale followed by those of its
+// language.
+func (d IntentDefinition) SamplesFor(locale string) []string {
+	samples := append([]string{}, d.Samples[locale]...)
+	if language, _, ok := strings.Cut(locale, "-"); ok {
+		samples = append(samples, d.Samples[language]...)
+	}
+	return samples
+}
+
+// NewInteractionModel builds the interaction model of locale from intents.
+func NewInteractionModel(invocationName string, locale string, intents []IntentDefinition, types []SlotType) InteractionModel {
+	var model InteractionModel
+	model.InteractionModel.LanguageModel = LanguageModel{
+		InvocationName: invocationName,
+		Intents:        make([]InteractionIntent, len(intents)),
+		Types:          types,
+	}
+	for i, intent := range intents {
+		model.InteractionModel.LanguageModel.Intents[i] = InteractionIntent{
+			Name:    intent.Name,
+			Slots:   intent.Slots,
+			Samples: intent.SamplesFor(locale),
+		}
+	}
+	return model
+}
@@ -0,0 +1,39 @@
+package alexa
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSamplesFor(t *testing.T) {
+	intent := IntentDefinition{Samples: map[string][]string{
+		"en":    {"continue"},
+		"en-GB": {"carry on"},
+		"de":    {"weiter"},
+	}}
+	assert.Equal(t, []string{"carry on", "continue"}, intent.SamplesFor("en-GB"))
+	assert.Equal(t, []string{"continue"}, intent.SamplesFor("en-US"))
+	assert.Equal(t, []string{"weiter"}, intent.SamplesFor("de-DE"))
+	assert.Empty(t, intent.SamplesFor("fr-FR"))
+}
+
+func TestNewInteractionModel(t *testing.T) {
+	model := NewInteractionModel("ask me", "en-US", []IntentDefinition{
+		{Name: FallbackIntent},
+		{Name: ModelIntent, Slots: []InteractionSlot{{Name: "chatModel", Type: "MODEL_LIST"}}, Samples: map[string][]string{"en": {"model {chatModel}"}}},
+	}, []SlotType{NewSlotType("MODEL_LIST", []string{"sonnet", "flux"})})
+
+	data, err := json.Marshal(model)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"interactionModel": {"languageModel": {
+		"invocationName": "ask me",
+		"intents": [
+			{"name": "AMAZON.FallbackIntent", "samples": []},
+			{"name": "Model", "slots": [{"name": "chatModel", "type": "MODEL_LIST"}], "samples": ["model {chatModel}"]}
+		],
+		"types": [{"name": "MODEL_LIST", "values": [{"name": {"value": "sonnet"}}, {"name": {"value": "flux"}}]}]
+	}}}`, string(data))
+}
@@ -0,0 +1,88 @@
+package alexa
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultAPIEndpoint is used for requests that do not name the Alexa API
+	// endpoint of their region.
+	DefaultAPIEndpoint = "https://api.amazonalexa.com"
+	// VoicePlayerSpeakDirective speaks SSML while the skill is still working on
+	// its response.
+	VoicePlayerSpeakDirective = "VoicePlayer.Speak"
+
+	directivesPath = "/v1/directives"
+)
+
+var (
+	MissingAPIAccessTokenErr = fmt.Errorf("request has no api access token")
+	ProgressiveResponseErr   = fmt.Errorf("progressive response was rejected")
+)
+
+// Client calls the Alexa APIs on behalf of the request being served, using the
+// request's API endpoint and access token.
+type Client struct {
+	HTTPClient *http.Client
+}
+
+func NewClient() *Client {
+	return &Client{HTTPClient: &http.Client{Timeout: 2 * time.Second}}
+}
+
+type directiveRequest struct {
+	Header struct {
+		RequestID string `json:"requestId"`
+	} `json:"header"`
+	Directive struct {
+		Type   string `json:"type"`
+		Speech string `json:"speech"`
+	} `json:"directive"`
+}
+
+// SendProgressiveResponse speaks speech to the user while req is still being
+// served. Alexa accepts up to five progressive responses per request.
+func (c *Client) SendProgressiveResponse(ctx context.Context, req Request, speech *SSML) error {
+	token := req.Context.System.APIAccessToken
+	if token == "" {
+		return MissingAPIAccessTokenErr
+	}
+	endpoint := req.Context.System.APIEndpoint
+	if endpoint == "" {
+		endpoint = DefaultAPIEndpoint
+	}
+
+	var body directiveRequest
+	body.Header.RequestID = req.Body.RequestID
+	body.Directive.Type = VoicePlayerSpeakDirective
+	body.Directive.Speech = speech.String()
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(endpoint, "/")+directivesPath, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	httpReq.Header.Set("Authorization", "Bearer "+token)
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.HTTPClient.Do(httpReq)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("%w: HTTP %d %s", ProgressiveResponseErr, resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
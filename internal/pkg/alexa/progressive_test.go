@@ -0,0 +1,55 @@
+package alexa
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func progressiveRequest(endpoint string) Request {
+	var req Request
+	req.Body.RequestID = "amzn1.echo-api.request.1"
+	req.Context.System.APIAccessToken = "token"
+	req.Context.System.APIEndpoint = endpoint
+	return req
+}
+
+func TestSendProgressiveResponse(t *testing.T) {
+	var got directiveRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/v1/directives", r.URL.Path)
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	err := NewClient().SendProgressiveResponse(context.Background(), progressiveRequest(srv.URL), NewSSML().Text("thinking with sonnet & co"))
+	require.NoError(t, err)
+	assert.Equal(t, "amzn1.echo-api.request.1", got.Header.RequestID)
+	assert.Equal(t, VoicePlayerSpeakDirective, got.Directive.Type)
+	assert.Equal(t, "<speak>thinking with sonnet &amp; co</speak>", got.Directive.Speech)
+}
+
+func TestSendProgressiveResponseErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"code":"INVALID_DIRECTIVE"}`, http.StatusBadRequest)
+	}))
+	defer srv.Close()
+
+	err := NewClient().SendProgressiveResponse(context.Background(), progressiveRequest(srv.URL), NewSSML().Text("hi"))
+	assert.ErrorIs(t, err, ProgressiveResponseErr)
+	assert.Contains(t, err.Error(), "INVALID_DIRECTIVE")
+
+	req := progressiveRequest(srv.URL)
+	req.Context.System.APIAccessToken = ""
+	err = NewClient().SendProgressiveResponse(context.Background(), req, NewSSML().Text("hi"))
+	assert.ErrorIs(t, err, MissingAPIAccessTokenErr)
+}
@@ -1,5 +1,7 @@
 package alexa
 
+import "encoding/json"
+
 const (
 	// built-in request types
 	IntentRequestType       = "IntentRequest"
@@ -33,8 +35,10 @@ type Session struct {
 type Context struct {
 	System struct {
 		APIAccessToken string `json:"apiAccessToken"`
+		APIEndpoint    string `json:"apiEndpoint,omitempty"`
 		Device         struct {
-			DeviceID string `json:"deviceId,omitempty"`
+			DeviceID            string                     `json:"deviceId,omitempty"`
+			SupportedInterfaces map[string]json.RawMessage `json:"supportedInterfaces,omitempty"`
 		} `json:"device"`
 		Application struct {
 			ApplicationID string `json:"applicationId,omitempty"`
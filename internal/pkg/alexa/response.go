@@ -59,10 +59,11 @@ type Response struct {
 
 // ResBody is the actual body of the response.
 type ResBody struct {
-	OutputSpeech     *Payload  `json:"outputSpeech,omitempty"`
-	Card             *Payload  `json:"card,omitempty"`
-	Reprompt         *Reprompt `json:"reprompt,omitempty"`
-	ShouldEndSession bool      `json:"shouldEndSession"`
+	OutputSpeech     *Payload    `json:"outputSpeech,omitempty"`
+	Card             *Payload    `json:"card,omitempty"`
+	Reprompt         *Reprompt   `json:"reprompt,omitempty"`
+	Directives       []Directive `json:"directives,omitempty"`
+	ShouldEndSession bool        `json:"shouldEndSession"`
 }
 
 type Reprompt struct {
@@ -0,0 +1,251 @@
+package alexa
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// codeBlockSpeech replaces fenced code blocks, which are unpleasant to hear
+// read out character by character.
+const codeBlockSpeech = "I've left out a code sample, you can read it in the Alexa app."
+
+var (
+	xmlEscaper = strings.NewReplacer(
+		"&", "&amp;",
+		"<", "&lt;",
+		">", "&gt;",
+		`"`, "&quot;",
+		"'", "&apos;",
+	)
+
+	// sayAsPattern finds ISO dates, ordinals and numbers with thousands
+	// separators, which Alexa otherwise reads digit group by digit group.
+	sayAsPattern = regexp.MustCompile(`\b(\d{4}-\d{2}-\d{2})\b|\b(\d+)(?:st|nd|rd|th)\b|\b(\d{1,3}(?:,\d{3})+(?:\.\d+)?)\b`)
+
+	headingPattern = regexp.MustCompile(`^#{1,6}\s+(.*?)\s*#*$`)
+	bulletPattern  = regexp.MustCompile(`^[-*+]\s+(.*)$`)
+	orderedPattern = regexp.MustCompile(`^(\d+)[.)]\s+(.*)$`)
+	rulePattern    = regexp.MustCompile(`^(?:-{3,}|\*{3,}|_{3,})$`)
+	tableSeparator = regexp.MustCompile(`^\|?[\s:|-]+\|?$`)
+	linkPattern    = regexp.MustCompile(`\[([^\]]+)\]\([^)]*\)`)
+	boldPattern    = regexp.MustCompile(`\*\*(.+?)\*\*|__(.+?)__`)
+	italicPattern  = regexp.MustCompile(`\*([^*\s][^*]*)\*`)
+)
+
+// SSML builds the output speech of a response. All text added to it is
+// escaped, so model output can be passed in as is.
+type SSML struct {
+	b strings.Builder
+}
+
+func NewSSML() *SSML {
+	return &SSML{}
+}
+
+// Text adds escaped text, marking up dates, ordinals and large numbers with
+// say-as so they are read naturally.
+func (s *SSML) Text(text string) *SSML {
+	last := 0
+	for _, m := range sayAsPattern.FindAllStringSubmatchIndex(text, -1) {
+		s.b.WriteString(xmlEscaper.Replace(text[last:m[0]]))
+		last = m[1]
+
+		switch {
+		case m[2] >= 0:
+			s.b.WriteString(`<say-as interpret-as="date" format="ymd">` + text[m[2]:m[3]] + `</say-as>`)
+		case m[4] >= 0:
+			s.SayAs("ordinal", text[m[4]:m[5]])
+		case strings.Contains(text[m[6]:m[7]], "."):
+			s.b.WriteString(strings.ReplaceAll(text[m[6]:m[7]], ",", ""))
+		default:
+			s.SayAs("cardinal", strings.ReplaceAll(text[m[6]:m[7]], ",", ""))
+		}
+	}
+	s.b.WriteString(xmlEscaper.Replace(text[last:]))
+	return s
+}
+
+// Break adds a pause of d.
+func (s *SSML) Break(d time.Duration) *SSML {
+	fmt.Fprintf(&s.b, `<break time="%dms"/>`, d.Milliseconds())
+	return s
+}
+
+// Emphasis adds text spoken with the given level, one of strong, moderate
+// or reduced.
+func (s *SSML) Emphasis(level string, text string) *SSML {
+	s.b.WriteString(`<emphasis level="` + xmlEscaper.Replace(level) + `">`)
+	s.Text(text)
+	s.b.WriteString(`</emphasis>`)
+	return s
+}
+
+// SayAs adds text with an explicit interpretation, e.g. cardinal, ordinal,
+// digits or spell-out.
+func (s *SSML) SayAs(interpretAs string, text string) *SSML {
+	s.b.WriteString(`<say-as interpret-as="` + xmlEscaper.Replace(interpretAs) + `">` + xmlEscaper.Replace(text) + `</say-as>`)
+	return s
+}
+
+// Lang adds text spoken with the pronunciation of locale, e.g. fr-FR.
+func (s *SSML) Lang(locale string, text string) *SSML {
+	s.b.WriteString(`<lang xml:lang="` + xmlEscaper.Replace(locale) + `">`)
+	s.Text(text)
+	s.b.WriteString(`</lang>`)
+	return s
+}
+
+// Markdown adds a model answer, turning paragraphs, headings, list items and
+// table rows into paced speech. Formatting markers are dropped and fenced code
+// blocks are left out.
+func (s *SSML) Markdown(md string) *SSML {
+	inParagraph, inCode := false, false
+	closeParagraph := func() {
+		if inParagraph {
+			s.b.WriteString("</p>")
+			inParagraph = false
+		}
+	}
+
+	for _, line := range strings.Split(md, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "```") {
+			if !inCode {
+				closeParagraph()
+				s.sentence(codeBlockSpeech)
+			}
+			inCode = !inCode
+			continue
+		}
+		if inCode {
+			continue
+		}
+
+		switch {
+		case line == "":
+			closeParagraph()
+		case rulePattern.MatchString(line):
+			closeParagraph()
+			s.Break(500 * time.Millisecond)
+		case headingPattern.MatchString(line):
+			closeParagraph()
+			s.b.WriteString("<p>")
+			s.Emphasis("moderate", stripInline(headingPattern.FindStringSubmatch(line)[1]))
+			s.b.WriteString("</p>")
+		case bulletPattern.MatchString(line):
+			closeParagraph()
+			s.sentence(bulletPattern.FindStringSubmatch(line)[1])
+		case orderedPattern.MatchString(line):
+			closeParagraph()
+			item := orderedPattern.FindStringSubmatch(line)
+			s.b.WriteString("<s>")
+			s.SayAs("ordinal", item[1])
+			s.b.WriteString(", ")
+			s.inline(item[2])
+			s.b.WriteString("</s>")
+		case strings.HasPrefix(line, "|"):
+			closeParagraph()
+			if !tableSeparator.MatchString(line) {
+				cells := strings.Split(strings.Trim(line, "|"), "|")
+				for i := range cells {
+					cells[i] = strings.TrimSpace(cells[i])
+				}
+				s.sentence(strings.Join(cells, ", "))
+			}
+		default:
+			if inParagraph {
+				s.b.WriteString(" ")
+			} else {
+				s.b.WriteString("<p>")
+				inParagraph = true
+			}
+			s.inline(line)
+		}
+	}
+	closeParagraph()
+	return s
+}
+
+// sentence adds text as a sentence of its own.
+func (s *SSML) sentence(text string) {
+	s.b.WriteString("<s>")
+	s.inline(text)
+	s.b.WriteString("</s>")
+}
+
+// inline adds a line of markdown, emphasising bold text and dropping links,
+// italics and inline code markers.
+func (s *SSML) inline(text string) {
+	text = linkPattern.ReplaceAllString(text, "$1")
+	text = strings.ReplaceAll(text, "`", "")
+
+	last := 0
+	for _, m := range boldPattern.FindAllStringSubmatchIndex(text, -1) {
+		s.Text(stripItalics(text[last:m[0]]))
+		last = m[1]
+		if m[2] >= 0 {
+			s.Emphasis("moderate", stripItalics(text[m[2]:m[3]]))
+		} else {
+			s.Emphasis("moderate", stripItalics(text[m[4]:m[5]]))
+		}
+	}
+	s.Text(stripItalics(text[last:]))
+}
+
+func stripItalics(text string) string {
+	return italicPattern.ReplaceAllString(text, "$1")
+}
+
+// stripInline drops the markers left in headings, which are already
+// emphasised.
+func stripInline(text string) string {
+	return stripItalics(strings.NewReplacer("**", "", "__", "", "`", "").Replace(text))
+}
+
+// String returns the speech as an SSML document.
+func (s *SSML) String() string {
+	return "<speak>" + s.b.String() + "</speak>"
+}
+
+// OutputSpeech returns the speech as an SSML payload.
+func (s *SSML) OutputSpeech() *Payload {
+	return &Payload{Type: "SSML", SSML: s.String()}
+}
+
+// NewSSMLResponse builds a response that speaks speech and shows text on the
+// card.
+func NewSSMLResponse(title, text string, speech *SSML, endSession bool) Response {
+	r := NewResponse(title, text, endSession)
+	r.Body.OutputSpeech = speech.OutputSpeech()
+	return r
+}
+
+// langLocales maps languages, by English name and ISO 639-1 code, to the
+// locales the SSML lang tag supports.
+var langLocales = map[string]string{
+	"english":    "en-US",
+	"en":         "en-US",
+	"german":     "de-DE",
+	"de":         "de-DE",
+	"spanish":    "es-ES",
+	"es":         "es-ES",
+	"french":     "fr-FR",
+	"fr":         "fr-FR",
+	"hindi":      "hi-IN",
+	"hi":         "hi-IN",
+	"italian":    "it-IT",
+	"it":         "it-IT",
+	"japanese":   "ja-JP",
+	"ja":         "ja-JP",
+	"portuguese": "pt-BR",
+	"pt":         "pt-BR",
+}
+
+// LangLocale returns the lang tag locale for a language name or ISO code, and
+// false when Alexa cannot speak the language.
+func LangLocale(language string) (string, bool) {
+	locale, ok := langLocales[strings.ToLower(strings.TrimSpace(language))]
+	return locale, ok
+}
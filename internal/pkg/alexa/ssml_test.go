@@ -0,0 +1,125 @@
+package alexa
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// assertWellFormed fails when ssml is not a well formed XML document.
+func assertWellFormed(t *testing.T, ssml string) {
+	t.Helper()
+	dec := xml.NewDecoder(strings.NewReader(ssml))
+	for {
+		_, err := dec.Token()
+		if err != nil {
+			assert.Equal(t, "EOF", err.Error(), ssml)
+			return
+		}
+	}
+}
+
+func TestSSMLText(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{name: "escaping", text: `Tom & Jerry's <b>"show"</b>`, want: `Tom &amp; Jerry&apos;s &lt;b&gt;&quot;show&quot;&lt;/b&gt;`},
+		{name: "date", text: "launched on 1969-07-16", want: `launched on <say-as interpret-as="date" format="ymd">1969-07-16</say-as>`},
+		{name: "ordinal", text: "the 21st century", want: `the <say-as interpret-as="ordinal">21</say-as> century`},
+		{name: "grouped number", text: "about 8,100,000 people", want: `about <say-as interpret-as="cardinal">8100000</say-as> people`},
+		{name: "grouped decimal", text: "costs 1,234.56 dollars", want: `costs 1234.56 dollars`},
+		{name: "plain number", text: "42 is the answer", want: `42 is the answer`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ssml := NewSSML().Text(tt.text).String()
+			assert.Equal(t, "<speak>"+tt.want+"</speak>", ssml)
+			assertWellFormed(t, ssml)
+		})
+	}
+}
+
+func TestSSMLMarkdown(t *testing.T) {
+	tests := []struct {
+		name string
+		md   string
+		want string
+	}{
+		{
+			name: "paragraphs",
+			md:   "The sky is blue.\nIt scatters light.\n\nSunsets are red.",
+			want: `<p>The sky is blue. It scatters light.</p><p>Sunsets are red.</p>`,
+		},
+		{
+			name: "heading and bullets",
+			md:   "## Planets\n- **Mercury** is closest\n* Venus is hottest",
+			want: `<p><emphasis level="moderate">Planets</emphasis></p><s><emphasis level="moderate">Mercury</emphasis> is closest</s><s>Venus is hottest</s>`,
+		},
+		{
+			name: "ordered list",
+			md:   "Steps:\n1. Boil water\n2) Add *pasta*",
+			want: `<p>Steps:</p><s><say-as interpret-as="ordinal">1</say-as>, Boil water</s><s><say-as interpret-as="ordinal">2</say-as>, Add pasta</s>`,
+		},
+		{
+			name: "code block is left out",
+			md:   "Use this:\n```go\nif a < b && c {\n}\n```\nDone with `fmt`.",
+			want: `<p>Use this:</p><s>I&apos;ve left out a code sample, you can read it in the Alexa app.</s><p>Done with fmt.</p>`,
+		},
+		{
+			name: "table and rule",
+			md:   "| Name | Age |\n|------|----:|\n| Ann | 30 |\n---\nBye",
+			want: `<s>Name, Age</s><s>Ann, 30</s><break time="500ms"/><p>Bye</p>`,
+		},
+		{
+			name: "links",
+			md:   "See [the docs](https://example.com?a=1&b=2) & more",
+			want: `<p>See the docs &amp; more</p>`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ssml := NewSSML().Markdown(tt.md).String()
+			assert.Equal(t, "<speak>"+tt.want+"</speak>", ssml)
+			assertWellFormed(t, ssml)
+		})
+	}
+}
+
+func TestSSMLBuilder(t *testing.T) {
+	ssml := NewSSML().
+		Text("In French, ").
+		Lang("fr-FR", "c'est la vie").
+		Break(300*time.Millisecond).
+		Emphasis("strong", "well done").
+		SayAs("digits", "1234").
+		String()
+
+	assert.Equal(t, `<speak>In French, <lang xml:lang="fr-FR">c&apos;est la vie</lang><break time="300ms"/><emphasis level="strong">well done</emphasis><say-as interpret-as="digits">1234</say-as></speak>`, ssml)
+	assertWellFormed(t, ssml)
+}
+
+func TestNewSSMLResponse(t *testing.T) {
+	resp := NewSSMLResponse("Response", "**bold** answer", NewSSML().Markdown("**bold** answer"), false)
+	assert.Equal(t, "SSML", resp.Body.OutputSpeech.Type)
+	assert.Equal(t, `<speak><p><emphasis level="moderate">bold</emphasis> answer</p></speak>`, resp.Body.OutputSpeech.SSML)
+	assert.Empty(t, resp.Body.OutputSpeech.Text)
+	assert.Equal(t, "**bold** answer", resp.Body.Card.Text)
+}
+
+func TestLangLocale(t *testing.T) {
+	locale, ok := LangLocale(" French ")
+	assert.True(t, ok)
+	assert.Equal(t, "fr-FR", locale)
+
+	locale, ok = LangLocale("ja")
+	assert.True(t, ok)
+	assert.Equal(t, "ja-JP", locale)
+
+	_, ok = LangLocale("klingon")
+	assert.False(t, ok)
+}
@@ -0,0 +1,212 @@
+package alexa
+
+import (
+	"context"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// SignatureCertChainURLHeader names the header holding the URL of the
+	// certificate chain that signed the request.
+	SignatureCertChainURLHeader = "SignatureCertChainUrl"
+	// SignatureHeader names the header holding the base64 encoded SHA-256
+	// RSA signature of the request body.
+	SignatureHeader = "Signature-256"
+
+	// MaxRequestAge is how far a request's timestamp may be from the current
+	// time before it is treated as a replay.
+	MaxRequestAge = 150 * time.Second
+
+	certHost       = "s3.amazonaws.com"
+	certPathPrefix = "/echo.api/"
+	certSubject    = "echo-api.amazon.com"
+	maxCertSize    = 64 * 1024
+)
+
+var (
+	InvalidCertURLErr     = fmt.Errorf("invalid signature certificate chain url")
+	InvalidCertErr        = fmt.Errorf("invalid signature certificate")
+	InvalidSignatureErr   = fmt.Errorf("invalid request signature")
+	StaleRequestErr       = fmt.Errorf("request timestamp is too far from the current time")
+	UnknownApplicationErr = fmt.Errorf("request is for an unknown application")
+)
+
+// Verifier checks that requests served over HTTPS were sent by Alexa for one
+// of the allowed skills, as required of skills not hosted on Lambda.
+type Verifier struct {
+	ApplicationIDs []string
+	HTTPClient     *http.Client
+	// Roots is the trust store for certificate chains, nil for the system's.
+	Roots *x509.CertPool
+	Now   func() time.Time
+
+	mu    sync.Mutex
+	certs map[string]*x509.Certificate
+}
+
+func NewVerifier(applicationIDs []string) *Verifier {
+	return &Verifier{
+		ApplicationIDs: applicationIDs,
+		HTTPClient:     &http.Client{Timeout: 5 * time.Second},
+		Now:            time.Now,
+		certs:          make(map[string]*x509.Certificate),
+	}
+}
+
+// Verify checks the signature headers against the raw request body and
+// returns the decoded request once its timestamp and application are allowed.
+func (v *Verifier) Verify(ctx context.Context, header http.Header, body []byte) (Request, error) {
+	cert, err := v.certificate(ctx, header.Get(SignatureCertChainURLHeader))
+	if err != nil {
+		return Request{}, err
+	}
+
+	signature, err := base64.StdEncoding.DecodeString(header.Get(SignatureHeader))
+	if err != nil || len(signature) == 0 {
+		return Request{}, InvalidSignatureErr
+	}
+	publicKey, ok := cert.PublicKey.(*rsa.PublicKey)
+	if !ok {
+		return Request{}, fmt.Errorf("%w: not an RSA key", InvalidCertErr)
+	}
+	digest := sha256.Sum256(body)
+	if err := rsa.VerifyPKCS1v15(publicKey, crypto.SHA256, digest[:], signature); err != nil {
+		return Request{}, InvalidSignatureErr
+	}
+
+	var req Request
+	if err := json.Unmarshal(body, &req); err != nil {
+		return Request{}, err
+	}
+
+	timestamp, err := time.Parse(time.RFC3339, req.Body.Timestamp)
+	if err != nil {
+		return Request{}, fmt.Errorf("%w: %q", StaleRequestErr, req.Body.Timestamp)
+	}
+	if age := v.Now().Sub(timestamp); age > MaxRequestAge || age < -MaxRequestAge {
+		return Request{}, StaleRequestErr
+	}
+
+	applicationID := req.Session.Application.ApplicationID
+	if applicationID == "" {
+		applicationID = req.Context.System.Application.ApplicationID
+	}
+	if !slices.Contains(v.ApplicationIDs, applicationID) {
+		return Request{}, fmt.Errorf("%w: %q", UnknownApplicationErr, applicationID)
+	}
+
+	return req, nil
+}
+
+// certificate returns the verified signing certificate at certURL, downloading
+// the chain only when no unexpired certificate for it is cached.
+func (v *Verifier) certificate(ctx context.Context, certURL string) (*x509.Certificate, error) {
+	if err := validateCertURL(certURL); err != nil {
+		return nil, err
+	}
+	now := v.Now()
+
+	v.mu.Lock()
+	cert, ok := v.certs[certURL]
+	v.mu.Unlock()
+	if ok && now.Before(cert.NotAfter) && now.After(cert.NotBefore) {
+		return cert, nil
+	}
+
+	cert, err := v.fetchChain(ctx, certURL, now)
+	if err != nil {
+		return nil, err
+	}
+
+	v.mu.Lock()
+	if v.certs == nil {
+		v.certs = make(map[string]*x509.Certificate)
+	}
+	v.certs[certURL] = cert
+	v.mu.Unlock()
+
+	return cert, nil
+}
+
+func (v *Verifier) fetchChain(ctx context.Context, certURL string, now time.Time) (*x509.Certificate, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, certURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := v.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", InvalidCertErr, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: HTTP %d fetching chain", InvalidCertErr, resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxCertSize))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", InvalidCertErr, err)
+	}
+
+	var chain []*x509.Certificate
+	for block, rest := pem.Decode(data); block != nil; block, rest = pem.Decode(rest) {
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", InvalidCertErr, err)
+		}
+		chain = append(chain, cert)
+	}
+	if len(chain) == 0 {
+		return nil, fmt.Errorf("%w: no certificates in chain", InvalidCertErr)
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, cert := range chain[1:] {
+		intermediates.AddCert(cert)
+	}
+	// Verify checks the validity period, the chain up to a trusted root and
+	// that echo-api.amazon.com is among the subject alternative names.
+	_, err = chain[0].Verify(x509.VerifyOptions{
+		DNSName:       certSubject,
+		Roots:         v.Roots,
+		Intermediates: intermediates,
+		CurrentTime:   now,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", InvalidCertErr, err)
+	}
+
+	return chain[0], nil
+}
+
+// validateCertURL accepts only chains hosted by Amazon under /echo.api/ on
+// s3.amazonaws.com over HTTPS, after resolving any dot segments in the path.
+func validateCertURL(certURL string) error {
+	u, err := url.Parse(certURL)
+	if err != nil {
+		return fmt.Errorf("%w: %w", InvalidCertURLErr, err)
+	}
+	if !strings.EqualFold(u.Scheme, "https") ||
+		!strings.EqualFold(u.Hostname(), certHost) ||
+		(u.Port() != "" && u.Port() != "443") ||
+		!strings.HasPrefix(path.Clean(u.Path), certPathPrefix) {
+		return fmt.Errorf("%w: %q", InvalidCertURLErr, certURL)
+	}
+	return nil
+}
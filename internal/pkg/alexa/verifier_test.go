@@ -0,0 +1,255 @@
+package alexa
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testCertURL = "https://s3.amazonaws.com/echo.api/echo-api-cert.pem"
+	testAppID   = "amzn1.ask.skill.local-test"
+)
+
+// fixtureTime is the timestamp of testdata/intent_request.json.
+var fixtureTime = time.Date(2026, 10, 18, 10, 0, 0, 0, time.UTC)
+
+// testPKI is a locally generated root CA and an echo-api signing certificate.
+type testPKI struct {
+	roots   *x509.CertPool
+	rootKey *rsa.PrivateKey
+	root    *x509.Certificate
+	key     *rsa.PrivateKey
+	chain   []byte
+}
+
+func newTestPKI(t *testing.T) *testPKI {
+	t.Helper()
+	rootKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	rootTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test Root CA"},
+		NotBefore:             fixtureTime.Add(-24 * time.Hour),
+		NotAfter:              fixtureTime.Add(365 * 24 * time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	rootDER, err := x509.CreateCertificate(rand.Reader, rootTemplate, rootTemplate, &rootKey.PublicKey, rootKey)
+	require.NoError(t, err)
+	root, err := x509.ParseCertificate(rootDER)
+	require.NoError(t, err)
+
+	pki := &testPKI{roots: x509.NewCertPool(), rootKey: rootKey, root: root}
+	pki.roots.AddCert(root)
+	pki.key, pki.chain = pki.issue(t, certSubject, fixtureTime.Add(-time.Hour), fixtureTime.Add(24*time.Hour))
+	return pki
+}
+
+// issue signs a leaf certificate for dnsName with the root CA and returns its
+// key and PEM encoded chain.
+func (pki *testPKI) issue(t *testing.T, dnsName string, notBefore, notAfter time.Time) (*rsa.PrivateKey, []byte) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	der, err := x509.CreateCertificate(rand.Reader, &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: dnsName},
+		DNSNames:     []string{dnsName},
+		NotBefore:    notBefore,
+		NotAfter:     notAfter,
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}, pki.root, &key.PublicKey, pki.rootKey)
+	require.NoError(t, err)
+
+	var chain bytes.Buffer
+	pem.Encode(&chain, &pem.Block{Type: "CERTIFICATE", Bytes: der})
+	pem.Encode(&chain, &pem.Block{Type: "CERTIFICATE", Bytes: pki.root.Raw})
+	return key, chain.Bytes()
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
+
+// newTestVerifier returns a verifier trusting pki that is served chain for
+// every download, and a pointer to the number of downloads.
+func newTestVerifier(pki *testPKI, chain []byte) (*Verifier, *int) {
+	fetches := 0
+	v := NewVerifier([]string{testAppID})
+	v.Roots = pki.roots
+	v.Now = func() time.Time { return fixtureTime.Add(10 * time.Second) }
+	v.HTTPClient = &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		fetches++
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewReader(chain)), Request: r}, nil
+	})}
+	return v, &fetches
+}
+
+func sign(t *testing.T, key *rsa.PrivateKey, body []byte) http.Header {
+	t.Helper()
+	digest := sha256.Sum256(body)
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	require.NoError(t, err)
+
+	header := http.Header{}
+	header.Set(SignatureCertChainURLHeader, testCertURL)
+	header.Set(SignatureHeader, base64.StdEncoding.EncodeToString(signature))
+	return header
+}
+
+func fixture(t *testing.T) []byte {
+	t.Helper()
+	body, err := os.ReadFile("testdata/intent_request.json")
+	require.NoError(t, err)
+	return body
+}
+
+func TestVerifyAcceptsSignedRequest(t *testing.T) {
+	pki := newTestPKI(t)
+	v, fetches := newTestVerifier(pki, pki.chain)
+	body := fixture(t)
+
+	req, err := v.Verify(context.Background(), sign(t, pki.key, body), body)
+	require.NoError(t, err)
+	assert.Equal(t, AutoCompleteIntent, req.Body.Intent.Name)
+	assert.Equal(t, "why is the sky blue", req.Body.Intent.Slots["prompt"].Value)
+
+	_, err = v.Verify(context.Background(), sign(t, pki.key, body), body)
+	require.NoError(t, err)
+	assert.Equal(t, 1, *fetches, "chain should be cached")
+}
+
+func TestVerifyRejectsTamperedBody(t *testing.T) {
+	pki := newTestPKI(t)
+	v, _ := newTestVerifier(pki, pki.chain)
+	body := fixture(t)
+	header := sign(t, pki.key, body)
+
+	tampered := bytes.Replace(body, []byte("why is the sky blue"), []byte("purge the queue"), 1)
+	_, err := v.Verify(context.Background(), header, tampered)
+	assert.ErrorIs(t, err, InvalidSignatureErr)
+
+	header.Set(SignatureHeader, "not base64!")
+	_, err = v.Verify(context.Background(), header, body)
+	assert.ErrorIs(t, err, InvalidSignatureErr)
+}
+
+func TestVerifyRejectsUntrustedCertificates(t *testing.T) {
+	pki := newTestPKI(t)
+	other := newTestPKI(t)
+	_, wrongName := pki.issue(t, "example.com", fixtureTime.Add(-time.Hour), fixtureTime.Add(time.Hour))
+	_, expired := pki.issue(t, certSubject, fixtureTime.Add(-48*time.Hour), fixtureTime.Add(-24*time.Hour))
+
+	tests := []struct {
+		name  string
+		chain []byte
+	}{
+		{name: "untrusted root", chain: other.chain},
+		{name: "wrong subject alternative name", chain: wrongName},
+		{name: "expired", chain: expired},
+		{name: "not a certificate", chain: []byte("hello")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, _ := newTestVerifier(pki, tt.chain)
+			body := fixture(t)
+			_, err := v.Verify(context.Background(), sign(t, pki.key, body), body)
+			assert.ErrorIs(t, err, InvalidCertErr)
+		})
+	}
+}
+
+func TestVerifyRejectsStaleTimestamps(t *testing.T) {
+	pki := newTestPKI(t)
+	body := fixture(t)
+
+	tests := []struct {
+		offset time.Duration
+		err    error
+	}{
+		{offset: 0},
+		{offset: 150 * time.Second},
+		{offset: -150 * time.Second},
+		{offset: 151 * time.Second, err: StaleRequestErr},
+		{offset: -151 * time.Second, err: StaleRequestErr},
+	}
+	for _, tt := range tests {
+		t.Run(tt.offset.String(), func(t *testing.T) {
+			v, _ := newTestVerifier(pki, pki.chain)
+			v.Now = func() time.Time { return fixtureTime.Add(tt.offset) }
+			_, err := v.Verify(context.Background(), sign(t, pki.key, body), body)
+			if tt.err == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, tt.err)
+			}
+		})
+	}
+}
+
+func TestVerifyEnforcesApplicationAllowList(t *testing.T) {
+	pki := newTestPKI(t)
+	v, _ := newTestVerifier(pki, pki.chain)
+	v.ApplicationIDs = []string{"amzn1.ask.skill.someone-else"}
+	body := fixture(t)
+
+	_, err := v.Verify(context.Background(), sign(t, pki.key, body), body)
+	assert.ErrorIs(t, err, UnknownApplicationErr)
+}
+
+func TestVerifyRejectsMissingHeaders(t *testing.T) {
+	pki := newTestPKI(t)
+	v, fetches := newTestVerifier(pki, pki.chain)
+
+	_, err := v.Verify(context.Background(), http.Header{}, fixture(t))
+	assert.ErrorIs(t, err, InvalidCertURLErr)
+	assert.Zero(t, *fetches)
+}
+
+func TestValidateCertURL(t *testing.T) {
+	tests := []struct {
+		url   string
+		valid bool
+	}{
+		{url: "https://s3.amazonaws.com/echo.api/echo-api-cert.pem", valid: true},
+		{url: "https://s3.amazonaws.com:443/echo.api/echo-api-cert.pem", valid: true},
+		{url: "HTTPS://s3.AmazonAWS.com/echo.api/echo-api-cert.pem", valid: true},
+		{url: "https://s3.amazonaws.com/echo.api/../echo.api/echo-api-cert.pem", valid: true},
+		{url: "http://s3.amazonaws.com/echo.api/echo-api-cert.pem"},
+		{url: "https://notamazon.com/echo.api/echo-api-cert.pem"},
+		{url: "https://s3.amazonaws.com/EcHo.aPi/echo-api-cert.pem"},
+		{url: "https://s3.amazonaws.com/invalid.path/echo-api-cert.pem"},
+		{url: "https://s3.amazonaws.com/echo.api/../invalid.path/echo-api-cert.pem"},
+		{url: "https://s3.amazonaws.com:563/echo.api/echo-api-cert.pem"},
+		{url: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			err := validateCertURL(tt.url)
+			if tt.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, InvalidCertURLErr)
+			}
+		})
+	}
+}
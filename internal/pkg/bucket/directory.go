@@ -0,0 +1,32 @@
+package bucket
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+)
+
+// Directory is a FilePersistance that writes files under a local directory,
+// for running the skill without S3. BaseURL is where that directory is served
+// from, so the returned URLs can be fetched by the device.
+type Directory struct {
+	Path    string
+	BaseURL string
+}
+
+func (d *Directory) Put(ctx context.Context, reqId string, fileName string, prefix string, data []byte) (string, error) {
+	dir := filepath.Join(d.Path, prefix+reqId)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, fileName), data, 0o644); err != nil {
+		return "", err
+	}
+
+	return d.BaseURL + "/" + prefix + reqId + "/" + fileName, nil
+}
+
+func (d *Directory) FolderName() string {
+	return d.Path
+}
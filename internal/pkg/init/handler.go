@@ -0,0 +1,55 @@
+package init
+
+import (
+	"log/slog"
+	"os"
+	"strconv"
+
+	"github.com/jackmcguire1/alexa-chatgpt/internal/api"
+	"github.com/jackmcguire1/alexa-chatgpt/internal/dom/chatmodels"
+	"github.com/jackmcguire1/alexa-chatgpt/internal/pkg/alexa"
+	"github.com/jackmcguire1/alexa-chatgpt/internal/pkg/queue"
+)
+
+// InitializeHandler creates the skill's handler around svc and its queues,
+// configured from environment variables.
+func InitializeHandler(logger *slog.Logger, svc chatmodels.Service, responses, requests queue.PullPoll) *api.Handler {
+	h := api.NewHandler(
+		logger,
+		svc,
+		responses,
+		requests,
+		EnvInt("POLL_DELAY", 0),
+		GetDefaultChatModel(),
+		GetDefaultImageModel(),
+		InitializeUserStateStore(),
+	)
+	h.ConversationTokenBudget = EnvInt("CONVERSATION_TOKEN_BUDGET", 0)
+	h.DetectTranslationSource = EnvBool("DETECT_TRANSLATION_SOURCE", false)
+	h.TranslationModel = chatmodels.ChatModel(os.Getenv("TRANSLATION_MODEL"))
+	h.RomanizeTranslations = EnvBool("ROMANIZE_TRANSLATIONS", false)
+	if EnvBool("PROGRESSIVE_RESPONSES", true) {
+		h.ProgressiveResponder = alexa.NewClient()
+	}
+	return h
+}
+
+// EnvBool reads the boolean environment variable name, or def when it is unset
+// or not a boolean.
+func EnvBool(name string, def bool) bool {
+	v, err := strconv.ParseBool(os.Getenv(name))
+	if err != nil {
+		return def
+	}
+	return v
+}
+
+// EnvInt reads the integer environment variable name, or def when it is unset
+// or not an integer.
+func EnvInt(name string, def int) int {
+	v, err := strconv.Atoi(os.Getenv(name))
+	if err != nil {
+		return def
+	}
+	return v
+}
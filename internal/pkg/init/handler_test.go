@@ -0,0 +1,39 @@
+package init
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInitializeHandlerFlags(t *testing.T) {
+	t.Setenv("DETECT_TRANSLATION_SOURCE", "1")
+	t.Setenv("ROMANIZE_TRANSLATIONS", "TRUE")
+	t.Setenv("PROGRESSIVE_RESPONSES", "0")
+	t.Setenv("CONVERSATION_TOKEN_BUDGET", "2000")
+
+	h := InitializeHandler(SetupLogger(), nil, nil, nil)
+	assert.True(t, h.DetectTranslationSource)
+	assert.True(t, h.RomanizeTranslations)
+	assert.Nil(t, h.ProgressiveResponder)
+	assert.Equal(t, 2000, h.ConversationTokenBudget)
+}
+
+func TestInitializeHandlerDefaults(t *testing.T) {
+	t.Setenv("DETECT_TRANSLATION_SOURCE", "")
+	t.Setenv("ROMANIZE_TRANSLATIONS", "")
+	t.Setenv("PROGRESSIVE_RESPONSES", "")
+	t.Setenv("CONVERSATION_TOKEN_BUDGET", "")
+
+	h := InitializeHandler(SetupLogger(), nil, nil, nil)
+	assert.False(t, h.DetectTranslationSource)
+	assert.False(t, h.RomanizeTranslations)
+	assert.NotNil(t, h.ProgressiveResponder)
+	assert.Zero(t, h.ConversationTokenBudget)
+}
+
+func TestEnvBoolFallsBackOnInvalidValue(t *testing.T) {
+	t.Setenv("FLAG", "yes please")
+	assert.True(t, EnvBool("FLAG", true))
+	assert.False(t, EnvBool("FLAG", false))
+}
@@ -4,23 +4,70 @@ import (
 	"context"
 	"log/slog"
 	"os"
+	"strconv"
+	"strings"
 
 	otelsetup "github.com/jackmcguire1/alexa-chatgpt/internal/otel"
+	"github.com/jackmcguire1/alexa-chatgpt/internal/pkg/redact"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
 	"go.opentelemetry.io/otel/sdk/trace"
 )
 
-// SetupLogger creates and returns a JSON logger
+// SetupLogger creates and returns a JSON logger that masks personal data and
+// secrets, and truncates prompts to LOG_MAX_TEXT_LENGTH characters. With
+// LOG_DEBUG=true it logs debug messages and everything unmasked.
 func SetupLogger() *slog.Logger {
-	jsonLogH := slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo})
-	return slog.New(jsonLogH)
+	redactor := Redactor()
+	level := slog.LevelInfo
+	if redactor.Debug {
+		level = slog.LevelDebug
+	}
+	jsonLogH := slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: level})
+	return slog.New(redact.NewHandler(jsonLogH, redactor))
+}
+
+// Redactor returns the redactor for logs and spans configured by
+// LOG_MAX_TEXT_LENGTH and LOG_DEBUG.
+func Redactor() *redact.Redactor {
+	redactor := &redact.Redactor{}
+	redactor.MaxTextLength, _ = strconv.Atoi(os.Getenv("LOG_MAX_TEXT_LENGTH"))
+	redactor.Debug, _ = strconv.ParseBool(os.Getenv("LOG_DEBUG"))
+	return redactor
 }
 
-// SetupTracing initializes and returns the OpenTelemetry tracer
+// SetupTracing initializes the OpenTelemetry tracer provider exporting with
+// OTEL_TRACES_EXPORTER (xray, otlp, stdout or none) and propagating with the
+// comma separated OTEL_PROPAGATORS (xray, tracecontext and baggage, xray by
+// default). Without an exporter, spans are sent to X-Ray on Lambda and not
+// exported elsewhere. Span attributes are masked like logs. When tracing
+// can't be set up spans are still created but not exported.
 func SetupTracing(ctx context.Context, logger *slog.Logger) *trace.TracerProvider {
-	tracer, err := otelsetup.SetupXrayOtel(ctx)
+	exporter := os.Getenv("OTEL_TRACES_EXPORTER")
+	if exporter == "" && os.Getenv("AWS_LAMBDA_FUNCTION_NAME") != "" {
+		exporter = otelsetup.TracesExporterXRay
+	}
+	propagators := strings.Split(os.Getenv("OTEL_PROPAGATORS"), ",")
+
+	tp, err := otelsetup.SetupTracing(ctx, exporter, Redactor(), propagators...)
+	if err != nil {
+		logger.With("error", err).Error("failed to setup tracer, traces will not be exported")
+		tp, err = otelsetup.SetupTracing(ctx, otelsetup.TracesExporterNone, nil)
+		if err != nil {
+			logger.With("error", err).Error("failed to setup tracer")
+			return trace.NewTracerProvider()
+		}
+	}
+	return tp
+}
+
+// SetupMetrics initializes the OpenTelemetry meter provider exporting with
+// OTEL_METRICS_EXPORTER (otlp, stdout or none, the default). When the exporter
+// can't be set up metrics are still recorded but not exported.
+func SetupMetrics(ctx context.Context, logger *slog.Logger) *sdkmetric.MeterProvider {
+	mp, err := otelsetup.SetupMetrics(ctx, os.Getenv("OTEL_METRICS_EXPORTER"))
 	if err != nil {
-		logger.With("error", err).Error("failed to setup tracer")
-		panic(err)
+		logger.With("error", err).Error("failed to setup metrics exporter, metrics will not be exported")
+		return otelsetup.NewMeterProvider()
 	}
-	return tracer
+	return mp
 }
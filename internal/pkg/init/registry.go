@@ -0,0 +1,64 @@
+package init
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/jackmcguire1/alexa-chatgpt/internal/dom/chatmodels"
+)
+
+// LoadModelRegistry replaces the embedded model registry with the file named
+// by MODEL_REGISTRY, either a local path or an s3://bucket/key URI. It panics
+// when the file cannot be read or fails validation, so a bad registry stops
+// the lambda at startup rather than on the first prompt.
+func LoadModelRegistry() {
+	location := os.Getenv("MODEL_REGISTRY")
+	if location == "" {
+		return
+	}
+
+	data, err := readRegistry(context.Background(), location)
+	if err != nil {
+		panic(fmt.Sprintf("failed to read model registry %s: %v", location, err))
+	}
+
+	configs, err := chatmodels.LoadModelConfigs(data)
+	if err != nil {
+		panic(fmt.Sprintf("invalid model registry %s: %v", location, err))
+	}
+	chatmodels.UseModelConfigs(configs)
+}
+
+func readRegistry(ctx context.Context, location string) ([]byte, error) {
+	bucketKey, ok := strings.CutPrefix(location, "s3://")
+	if !ok {
+		return os.ReadFile(location)
+	}
+
+	bucket, key, ok := strings.Cut(bucketKey, "/")
+	if !ok || bucket == "" || key == "" {
+		return nil, fmt.Errorf("expected s3://bucket/key")
+	}
+
+	cfg, err := config.LoadDefaultConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	obj, err := s3.NewFromConfig(cfg).GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer obj.Body.Close()
+
+	return io.ReadAll(obj.Body)
+}
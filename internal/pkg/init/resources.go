@@ -1,13 +1,18 @@
 package init
 
 import (
+	"fmt"
 	"os"
 
 	"github.com/jackmcguire1/alexa-chatgpt/internal/dom/chatmodels"
+	"github.com/jackmcguire1/alexa-chatgpt/internal/pkg/userstate"
 )
 
-// InitializeResources creates and configures all AI provider clients based on environment variables.
+// InitializeResources loads the model registry and creates and configures all
+// AI provider clients based on environment variables.
 func InitializeResources() *chatmodels.Resources {
+	LoadModelRegistry()
+
 	resources := &chatmodels.Resources{}
 
 	resources.BedrockAPI = chatmodels.NewBedrockApiClient()
@@ -20,6 +25,25 @@ func InitializeResources() *chatmodels.Resources {
 	return resources
 }
 
+// InitializeUserStateStore selects where per-user state is kept: DynamoDB when
+// USER_STATE_TABLE is set, a local bbolt file when USER_STATE_FILE is set and
+// process memory otherwise.
+func InitializeUserStateStore() userstate.UserStateStore {
+	if table := os.Getenv("USER_STATE_TABLE"); table != "" {
+		return userstate.NewDynamoStore(table)
+	}
+
+	if path := os.Getenv("USER_STATE_FILE"); path != "" {
+		store, err := userstate.NewBoltStore(path)
+		if err != nil {
+			panic(fmt.Sprintf("failed to open user state file: %v", err))
+		}
+		return store
+	}
+
+	return userstate.NewMemoryStore()
+}
+
 // GetDefaultChatModel returns the default chat model.
 func GetDefaultChatModel() chatmodels.ChatModel {
 	return chatmodels.CHAT_MODEL_SONNET
@@ -0,0 +1,263 @@
+package queue
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jackmcguire1/alexa-chatgpt/internal/pkg/utils"
+	bolt "go.etcd.io/bbolt"
+)
+
+var messagesBucket = []byte("messages")
+
+// BoltQueue is a PullPoll kept in a local bbolt database file, so queued
+// messages survive a restart. Messages are received in the order they were
+// pushed, and a received message that isn't acknowledged within
+// VisibilityTimeout is delivered again. Each queue needs its own file, which
+// only one process can have open.
+type BoltQueue struct {
+	VisibilityTimeout time.Duration
+
+	db *bolt.DB
+
+	mu sync.Mutex
+	// visible is closed and replaced whenever a message becomes visible,
+	// waking long polls.
+	visible chan struct{}
+}
+
+// boltRecord is a message as it is stored, keyed by its push sequence.
+type boltRecord struct {
+	visibleAt    time.Time
+	sentAt       time.Time
+	receiveCount uint32
+	body         []byte
+}
+
+const boltRecordHeader = 20
+
+func (r boltRecord) encode() []byte {
+	data := make([]byte, 0, boltRecordHeader+len(r.body))
+	data = binary.BigEndian.AppendUint64(data, uint64(r.visibleAt.UnixNano()))
+	data = binary.BigEndian.AppendUint64(data, uint64(r.sentAt.UnixNano()))
+	data = binary.BigEndian.AppendUint32(data, r.receiveCount)
+	return append(data, r.body...)
+}
+
+func decodeBoltRecord(data []byte) (boltRecord, error) {
+	if len(data) < boltRecordHeader {
+		return boltRecord{}, fmt.Errorf("queue: record of %d bytes is too short", len(data))
+	}
+	return boltRecord{
+		visibleAt:    time.Unix(0, int64(binary.BigEndian.Uint64(data[0:8]))),
+		sentAt:       time.Unix(0, int64(binary.BigEndian.Uint64(data[8:16]))),
+		receiveCount: binary.BigEndian.Uint32(data[16:20]),
+		body:         append([]byte(nil), data[boltRecordHeader:]...),
+	}, nil
+}
+
+func NewBoltQueue(path string) (*BoltQueue, error) {
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("queue: open %s: %w", path, err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(messagesBucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("queue: create bucket: %w", err)
+	}
+
+	return &BoltQueue{db: db, visible: make(chan struct{})}, nil
+}
+
+func (q *BoltQueue) PushMessage(_ context.Context, i any) error {
+	err := q.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(messagesBucket)
+		seq, err := bucket.NextSequence()
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+		record := boltRecord{visibleAt: now, sentAt: now, body: []byte(utils.ToJSON(i))}
+		return bucket.Put(binary.BigEndian.AppendUint64(nil, seq), record.encode())
+	})
+	if err != nil {
+		return err
+	}
+	q.wake()
+	return nil
+}
+
+func (q *BoltQueue) PullMessage(ctx context.Context, wait int) ([]byte, error) {
+	msg, err := q.ReceiveMessage(ctx, wait)
+	if err != nil || msg == nil {
+		return nil, err
+	}
+	if err := q.Ack(ctx, msg); err != nil {
+		return nil, err
+	}
+	return msg.Body, nil
+}
+
+func (q *BoltQueue) ReceiveMessage(ctx context.Context, wait int) (*Message, error) {
+	deadline := time.Now().Add(time.Duration(wait) * time.Second)
+
+	for {
+		// take the channel before looking, so a push in between still wakes us
+		q.mu.Lock()
+		visible := q.visible
+		q.mu.Unlock()
+
+		msg, nextVisible, err := q.receive()
+		if err != nil || msg != nil || wait <= 0 {
+			return msg, err
+		}
+
+		until := deadline
+		if !nextVisible.IsZero() && nextVisible.Before(until) {
+			until = nextVisible
+		}
+		timer := time.NewTimer(time.Until(until))
+		select {
+		case <-visible:
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		}
+		timer.Stop()
+
+		if !time.Now().Before(deadline) {
+			msg, _, err := q.receive()
+			return msg, err
+		}
+	}
+}
+
+// receive hides and returns the oldest visible message. When there is none,
+// it returns when the next hidden message becomes visible, if there is one.
+func (q *BoltQueue) receive() (msg *Message, nextVisible time.Time, err error) {
+	timeout := q.VisibilityTimeout
+	if timeout <= 0 {
+		timeout = DefaultVisibilityTimeout
+	}
+
+	err = q.db.Update(func(tx *bolt.Tx) error {
+		now := time.Now()
+		cursor := tx.Bucket(messagesBucket).Cursor()
+		for key, value := cursor.First(); key != nil; key, value = cursor.Next() {
+			record, err := decodeBoltRecord(value)
+			if err != nil {
+				return err
+			}
+			if record.visibleAt.After(now) {
+				if nextVisible.IsZero() || record.visibleAt.Before(nextVisible) {
+					nextVisible = record.visibleAt
+				}
+				continue
+			}
+
+			record.visibleAt = now.Add(timeout)
+			record.receiveCount++
+			if err := tx.Bucket(messagesBucket).Put(key, record.encode()); err != nil {
+				return err
+			}
+			msg = &Message{
+				Body:    record.body,
+				Receipt: boltReceipt(key, record.receiveCount),
+				Attributes: map[string]string{
+					SentTimestampAttribute:           strconv.FormatInt(record.sentAt.UnixMilli(), 10),
+					ApproximateReceiveCountAttribute: strconv.Itoa(int(record.receiveCount)),
+				},
+				ReceiveCount: int(record.receiveCount),
+			}
+			return nil
+		}
+		return nil
+	})
+	if msg != nil {
+		nextVisible = time.Time{}
+	}
+	return
+}
+
+// boltReceipt identifies one receipt of the message stored under key, so a
+// receipt from before the message was redelivered can't settle it.
+func boltReceipt(key []byte, receiveCount uint32) string {
+	return hex.EncodeToString(key) + "-" + strconv.Itoa(int(receiveCount))
+}
+
+// inFlight runs fn with the record msg was received as, if it is still in
+// flight under msg's receipt.
+func (q *BoltQueue) inFlight(msg *Message, fn func(bucket *bolt.Bucket, key []byte, record boltRecord) error) error {
+	hexKey, _, _ := strings.Cut(msg.Receipt, "-")
+	key, err := hex.DecodeString(hexKey)
+	if err != nil {
+		return InvalidReceiptErr
+	}
+
+	return q.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(messagesBucket)
+		value := bucket.Get(key)
+		if value == nil {
+			return InvalidReceiptErr
+		}
+		record, err := decodeBoltRecord(value)
+		if err != nil {
+			return err
+		}
+		if boltReceipt(key, record.receiveCount) != msg.Receipt || !record.visibleAt.After(time.Now()) {
+			return InvalidReceiptErr
+		}
+		return fn(bucket, key, record)
+	})
+}
+
+func (q *BoltQueue) Ack(_ context.Context, msg *Message) error {
+	return q.inFlight(msg, func(bucket *bolt.Bucket, key []byte, _ boltRecord) error {
+		return bucket.Delete(key)
+	})
+}
+
+func (q *BoltQueue) Nack(_ context.Context, msg *Message) error {
+	err := q.inFlight(msg, func(bucket *bolt.Bucket, key []byte, record boltRecord) error {
+		record.visibleAt = time.Now()
+		return bucket.Put(key, record.encode())
+	})
+	if err != nil {
+		return err
+	}
+	q.wake()
+	return nil
+}
+
+func (q *BoltQueue) wake() {
+	q.mu.Lock()
+	close(q.visible)
+	q.visible = make(chan struct{})
+	q.mu.Unlock()
+}
+
+func (q *BoltQueue) Purge(context.Context) error {
+	return q.db.Update(func(tx *bolt.Tx) error {
+		if err := tx.DeleteBucket(messagesBucket); err != nil {
+			return err
+		}
+		_, err := tx.CreateBucket(messagesBucket)
+		return err
+	})
+}
+
+func (q *BoltQueue) Close() error {
+	return q.db.Close()
+}
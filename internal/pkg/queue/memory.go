@@ -0,0 +1,190 @@
+package queue
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackmcguire1/alexa-chatgpt/internal/pkg/utils"
+)
+
+// DefaultMemoryQueueSize is how many messages a MemoryQueue buffers when
+// NewMemoryQueue is given no size.
+const DefaultMemoryQueueSize = 1000
+
+// DefaultVisibilityTimeout is how long a received message stays hidden, as in
+// SQS, when a queue doesn't set its own timeout.
+const DefaultVisibilityTimeout = 30 * time.Second
+
+var QueueFullErr = fmt.Errorf("queue is full")
+
+// MemoryQueue is an in-process PullPoll for running the skill without SQS.
+// Like SQS it long-polls, waiting up to wait seconds for a message to arrive,
+// and a received message that isn't acknowledged within VisibilityTimeout is
+// delivered again.
+type MemoryQueue struct {
+	VisibilityTimeout time.Duration
+
+	messages chan *memoryMessage
+
+	mu       sync.Mutex
+	inFlight map[string]*inFlightMessage
+}
+
+type memoryMessage struct {
+	body         []byte
+	sentAt       time.Time
+	receiveCount int
+}
+
+type inFlightMessage struct {
+	message *memoryMessage
+	timer   *time.Timer
+}
+
+func NewMemoryQueue(size int) *MemoryQueue {
+	if size <= 0 {
+		size = DefaultMemoryQueueSize
+	}
+	return &MemoryQueue{
+		messages: make(chan *memoryMessage, size),
+		inFlight: map[string]*inFlightMessage{},
+	}
+}
+
+func (q *MemoryQueue) PushMessage(ctx context.Context, i any) error {
+	select {
+	case q.messages <- &memoryMessage{body: []byte(utils.ToJSON(i)), sentAt: time.Now()}:
+		return nil
+	default:
+		return QueueFullErr
+	}
+}
+
+func (q *MemoryQueue) PullMessage(ctx context.Context, wait int) ([]byte, error) {
+	msg, err := q.ReceiveMessage(ctx, wait)
+	if err != nil || msg == nil {
+		return nil, err
+	}
+	if err := q.Ack(ctx, msg); err != nil {
+		return nil, err
+	}
+	return msg.Body, nil
+}
+
+func (q *MemoryQueue) ReceiveMessage(ctx context.Context, wait int) (*Message, error) {
+	m, err := q.receive(ctx, wait)
+	if err != nil || m == nil {
+		return nil, err
+	}
+
+	m.receiveCount++
+	receipt := uuid.New().String()
+	timeout := q.VisibilityTimeout
+	if timeout <= 0 {
+		timeout = DefaultVisibilityTimeout
+	}
+	q.mu.Lock()
+	q.inFlight[receipt] = &inFlightMessage{
+		message: m,
+		timer: time.AfterFunc(timeout, func() {
+			if m := q.settle(receipt); m != nil {
+				q.requeue(m)
+			}
+		}),
+	}
+	q.mu.Unlock()
+
+	return &Message{
+		Body:    m.body,
+		Receipt: receipt,
+		Attributes: map[string]string{
+			SentTimestampAttribute:           strconv.FormatInt(m.sentAt.UnixMilli(), 10),
+			ApproximateReceiveCountAttribute: strconv.Itoa(m.receiveCount),
+		},
+		ReceiveCount: m.receiveCount,
+	}, nil
+}
+
+func (q *MemoryQueue) receive(ctx context.Context, wait int) (*memoryMessage, error) {
+	select {
+	case m := <-q.messages:
+		return m, nil
+	default:
+	}
+	if wait <= 0 {
+		return nil, nil
+	}
+
+	timer := time.NewTimer(time.Duration(wait) * time.Second)
+	defer timer.Stop()
+
+	select {
+	case m := <-q.messages:
+		return m, nil
+	case <-timer.C:
+		return nil, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (q *MemoryQueue) Ack(_ context.Context, msg *Message) error {
+	if q.settle(msg.Receipt) == nil {
+		return InvalidReceiptErr
+	}
+	return nil
+}
+
+func (q *MemoryQueue) Nack(_ context.Context, msg *Message) error {
+	m := q.settle(msg.Receipt)
+	if m == nil {
+		return InvalidReceiptErr
+	}
+	q.requeue(m)
+	return nil
+}
+
+// settle takes the message received with receipt out of flight, returning
+// nil when it is no longer in flight.
+func (q *MemoryQueue) settle(receipt string) *memoryMessage {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	inFlight, ok := q.inFlight[receipt]
+	if !ok {
+		return nil
+	}
+	inFlight.timer.Stop()
+	delete(q.inFlight, receipt)
+	return inFlight.message
+}
+
+// requeue makes m visible again, waiting for room when the queue is full
+// rather than losing it.
+func (q *MemoryQueue) requeue(m *memoryMessage) {
+	select {
+	case q.messages <- m:
+	default:
+		go func() { q.messages <- m }()
+	}
+}
+
+func (q *MemoryQueue) Purge(context.Context) error {
+	q.mu.Lock()
+	for receipt, inFlight := range q.inFlight {
+		inFlight.timer.Stop()
+		delete(q.inFlight, receipt)
+	}
+	q.mu.Unlock()
+
+	for {
+		select {
+		case <-q.messages:
+		default:
+			return nil
+		}
+	}
+}
@@ -29,3 +29,27 @@ func (q *MockQueue) Purge(ctx context.Context) error {
 	args := q.Called(ctx)
 	return args.Error(0)
 }
+
+// ReceiveMessage returns the *Message the call was set up to return, or
+// wraps the []byte it was set up with in a first receipt of a message.
+func (q *MockQueue) ReceiveMessage(ctx context.Context, wait int) (*Message, error) {
+	args := q.Called(ctx, wait)
+
+	switch res := args.Get(0).(type) {
+	case *Message:
+		return res, args.Error(1)
+	case []byte:
+		return &Message{Body: res, Receipt: "receipt", ReceiveCount: 1}, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (q *MockQueue) Ack(ctx context.Context, msg *Message) error {
+	args := q.Called(ctx, msg)
+	return args.Error(0)
+}
+
+func (q *MockQueue) Nack(ctx context.Context, msg *Message) error {
+	args := q.Called(ctx, msg)
+	return args.Error(0)
+}
@@ -4,9 +4,12 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"strconv"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
 	"github.com/jackmcguire1/alexa-chatgpt/internal/pkg/utils"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
@@ -14,12 +17,44 @@ import (
 
 var tracer = otel.Tracer("sqs")
 
-var EmptyMessageErr = fmt.Errorf("no messages found")
+var (
+	EmptyMessageErr   = fmt.Errorf("no messages found")
+	InvalidReceiptErr = fmt.Errorf("message receipt is no longer valid")
+)
+
+// Message attributes set by every PullPoll, named as SQS names them.
+const (
+	SentTimestampAttribute           = "SentTimestamp"
+	ApproximateReceiveCountAttribute = "ApproximateReceiveCount"
+)
+
+// Message is a message received from a queue. It stays hidden from other
+// receivers until it is acknowledged, which deletes it, or until it is
+// negatively acknowledged or its visibility timeout ends, which makes it
+// visible again.
+type Message struct {
+	Body []byte
+	// Receipt identifies this receipt of the message to Ack and Nack.
+	Receipt    string
+	Attributes map[string]string
+	// ReceiveCount is how many times the message has been received,
+	// including this time.
+	ReceiveCount int
+}
 
+// PullPoll is a queue of JSON messages. PullMessage acknowledges a message as
+// soon as it is received, so it is lost if the caller then fails to handle it;
+// callers that can't afford that use ReceiveMessage and acknowledge the message
+// once it is handled.
 type PullPoll interface {
 	Purge(context.Context) error
 	PullMessage(context.Context, int) ([]byte, error)
 	PushMessage(context.Context, any) error
+	// ReceiveMessage long-polls for up to wait seconds for a message,
+	// returning nil when none arrives.
+	ReceiveMessage(ctx context.Context, wait int) (*Message, error)
+	Ack(context.Context, *Message) error
+	Nack(context.Context, *Message) error
 }
 
 type Queue struct {
@@ -60,14 +95,26 @@ func (q *Queue) PushMessage(ctx context.Context, i any) error {
 }
 
 func (q *Queue) PullMessage(ctx context.Context, wait int) ([]byte, error) {
-	ctx, span := tracer.Start(ctx, "PullMessage")
+	msg, err := q.ReceiveMessage(ctx, wait)
+	if err != nil || msg == nil {
+		return nil, err
+	}
+	if err := q.Ack(ctx, msg); err != nil {
+		return nil, err
+	}
+	return msg.Body, nil
+}
+
+func (q *Queue) ReceiveMessage(ctx context.Context, wait int) (*Message, error) {
+	ctx, span := tracer.Start(ctx, "ReceiveMessage")
 	defer span.End()
 	span.SetAttributes(attribute.String("queue.uri", q.queueUri))
 
 	resp, err := q.client.ReceiveMessage(ctx, &sqs.ReceiveMessageInput{
-		QueueUrl:            &q.queueUri,
-		MaxNumberOfMessages: 1,
-		WaitTimeSeconds:     int32(wait),
+		QueueUrl:                    &q.queueUri,
+		MaxNumberOfMessages:         1,
+		WaitTimeSeconds:             int32(wait),
+		MessageSystemAttributeNames: []types.MessageSystemAttributeName{types.MessageSystemAttributeNameAll},
 	})
 	if err != nil {
 		span.RecordError(err)
@@ -78,24 +125,56 @@ func (q *Queue) PullMessage(ctx context.Context, wait int) ([]byte, error) {
 		return nil, nil
 	}
 
-	// Start a child span for message deletion
-	deleteCtx, deleteSpan := tracer.Start(ctx, "DeleteMessage")
-	defer deleteSpan.End()
+	msg := resp.Messages[0]
+	receiveCount, _ := strconv.Atoi(msg.Attributes[ApproximateReceiveCountAttribute])
+	span.SetAttributes(attribute.Int("receive-count", receiveCount))
+	return &Message{
+		Body:         []byte(aws.ToString(msg.Body)),
+		Receipt:      aws.ToString(msg.ReceiptHandle),
+		Attributes:   msg.Attributes,
+		ReceiveCount: receiveCount,
+	}, nil
+}
+
+// Ack deletes msg from the queue.
+func (q *Queue) Ack(ctx context.Context, msg *Message) error {
+	ctx, span := tracer.Start(ctx, "DeleteMessage")
+	defer span.End()
 	span.SetAttributes(
 		attribute.String("queue.uri", q.queueUri),
-		attribute.String("message-receipt", *resp.Messages[0].ReceiptHandle),
+		attribute.String("message-receipt", msg.Receipt),
 	)
 
-	_, err = q.client.DeleteMessage(deleteCtx, &sqs.DeleteMessageInput{
+	_, err := q.client.DeleteMessage(ctx, &sqs.DeleteMessageInput{
 		QueueUrl:      &q.queueUri,
-		ReceiptHandle: resp.Messages[0].ReceiptHandle,
+		ReceiptHandle: &msg.Receipt,
 	})
 	if err != nil {
-		deleteSpan.RecordError(err)
-		return nil, err
+		span.RecordError(err)
+		return err
 	}
+	return nil
+}
 
-	return []byte(*resp.Messages[0].Body), nil
+// Nack makes msg visible to receivers again straight away.
+func (q *Queue) Nack(ctx context.Context, msg *Message) error {
+	ctx, span := tracer.Start(ctx, "ChangeMessageVisibility")
+	defer span.End()
+	span.SetAttributes(
+		attribute.String("queue.uri", q.queueUri),
+		attribute.String("message-receipt", msg.Receipt),
+	)
+
+	_, err := q.client.ChangeMessageVisibility(ctx, &sqs.ChangeMessageVisibilityInput{
+		QueueUrl:          &q.queueUri,
+		ReceiptHandle:     &msg.Receipt,
+		VisibilityTimeout: 0,
+	})
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+	return nil
 }
 
 func (q *Queue) Purge(ctx context.Context) error {
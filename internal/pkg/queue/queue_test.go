@@ -0,0 +1,91 @@
+package queue_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/jackmcguire1/alexa-chatgpt/internal/pkg/queue"
+	"github.com/jackmcguire1/alexa-chatgpt/internal/pkg/queue/queuetest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryQueue(t *testing.T) {
+	queuetest.Run(t, func(t *testing.T) queue.PullPoll {
+		q := queue.NewMemoryQueue(0)
+		q.VisibilityTimeout = queuetest.VisibilityTimeout
+		return q
+	})
+}
+
+func TestBoltQueue(t *testing.T) {
+	queuetest.Run(t, func(t *testing.T) queue.PullPoll {
+		q, err := queue.NewBoltQueue(filepath.Join(t.TempDir(), "queue.db"))
+		require.NoError(t, err)
+		q.VisibilityTimeout = queuetest.VisibilityTimeout
+		t.Cleanup(func() { q.Close() })
+		return q
+	})
+}
+
+func TestBoltQueueSurvivesReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "queue.db")
+	ctx := context.Background()
+
+	q, err := queue.NewBoltQueue(path)
+	require.NoError(t, err)
+	require.NoError(t, q.PushMessage(ctx, map[string]string{"prompt": "first"}))
+	require.NoError(t, q.PushMessage(ctx, map[string]string{"prompt": "second"}))
+	require.NoError(t, q.Close())
+
+	q, err = queue.NewBoltQueue(path)
+	require.NoError(t, err)
+	defer q.Close()
+
+	for _, want := range []string{`{"prompt":"first"}`, `{"prompt":"second"}`} {
+		data, err := q.PullMessage(ctx, 0)
+		require.NoError(t, err)
+		assert.JSONEq(t, want, string(data))
+	}
+}
+
+// TestSQSQueue runs the suite against a local SQS stand-in, such as ElasticMQ
+// started with docker run -p 9324:9324 softwaremill/elasticmq-native and
+// ELASTICMQ_ENDPOINT=http://localhost:9324.
+func TestSQSQueue(t *testing.T) {
+	endpoint := os.Getenv("ELASTICMQ_ENDPOINT")
+	if endpoint == "" {
+		t.Skip("ELASTICMQ_ENDPOINT is not set")
+	}
+	t.Setenv("AWS_ENDPOINT_URL_SQS", endpoint)
+	if os.Getenv("AWS_ACCESS_KEY_ID") == "" {
+		t.Setenv("AWS_ACCESS_KEY_ID", "x")
+		t.Setenv("AWS_SECRET_ACCESS_KEY", "x")
+	}
+
+	cfg, err := config.LoadDefaultConfig(context.Background(), config.WithRegion("us-east-1"))
+	require.NoError(t, err)
+	client := sqs.NewFromConfig(cfg)
+
+	queuetest.Run(t, func(t *testing.T) queue.PullPoll {
+		created, err := client.CreateQueue(context.Background(), &sqs.CreateQueueInput{
+			QueueName: aws.String(fmt.Sprintf("conformance-%d", time.Now().UnixNano())),
+			Attributes: map[string]string{
+				"VisibilityTimeout": strconv.Itoa(int(queuetest.VisibilityTimeout.Seconds())),
+			},
+		})
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			client.DeleteQueue(context.Background(), &sqs.DeleteQueueInput{QueueUrl: created.QueueUrl})
+		})
+		return queue.NewQueue(*created.QueueUrl)
+	})
+}
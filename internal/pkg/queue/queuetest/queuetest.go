@@ -0,0 +1,232 @@
+// Package queuetest is a conformance suite for queue.PullPoll
+// implementations, so each one behaves as the api handler and worker expect
+// from SQS.
+package queuetest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jackmcguire1/alexa-chatgpt/internal/pkg/queue"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type message struct {
+	ID   int    `json:"id"`
+	Body string `json:"body"`
+}
+
+// VisibilityTimeout is how long the queues under test must keep a received
+// message hidden before delivering it again.
+const VisibilityTimeout = time.Second
+
+// Run runs the suite, calling newQueue for an empty queue in every test.
+func Run(t *testing.T, newQueue func(t *testing.T) queue.PullPoll) {
+	t.Run("empty queue", func(t *testing.T) {
+		q := newQueue(t)
+
+		start := time.Now()
+		data, err := q.PullMessage(context.Background(), 0)
+		require.NoError(t, err)
+		assert.Empty(t, data)
+		assert.Less(t, time.Since(start), time.Second, "a pull without wait returns at once")
+	})
+
+	t.Run("push and pull", func(t *testing.T) {
+		q := newQueue(t)
+		ctx := context.Background()
+
+		require.NoError(t, q.PushMessage(ctx, message{ID: 1, Body: "hello"}))
+		assert.Equal(t, message{ID: 1, Body: "hello"}, pull(t, q, 1))
+
+		data, err := q.PullMessage(ctx, 0)
+		require.NoError(t, err)
+		assert.Empty(t, data, "pulled messages are gone")
+	})
+
+	t.Run("every message is pulled once", func(t *testing.T) {
+		q := newQueue(t)
+		ctx := context.Background()
+
+		const messages = 10
+		for i := range messages {
+			require.NoError(t, q.PushMessage(ctx, message{ID: i, Body: fmt.Sprint("message ", i)}))
+		}
+
+		var (
+			mu     sync.Mutex
+			pulled = map[int]int{}
+			wg     sync.WaitGroup
+		)
+		for range 3 {
+			wg.Go(func() {
+				for {
+					data, err := q.PullMessage(ctx, 1)
+					if !assert.NoError(t, err) || len(data) == 0 {
+						return
+					}
+					var m message
+					assert.NoError(t, json.Unmarshal(data, &m))
+					mu.Lock()
+					pulled[m.ID]++
+					mu.Unlock()
+				}
+			})
+		}
+		wg.Wait()
+
+		require.Len(t, pulled, messages)
+		for id, times := range pulled {
+			assert.Equal(t, 1, times, "message %d", id)
+		}
+	})
+
+	t.Run("long poll returns when a message arrives", func(t *testing.T) {
+		q := newQueue(t)
+		ctx := context.Background()
+
+		go func() {
+			time.Sleep(200 * time.Millisecond)
+			assert.NoError(t, q.PushMessage(ctx, message{ID: 2, Body: "late"}))
+		}()
+
+		start := time.Now()
+		assert.Equal(t, message{ID: 2, Body: "late"}, pull(t, q, 5))
+		assert.Less(t, time.Since(start), 4*time.Second)
+	})
+
+	t.Run("long poll honours wait", func(t *testing.T) {
+		q := newQueue(t)
+
+		start := time.Now()
+		data, err := q.PullMessage(context.Background(), 1)
+		require.NoError(t, err)
+		assert.Empty(t, data)
+		assert.GreaterOrEqual(t, time.Since(start), 900*time.Millisecond)
+		assert.Less(t, time.Since(start), 5*time.Second)
+	})
+
+	t.Run("long poll stops with its context", func(t *testing.T) {
+		q := newQueue(t)
+		ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+		defer cancel()
+
+		start := time.Now()
+		data, err := q.PullMessage(ctx, 5)
+		assert.Error(t, err)
+		assert.Empty(t, data)
+		assert.Less(t, time.Since(start), 4*time.Second)
+	})
+
+	t.Run("receive and ack", func(t *testing.T) {
+		q := newQueue(t)
+		ctx := context.Background()
+
+		require.NoError(t, q.PushMessage(ctx, message{ID: 5, Body: "ack me"}))
+		msg := receive(t, q, 1)
+		assert.Equal(t, message{ID: 5, Body: "ack me"}, decode(t, msg))
+		assert.NotEmpty(t, msg.Receipt)
+		assert.Equal(t, 1, msg.ReceiveCount)
+		assert.Equal(t, "1", msg.Attributes[queue.ApproximateReceiveCountAttribute])
+		assert.NotEmpty(t, msg.Attributes[queue.SentTimestampAttribute])
+		require.NoError(t, q.Ack(ctx, msg))
+
+		time.Sleep(VisibilityTimeout + 200*time.Millisecond)
+		again, err := q.ReceiveMessage(ctx, 0)
+		require.NoError(t, err)
+		assert.Nil(t, again, "acknowledged messages are gone")
+	})
+
+	t.Run("received messages are hidden", func(t *testing.T) {
+		q := newQueue(t)
+		ctx := context.Background()
+
+		require.NoError(t, q.PushMessage(ctx, message{ID: 6, Body: "in flight"}))
+		receive(t, q, 1)
+
+		again, err := q.ReceiveMessage(ctx, 0)
+		require.NoError(t, err)
+		assert.Nil(t, again, "a message in flight isn't received twice")
+	})
+
+	t.Run("unacknowledged message is delivered again", func(t *testing.T) {
+		q := newQueue(t)
+		ctx := context.Background()
+
+		require.NoError(t, q.PushMessage(ctx, message{ID: 7, Body: "lost receiver"}))
+		first := receive(t, q, 1)
+
+		start := time.Now()
+		again := receive(t, q, 5)
+		assert.GreaterOrEqual(t, time.Since(start), VisibilityTimeout/2, "redelivered only once the visibility timeout ends")
+		assert.Equal(t, message{ID: 7, Body: "lost receiver"}, decode(t, again))
+		assert.Equal(t, 2, again.ReceiveCount)
+		assert.NotEqual(t, first.Receipt, again.Receipt)
+		require.NoError(t, q.Ack(ctx, again))
+	})
+
+	t.Run("nack makes the message visible again", func(t *testing.T) {
+		q := newQueue(t)
+		ctx := context.Background()
+
+		require.NoError(t, q.PushMessage(ctx, message{ID: 8, Body: "try again"}))
+		require.NoError(t, q.Nack(ctx, receive(t, q, 1)))
+
+		start := time.Now()
+		again := receive(t, q, 1)
+		assert.Less(t, time.Since(start), VisibilityTimeout, "redelivered before the visibility timeout ends")
+		assert.Equal(t, message{ID: 8, Body: "try again"}, decode(t, again))
+		assert.Equal(t, 2, again.ReceiveCount)
+		require.NoError(t, q.Ack(ctx, again))
+	})
+
+	t.Run("purge", func(t *testing.T) {
+		q := newQueue(t)
+		ctx := context.Background()
+
+		for i := range 3 {
+			require.NoError(t, q.PushMessage(ctx, message{ID: i}))
+		}
+		require.NoError(t, q.Purge(ctx))
+
+		data, err := q.PullMessage(ctx, 1)
+		require.NoError(t, err)
+		assert.Empty(t, data)
+
+		require.NoError(t, q.PushMessage(ctx, message{ID: 4, Body: "after purge"}))
+		assert.Equal(t, message{ID: 4, Body: "after purge"}, pull(t, q, 1))
+	})
+}
+
+// pull long-polls q for a message that must arrive within wait seconds.
+func pull(t *testing.T, q queue.PullPoll, wait int) message {
+	t.Helper()
+	data, err := q.PullMessage(context.Background(), wait)
+	require.NoError(t, err)
+	require.NotEmpty(t, data, "no message within %ds", wait)
+
+	var m message
+	require.NoError(t, json.Unmarshal(data, &m))
+	return m
+}
+
+// receive long-polls q for a message that must arrive within wait seconds.
+func receive(t *testing.T, q queue.PullPoll, wait int) *queue.Message {
+	t.Helper()
+	msg, err := q.ReceiveMessage(context.Background(), wait)
+	require.NoError(t, err)
+	require.NotNil(t, msg, "no message within %ds", wait)
+	return msg
+}
+
+func decode(t *testing.T, msg *queue.Message) message {
+	t.Helper()
+	var m message
+	require.NoError(t, json.Unmarshal(msg.Body, &m))
+	return m
+}
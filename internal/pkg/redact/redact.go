@@ -0,0 +1,161 @@
+// Package redact masks personal data and secrets in log records and span
+// attributes: tokens are removed, user and device IDs replaced by a short
+// hash that still correlates, emails and phone numbers masked, and prompt
+// and response text truncated.
+package redact
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// DefaultMaxTextLength is how many characters of prompt and response text
+// are kept when a Redactor doesn't set its own length.
+const DefaultMaxTextLength = 64
+
+const secretMask = "[REDACTED]"
+
+var (
+	emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+	phonePattern = regexp.MustCompile(`\+\d[\d\s().-]{6,}\d|\(?\b\d{3}\)?[\s.-]?\d{3}[\s.-]?\d{4}\b`)
+)
+
+// Keys are matched lowercased, without separators, so user-id, user_id and
+// userId are all the same key.
+var (
+	// secretKeys are removed when the key contains one of them.
+	secretKeys = []string{"token", "secret", "password", "apikey", "authorization", "receipt"}
+	idKeys     = map[string]bool{"userid": true, "deviceid": true, "personid": true}
+	textKeys   = map[string]bool{
+		"prompt":       true,
+		"systemprompt": true,
+		"response":     true,
+		"textresponse": true,
+		"content":      true,
+		"text":         true,
+		"ssml":         true,
+		"value":        true,
+		"phrase":       true,
+	}
+)
+
+// Redactor decides how each value is masked by its key. The zero Redactor
+// truncates text to DefaultMaxTextLength characters.
+type Redactor struct {
+	// MaxTextLength is how many characters of prompt and response text
+	// are kept.
+	MaxTextLength int
+	// Debug turns redaction off, so logs and spans hold everything.
+	Debug bool
+}
+
+// String masks value, logged or set as an attribute under key. Values that
+// are JSON documents are masked field by field.
+func (r *Redactor) String(key, value string) string {
+	if r.Debug {
+		return value
+	}
+	switch k := normalize(key); {
+	case isSecret(k):
+		if value == "" {
+			return value
+		}
+		return secretMask
+	case idKeys[k]:
+		return maskID(value)
+	case textKeys[k]:
+		return r.truncate(maskPatterns(value))
+	}
+	if redacted, ok := r.json(value); ok {
+		return redacted
+	}
+	return maskPatterns(value)
+}
+
+func (r *Redactor) truncate(text string) string {
+	length := r.MaxTextLength
+	if length <= 0 {
+		length = DefaultMaxTextLength
+	}
+	if utf8.RuneCountInString(text) <= length {
+		return text
+	}
+	runes := []rune(text)
+	return fmt.Sprintf("%s…(%d chars)", string(runes[:length]), len(runes))
+}
+
+// json masks the fields of value when it is a JSON object or array.
+func (r *Redactor) json(value string) (string, bool) {
+	trimmed := strings.TrimSpace(value)
+	if !strings.HasPrefix(trimmed, "{") && !strings.HasPrefix(trimmed, "[") {
+		return "", false
+	}
+	decoder := json.NewDecoder(strings.NewReader(trimmed))
+	decoder.UseNumber()
+	var doc any
+	if err := decoder.Decode(&doc); err != nil {
+		return "", false
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(r.walk("", doc)); err != nil {
+		return "", false
+	}
+	return strings.TrimSuffix(buf.String(), "\n"), true
+}
+
+// walk masks the strings of a decoded JSON document, each by the key of the
+// object field that holds it.
+func (r *Redactor) walk(key string, doc any) any {
+	switch v := doc.(type) {
+	case map[string]any:
+		for field, value := range v {
+			v[field] = r.walk(field, value)
+		}
+		return v
+	case []any:
+		for i, value := range v {
+			v[i] = r.walk(key, value)
+		}
+		return v
+	case string:
+		return r.String(key, v)
+	}
+	return doc
+}
+
+func normalize(key string) string {
+	return strings.NewReplacer("-", "", "_", "", ".", "", " ", "").Replace(strings.ToLower(key))
+}
+
+func isSecret(key string) bool {
+	for _, secret := range secretKeys {
+		if strings.Contains(key, secret) {
+			return true
+		}
+	}
+	return false
+}
+
+// maskID replaces id with a hash of it, so the same user or device can still
+// be followed across log lines without the ID itself being logged.
+func maskID(id string) string {
+	if id == "" {
+		return id
+	}
+	sum := sha256.Sum256([]byte(id))
+	return "id:" + hex.EncodeToString(sum[:4])
+}
+
+func maskPatterns(text string) string {
+	text = emailPattern.ReplaceAllString(text, "[email]")
+	return phonePattern.ReplaceAllString(text, "[phone]")
+}
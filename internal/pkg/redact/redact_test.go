@@ -0,0 +1,64 @@
+package redact
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestString(t *testing.T) {
+	r := &Redactor{MaxTextLength: 10}
+	tests := []struct {
+		key   string
+		value string
+		want  string
+	}{
+		{key: "apiAccessToken", value: "eyJ0eXAiOiJKV1Qi", want: "[REDACTED]"},
+		{key: "accessToken", value: "Atza|IwEBIA", want: "[REDACTED]"},
+		{key: "message-receipt", value: "AQEBwJnKyrHigUMZj6rYigCgxlaS3SLy0a", want: "[REDACTED]"},
+		{key: "api_key", value: "sk-123", want: "[REDACTED]"},
+		{key: "apiAccessToken", value: "", want: ""},
+		{key: "userId", value: "amzn1.ask.account.AGF7", want: maskID("amzn1.ask.account.AGF7")},
+		{key: "user_id", value: "amzn1.ask.account.AGF7", want: maskID("amzn1.ask.account.AGF7")},
+		{key: "deviceId", value: "amzn1.ask.device.AEX3", want: maskID("amzn1.ask.device.AEX3")},
+		{key: "prompt", value: "why is the sky blue", want: "why is the…(19 chars)"},
+		{key: "system-prompt", value: "short", want: "short"},
+		{key: "prompt", value: "mail a@b.co", want: "mail [emai…(12 chars)"},
+		{key: "content", value: "日本語で答えてくださいね", want: "日本語で答えてくださ…(12 chars)"},
+		{key: "error", value: "no user jane.doe@example.com", want: "no user [email]"},
+		{key: "error", value: "call me on +44 20 7946 0958", want: "call me on [phone]"},
+		{key: "error", value: "call me on (555) 123-4567", want: "call me on [phone]"},
+		{key: "timestamp", value: "2026-10-18T10:00:00Z", want: "2026-10-18T10:00:00Z"},
+		{key: "request-id", value: "amzn1.echo-api.request.1234", want: "amzn1.echo-api.request.1234"},
+		{key: "input-tokens", value: "", want: ""},
+		{
+			key:   "payload",
+			value: `{"user_id":"u1","prompt":"my email is jane@example.com","history":[{"role":"user","content":"hello there, how are you"}],"model":"sonnet","max_tokens":5}`,
+			want:  `{"history":[{"content":"hello ther…(24 chars)","role":"user"}],"max_tokens":5,"model":"sonnet","prompt":"my email i…(19 chars)","user_id":"` + maskID("u1") + `"}`,
+		},
+		{key: "data", value: "{not json", want: "{not json"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key+" "+tt.value, func(t *testing.T) {
+			assert.Equal(t, tt.want, r.String(tt.key, tt.value))
+		})
+	}
+}
+
+func TestMaskIDCorrelates(t *testing.T) {
+	assert.Equal(t, maskID("alice"), maskID("alice"))
+	assert.NotEqual(t, maskID("alice"), maskID("bob"))
+	assert.True(t, strings.HasPrefix(maskID("alice"), "id:"))
+}
+
+func TestDefaultMaxTextLength(t *testing.T) {
+	prompt := strings.Repeat("a", DefaultMaxTextLength+1)
+	assert.Equal(t, strings.Repeat("a", DefaultMaxTextLength)+"…(65 chars)", (&Redactor{}).String("prompt", prompt))
+}
+
+func TestDebugKeepsEverything(t *testing.T) {
+	r := &Redactor{Debug: true}
+	assert.Equal(t, "eyJ0eXAiOiJKV1Qi", r.String("apiAccessToken", "eyJ0eXAiOiJKV1Qi"))
+	assert.Equal(t, "jane@example.com", r.String("prompt", "jane@example.com"))
+}
@@ -0,0 +1,83 @@
+package redact
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+)
+
+type handler struct {
+	next     slog.Handler
+	redactor *Redactor
+}
+
+// NewHandler wraps next so every attribute it handles is masked by r first.
+// In debug mode next is returned as is.
+func NewHandler(next slog.Handler, r *Redactor) slog.Handler {
+	if r.Debug {
+		return next
+	}
+	return &handler{next: next, redactor: r}
+}
+
+func (h *handler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *handler) Handle(ctx context.Context, record slog.Record) error {
+	redacted := slog.NewRecord(record.Time, record.Level, maskPatterns(record.Message), record.PC)
+	record.Attrs(func(attr slog.Attr) bool {
+		redacted.AddAttrs(h.redactor.attr(attr))
+		return true
+	})
+	return h.next.Handle(ctx, redacted)
+}
+
+func (h *handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	redacted := make([]slog.Attr, len(attrs))
+	for i, attr := range attrs {
+		redacted[i] = h.redactor.attr(attr)
+	}
+	return &handler{next: h.next.WithAttrs(redacted), redactor: h.redactor}
+}
+
+func (h *handler) WithGroup(name string) slog.Handler {
+	return &handler{next: h.next.WithGroup(name), redactor: h.redactor}
+}
+
+func (r *Redactor) attr(attr slog.Attr) slog.Attr {
+	value := attr.Value.Resolve()
+	switch value.Kind() {
+	case slog.KindString:
+		return slog.String(attr.Key, r.String(attr.Key, value.String()))
+	case slog.KindGroup:
+		group := value.Group()
+		redacted := make([]any, len(group))
+		for i, member := range group {
+			redacted[i] = r.attr(member)
+		}
+		return slog.Group(attr.Key, redacted...)
+	case slog.KindAny:
+		// errors and structs are masked through their text and JSON forms
+		if err, ok := value.Any().(error); ok {
+			return slog.String(attr.Key, r.String(attr.Key, err.Error()))
+		}
+		data, err := json.Marshal(value.Any())
+		if err != nil {
+			return attr
+		}
+		// masking by key leaves no JSON behind, so the value is logged as text
+		if k := normalize(attr.Key); isSecret(k) || idKeys[k] || textKeys[k] {
+			return slog.String(attr.Key, r.String(attr.Key, string(data)))
+		}
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.UseNumber()
+		var doc any
+		if err := decoder.Decode(&doc); err != nil {
+			return attr
+		}
+		return slog.Any(attr.Key, r.walk(attr.Key, doc))
+	}
+	return slog.Attr{Key: attr.Key, Value: value}
+}
@@ -0,0 +1,126 @@
+package redact
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/jackmcguire1/alexa-chatgpt/internal/pkg/alexa"
+	"github.com/jackmcguire1/alexa-chatgpt/internal/pkg/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	apiAccessToken = "eyJ0eXAiOiJKV1QiLCJhbGciOiJSUzI1NiJ9.secret"
+	accessToken    = "Atza|IwEBIAccountLinkingToken"
+	userID         = "amzn1.ask.account.AGF7PRIVATEUSER"
+	deviceID       = "amzn1.ask.device.AEX3PRIVATEDEVICE"
+	email          = "jane.doe@example.com"
+	phone          = "+1 415 555 0123"
+	longPrompt     = "please remember that my secret plan is to surprise my sister on her birthday"
+)
+
+var secrets = []string{apiAccessToken, accessToken, userID, deviceID, email, phone, longPrompt}
+
+type queuedRequest struct {
+	UserID string `json:"user_id"`
+	Prompt string `json:"prompt"`
+}
+
+type userLogValuer struct{}
+
+func (userLogValuer) LogValue() slog.Value {
+	return slog.GroupValue(slog.String("userId", userID), slog.String("email", email))
+}
+
+func alexaRequest() alexa.Request {
+	var req alexa.Request
+	req.Session.User.UserID = userID
+	req.Session.User.AccessToken = accessToken
+	req.Context.System.APIAccessToken = apiAccessToken
+	req.Context.System.Device.DeviceID = deviceID
+	req.Body.Intent.Slots = map[string]alexa.Slot{"prompt": {Name: "prompt", Value: longPrompt + " " + email}}
+	return req
+}
+
+func TestSecretsNeverReachLogs(t *testing.T) {
+	tests := []struct {
+		name string
+		log  func(logger *slog.Logger)
+	}{
+		{name: "alexa request as JSON", log: func(logger *slog.Logger) {
+			logger.With("intent", utils.ToJSON(alexaRequest())).Info("got intent")
+		}},
+		{name: "alexa request as value", log: func(logger *slog.Logger) {
+			logger.Info("got intent", "request", alexaRequest())
+		}},
+		{name: "queued request", log: func(logger *slog.Logger) {
+			logger.With("payload", utils.ToJSON(queuedRequest{UserID: userID, Prompt: longPrompt})).Info("invoked with payload")
+		}},
+		{name: "prompt and response", log: func(logger *slog.Logger) {
+			logger.With("prompt", longPrompt).With("response", "call "+phone).Info("answered")
+		}},
+		{name: "error", log: func(logger *slog.Logger) {
+			logger.With("error", errors.New("no account for "+email+" or "+phone)).Error("lookup failed")
+		}},
+		{name: "group", log: func(logger *slog.Logger) {
+			logger.WithGroup("alexa").Info("session", slog.Group("user", "userId", userID, "accessToken", accessToken))
+		}},
+		{name: "log valuer", log: func(logger *slog.Logger) {
+			logger.Info("user", "user", userLogValuer{})
+		}},
+		{name: "message", log: func(logger *slog.Logger) {
+			logger.Info("sending to " + email)
+		}},
+		{name: "text key as value", log: func(logger *slog.Logger) {
+			logger.With("response", struct{ Text string }{Text: longPrompt}).Info("answered")
+		}},
+		{name: "secret key as value", log: func(logger *slog.Logger) {
+			logger.With("token", struct{ Value string }{Value: accessToken}).Info("linked")
+		}},
+		{name: "id key as value", log: func(logger *slog.Logger) {
+			logger.With("userId", []string{userID}).Info("looked up")
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			tt.log(slog.New(NewHandler(slog.NewJSONHandler(&buf, nil), &Redactor{})))
+
+			assert.NotEmpty(t, buf.String())
+			assert.NotContains(t, buf.String(), "!ERROR", "masked values must still be logged")
+			for _, secret := range secrets {
+				assert.NotContains(t, buf.String(), secret)
+			}
+		})
+	}
+}
+
+func TestLogsKeepWhatIsSafe(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewHandler(slog.NewJSONHandler(&buf, nil), &Redactor{}))
+	logger.With("request-id", "req-1").With("model", "sonnet").With("user-id", userID).Info("answered")
+
+	assert.Contains(t, buf.String(), `"request-id":"req-1"`)
+	assert.Contains(t, buf.String(), `"model":"sonnet"`)
+	assert.Contains(t, buf.String(), `"user-id":"`+maskID(userID)+`"`)
+}
+
+func TestValuesUnderMaskedKeysAreLoggedAsText(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewHandler(slog.NewJSONHandler(&buf, nil), &Redactor{}))
+	logger.With("token", struct{ Value string }{Value: accessToken}).With("userId", []string{userID}).Info("linked")
+
+	assert.Contains(t, buf.String(), `"token":"[REDACTED]"`)
+	assert.Contains(t, buf.String(), `"userId":"`+maskID(`["`+userID+`"]`)+`"`)
+}
+
+func TestDebugLogsEverything(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewHandler(slog.NewJSONHandler(&buf, nil), &Redactor{Debug: true}))
+	logger.With("prompt", longPrompt).With("userId", userID).Info("answered")
+
+	assert.Contains(t, buf.String(), longPrompt)
+	assert.Contains(t, buf.String(), userID)
+}
@@ -0,0 +1,76 @@
+package redact
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+type spanExporter struct {
+	sdktrace.SpanExporter
+	redactor *Redactor
+}
+
+// NewSpanExporter wraps next so the attributes of every span it exports,
+// and of their events, are masked by r first. In debug mode next is
+// returned as is.
+func NewSpanExporter(next sdktrace.SpanExporter, r *Redactor) sdktrace.SpanExporter {
+	if r.Debug {
+		return next
+	}
+	return &spanExporter{SpanExporter: next, redactor: r}
+}
+
+func (e *spanExporter) ExportSpans(ctx context.Context, spans []sdktrace.ReadOnlySpan) error {
+	redacted := make([]sdktrace.ReadOnlySpan, len(spans))
+	for i, span := range spans {
+		redacted[i] = &redactedSpan{ReadOnlySpan: span, redactor: e.redactor}
+	}
+	return e.SpanExporter.ExportSpans(ctx, redacted)
+}
+
+type redactedSpan struct {
+	sdktrace.ReadOnlySpan
+	redactor *Redactor
+}
+
+func (s *redactedSpan) Attributes() []attribute.KeyValue {
+	return s.redactor.Attributes(s.ReadOnlySpan.Attributes())
+}
+
+func (s *redactedSpan) Events() []sdktrace.Event {
+	events := s.ReadOnlySpan.Events()
+	redacted := make([]sdktrace.Event, len(events))
+	for i, event := range events {
+		event.Attributes = s.redactor.Attributes(event.Attributes)
+		redacted[i] = event
+	}
+	return redacted
+}
+
+func (s *redactedSpan) Status() sdktrace.Status {
+	status := s.ReadOnlySpan.Status()
+	status.Description = maskPatterns(status.Description)
+	return status
+}
+
+// Attributes masks the string attributes of attrs by their keys.
+func (r *Redactor) Attributes(attrs []attribute.KeyValue) []attribute.KeyValue {
+	redacted := make([]attribute.KeyValue, len(attrs))
+	for i, attr := range attrs {
+		key := string(attr.Key)
+		switch attr.Value.Type() {
+		case attribute.STRING:
+			attr = attribute.String(key, r.String(key, attr.Value.AsString()))
+		case attribute.STRINGSLICE:
+			values := attr.Value.AsStringSlice()
+			for j, value := range values {
+				values[j] = r.String(key, value)
+			}
+			attr = attribute.StringSlice(key, values)
+		}
+		redacted[i] = attr
+	}
+	return redacted
+}
@@ -0,0 +1,78 @@
+package redact
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestSpanExporterMasksAttributes(t *testing.T) {
+	tests := []struct {
+		name  string
+		debug bool
+		want  map[attribute.Key]attribute.Value
+	}{
+		{
+			name: "redacted",
+			want: map[attribute.Key]attribute.Value{
+				"prompt":          attribute.StringValue("please rem…(76 chars)"),
+				"system-prompt":   attribute.StringValue("[email]"),
+				"message-receipt": attribute.StringValue("[REDACTED]"),
+				"user-id":         attribute.StringValue(maskID(userID)),
+				"model":           attribute.StringValue("sonnet"),
+				"input-tokens":    attribute.IntValue(12),
+			},
+		},
+		{
+			name:  "debug",
+			debug: true,
+			want: map[attribute.Key]attribute.Value{
+				"prompt":          attribute.StringValue(longPrompt),
+				"system-prompt":   attribute.StringValue(email),
+				"message-receipt": attribute.StringValue("AQEBwJnKyrHigUMZj6rYigCgxlaS3SLy0a"),
+				"user-id":         attribute.StringValue(userID),
+				"model":           attribute.StringValue("sonnet"),
+				"input-tokens":    attribute.IntValue(12),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exporter := tracetest.NewInMemoryExporter()
+			tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(NewSpanExporter(exporter, &Redactor{MaxTextLength: 10, Debug: tt.debug})))
+
+			_, span := tp.Tracer("test").Start(context.Background(), "ProcessGenerationRequest")
+			span.SetAttributes(
+				attribute.String("prompt", longPrompt),
+				attribute.String("system-prompt", email),
+				attribute.String("message-receipt", "AQEBwJnKyrHigUMZj6rYigCgxlaS3SLy0a"),
+				attribute.String("user-id", userID),
+				attribute.String("model", "sonnet"),
+				attribute.Int("input-tokens", 12),
+			)
+			span.AddEvent("stream-failed", trace.WithAttributes(attribute.String("error", "no reply from "+phone)))
+			span.SetStatus(codes.Error, "failed for "+email)
+			span.End()
+
+			spans := exporter.GetSpans()
+			require.Len(t, spans, 1)
+			got := map[attribute.Key]attribute.Value{}
+			for _, attr := range spans[0].Attributes {
+				got[attr.Key] = attr.Value
+			}
+			assert.Equal(t, tt.want, got)
+
+			if !tt.debug {
+				assert.Equal(t, "no reply from [phone]", spans[0].Events[0].Attributes[0].Value.AsString())
+				assert.Equal(t, "failed for [email]", spans[0].Status.Description)
+			}
+		})
+	}
+}
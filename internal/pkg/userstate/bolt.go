@@ -0,0 +1,57 @@
+package userstate
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var usersBucket = []byte("users")
+
+// BoltStore keeps user state in a local bbolt database file, for development
+// and tests that need state to survive a restart.
+type BoltStore struct {
+	db *bolt.DB
+}
+
+func NewBoltStore(path string) (*BoltStore, error) {
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("userstate: open %s: %w", path, err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(usersBucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("userstate: create bucket: %w", err)
+	}
+
+	return &BoltStore{db: db}, nil
+}
+
+func (s *BoltStore) Get(_ context.Context, userID string) (data []byte, err error) {
+	err = s.db.View(func(tx *bolt.Tx) error {
+		value := tx.Bucket(usersBucket).Get([]byte(userID))
+		if value == nil {
+			return NotFoundErr
+		}
+		data = append([]byte(nil), value...)
+		return nil
+	})
+	return
+}
+
+func (s *BoltStore) Put(_ context.Context, userID string, data []byte) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(usersBucket).Put([]byte(userID), data)
+	})
+}
+
+func (s *BoltStore) Close() error {
+	return s.db.Close()
+}
@@ -0,0 +1,90 @@
+package userstate
+
+import (
+	"context"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+var tracer = otel.Tracer("userstate")
+
+const (
+	userIDAttribute    = "user_id"
+	stateAttribute     = "state"
+	updatedAtAttribute = "updated_at"
+)
+
+type dynamoAPI interface {
+	GetItem(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error)
+	PutItem(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error)
+}
+
+// DynamoStore keeps user state in a DynamoDB table with a string partition
+// key named user_id.
+type DynamoStore struct {
+	client dynamoAPI
+	table  string
+}
+
+func NewDynamoStore(table string) *DynamoStore {
+	cfg, err := config.LoadDefaultConfig(context.TODO())
+	if err != nil {
+		log.Fatalf("unable to load SDK config, %v", err)
+	}
+
+	return &DynamoStore{
+		client: dynamodb.NewFromConfig(cfg),
+		table:  table,
+	}
+}
+
+func (s *DynamoStore) Get(ctx context.Context, userID string) ([]byte, error) {
+	ctx, span := tracer.Start(ctx, "GetItem")
+	defer span.End()
+	span.SetAttributes(attribute.String("table", s.table))
+
+	resp, err := s.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName:      &s.table,
+		ConsistentRead: aws.Bool(true),
+		Key: map[string]types.AttributeValue{
+			userIDAttribute: &types.AttributeValueMemberS{Value: userID},
+		},
+	})
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	state, ok := resp.Item[stateAttribute].(*types.AttributeValueMemberB)
+	if !ok {
+		return nil, NotFoundErr
+	}
+	return state.Value, nil
+}
+
+func (s *DynamoStore) Put(ctx context.Context, userID string, data []byte) error {
+	ctx, span := tracer.Start(ctx, "PutItem")
+	defer span.End()
+	span.SetAttributes(attribute.String("table", s.table))
+
+	_, err := s.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: &s.table,
+		Item: map[string]types.AttributeValue{
+			userIDAttribute:    &types.AttributeValueMemberS{Value: userID},
+			stateAttribute:     &types.AttributeValueMemberB{Value: data},
+			updatedAtAttribute: &types.AttributeValueMemberN{Value: strconv.FormatInt(time.Now().Unix(), 10)},
+		},
+	})
+	if err != nil {
+		span.RecordError(err)
+	}
+	return err
+}
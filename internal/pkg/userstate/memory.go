@@ -0,0 +1,35 @@
+package userstate
+
+import (
+	"context"
+	"sync"
+)
+
+// MemoryStore keeps user state for the lifetime of the process.
+type MemoryStore struct {
+	mu    sync.RWMutex
+	users map[string][]byte
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{users: make(map[string][]byte)}
+}
+
+func (s *MemoryStore) Get(_ context.Context, userID string) ([]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	data, ok := s.users[userID]
+	if !ok {
+		return nil, NotFoundErr
+	}
+	return append([]byte(nil), data...), nil
+}
+
+func (s *MemoryStore) Put(_ context.Context, userID string, data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.users[userID] = append([]byte(nil), data...)
+	return nil
+}
@@ -0,0 +1,15 @@
+package userstate
+
+import (
+	"context"
+	"errors"
+)
+
+var NotFoundErr = errors.New("user state not found")
+
+// UserStateStore persists the serialised state of a single Alexa user keyed by
+// their Session.User.UserID. Get returns NotFoundErr for users without state.
+type UserStateStore interface {
+	Get(ctx context.Context, userID string) ([]byte, error)
+	Put(ctx context.Context, userID string, data []byte) error
+}
@@ -0,0 +1,81 @@
+package userstate
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeDynamo struct {
+	items map[string]map[string]types.AttributeValue
+}
+
+func (f *fakeDynamo) GetItem(_ context.Context, params *dynamodb.GetItemInput, _ ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error) {
+	key := params.Key[userIDAttribute].(*types.AttributeValueMemberS).Value
+	return &dynamodb.GetItemOutput{Item: f.items[key]}, nil
+}
+
+func (f *fakeDynamo) PutItem(_ context.Context, params *dynamodb.PutItemInput, _ ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error) {
+	key := params.Item[userIDAttribute].(*types.AttributeValueMemberS).Value
+	f.items[key] = params.Item
+	return &dynamodb.PutItemOutput{}, nil
+}
+
+func TestUserStateStores(t *testing.T) {
+	bolt, err := NewBoltStore(filepath.Join(t.TempDir(), "state.db"))
+	require.NoError(t, err)
+	defer bolt.Close()
+
+	stores := map[string]UserStateStore{
+		"memory": NewMemoryStore(),
+		"bolt":   bolt,
+		"dynamo": &DynamoStore{client: &fakeDynamo{items: map[string]map[string]types.AttributeValue{}}, table: "users"},
+	}
+
+	for name, store := range stores {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+
+			_, err := store.Get(ctx, "alice")
+			assert.ErrorIs(t, err, NotFoundErr)
+
+			assert.NoError(t, store.Put(ctx, "alice", []byte(`{"model":"sonnet"}`)))
+			assert.NoError(t, store.Put(ctx, "bob", []byte(`{"model":"grok"}`)))
+
+			data, err := store.Get(ctx, "alice")
+			assert.NoError(t, err)
+			assert.JSONEq(t, `{"model":"sonnet"}`, string(data))
+
+			assert.NoError(t, store.Put(ctx, "alice", []byte(`{"model":"opus"}`)))
+			data, err = store.Get(ctx, "alice")
+			assert.NoError(t, err)
+			assert.JSONEq(t, `{"model":"opus"}`, string(data))
+
+			data, err = store.Get(ctx, "bob")
+			assert.NoError(t, err)
+			assert.JSONEq(t, `{"model":"grok"}`, string(data))
+		})
+	}
+}
+
+func TestBoltStoreSurvivesReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.db")
+
+	store, err := NewBoltStore(path)
+	require.NoError(t, err)
+	assert.NoError(t, store.Put(context.Background(), "alice", []byte(`{"model":"sonnet"}`)))
+	require.NoError(t, store.Close())
+
+	store, err = NewBoltStore(path)
+	require.NoError(t, err)
+	defer store.Close()
+
+	data, err := store.Get(context.Background(), "alice")
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"model":"sonnet"}`, string(data))
+}
@@ -0,0 +1,27 @@
+package utils
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// CompleteSentences returns the longest prefix of text that ends a sentence,
+// or "" when text does not hold a complete sentence yet. A full stop, question
+// or exclamation mark only ends a sentence once whitespace follows it, since
+// more text may still be on its way, e.g. the "5" of "3.5".
+func CompleteSentences(text string) string {
+	end := 0
+	for i, r := range text {
+		switch r {
+		case '。', '！', '？':
+			end = i + utf8.RuneLen(r)
+		case '.', '!', '?':
+			next := i + 1
+			if next < len(text) && unicode.IsSpace(rune(text[next])) {
+				end = next
+			}
+		}
+	}
+	return strings.TrimSpace(text[:end])
+}
@@ -0,0 +1,29 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompleteSentences(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"empty", "", ""},
+		{"no terminator", "The sky is blue because", ""},
+		{"terminator at the end is not trusted", "The answer is 3.", ""},
+		{"decimal number", "The answer is 3.5 metres", ""},
+		{"one sentence", "The sky is blue. Because", "The sky is blue."},
+		{"several sentences", "Really? Yes! It is blue. And", "Really? Yes! It is blue."},
+		{"newline after terminator", "First.\nSecond", "First."},
+		{"japanese", "空は青い。なぜなら", "空は青い。"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, CompleteSentences(tt.text))
+		})
+	}
+}
@@ -0,0 +1,405 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/google/uuid"
+	"github.com/jackmcguire1/alexa-chatgpt/internal/dom/chatmodels"
+	otelsetup "github.com/jackmcguire1/alexa-chatgpt/internal/otel"
+	"github.com/jackmcguire1/alexa-chatgpt/internal/pkg/bucket"
+	"github.com/jackmcguire1/alexa-chatgpt/internal/pkg/queue"
+	"github.com/jackmcguire1/alexa-chatgpt/internal/pkg/utils"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("prompt-handler")
+
+// DefaultMaxWorkers bounds how many records of a batch are processed at once
+// when the SqsHandler does not set its own limit.
+const DefaultMaxWorkers = 4
+
+// partialMinLength is how much of a streamed answer, in complete sentences,
+// is needed before it is worth pushing as a partial response.
+const partialMinLength = 100
+
+type SqsHandler struct {
+	GenerationModelSvc chatmodels.Service
+	ResponseQueue      queue.PullPoll
+	Logger             *slog.Logger
+	Bucket             bucket.FilePersistance
+	MaxWorkers         int
+	StreamPartials     bool
+}
+
+func (handler *SqsHandler) ProcessGenerationRequest(ctx context.Context, req *chatmodels.Request) error {
+	defer func() {
+		if r := recover(); r != nil {
+			handler.Logger.
+				With("payload", utils.ToJSON(req)).
+				With("error", r).
+				Error("panic occurred during request processing")
+
+			handler.Recover(ctx, req)
+		}
+	}()
+
+	handler.Logger.With("payload", utils.ToJSON(req)).Info("invoked with payload")
+	execTime := time.Now().UTC()
+
+	var errorMsg string
+	var response string
+	var romanization string
+	var imagesResponse []string
+	var usedModel chatmodels.ChatModel
+	var err error
+	generation := &chatmodels.Generation{}
+
+	// continue the trace of the Alexa request that queued req, linked to the
+	// span of the batch it arrived in
+	var spanOpts []trace.SpanStartOption
+	if parent, ok := otelsetup.ContextWithRemoteParent(ctx, req.TraceID, req.SpanID); ok {
+		if trace.SpanContextFromContext(ctx).IsValid() {
+			spanOpts = append(spanOpts, trace.WithLinks(trace.LinkFromContext(ctx)))
+		}
+		ctx = parent
+	}
+
+	ctx, span := tracer.Start(ctx, "ProcessGenerationRequest", spanOpts...)
+	span.SetAttributes(
+		attribute.String("request-id", req.RequestID),
+		attribute.String("prompt", req.Prompt),
+		attribute.String("system-prompt", req.SystemPrompt),
+	)
+
+	defer span.End()
+
+	if req.ImageModel != nil {
+		switch *req.ImageModel {
+		case chatmodels.IMAGE_MODEL_FLUX:
+			span.SetAttributes(
+				attribute.String("image-model", string(*req.ImageModel)),
+			)
+
+			imageBody, err := handler.GenerationModelSvc.GenerateImage(ctx, req.Prompt, *req.ImageModel)
+			if err != nil {
+				handler.Logger.
+					With("image-model", *req.ImageModel).
+					With("prompt", req.Prompt).
+					With("error", err).
+					Error("failed to generate image from request")
+
+				errorMsg = err.Error()
+				goto respond
+			}
+
+			imagesResponse, err = handler.processImage(ctx, imageBody)
+			if err != nil {
+				handler.Logger.
+					With("prompt", req.Prompt).
+					With("error", err).
+					Error("failed to persist image resolutions")
+
+				errorMsg = err.Error()
+			}
+			goto respond
+		}
+	}
+
+	span.SetAttributes(attribute.String("model", req.Model.String()))
+	switch req.Model {
+	case chatmodels.CHAT_MODEL_TRANSLATIONS:
+		span.SetAttributes(
+			attribute.String("source-language", req.SourceLanguage),
+			attribute.String("target-language", req.TargetLanguage),
+			attribute.String("translation-model", req.TranslationModel.String()),
+		)
+		var translation chatmodels.Translation
+		translation, err = handler.GenerationModelSvc.Translate(
+			chatmodels.WithGeneration(ctx, generation),
+			req.Prompt,
+			req.SourceLanguage,
+			req.TargetLanguage,
+			req.TranslationModel,
+			chatmodels.TranslateOptions{Glossary: req.Glossary, Romanize: req.Romanize},
+		)
+		response, romanization = translation.Text, translation.Romanization
+		if err != nil {
+			handler.Logger.
+				With("prompt", req.Prompt).
+				With("error", err).
+				Error("failed to process translation request")
+
+			errorMsg = err.Error()
+			break
+		}
+	default:
+		genCtx := chatmodels.WithGeneration(ctx, generation)
+		switch {
+		case handler.StreamPartials:
+			response, err = handler.streamResponse(genCtx, req, execTime)
+		case len(req.History) > 0:
+			span.SetAttributes(attribute.Int("history-turns", len(req.History)))
+			response, err = handler.GenerationModelSvc.ChatGeneration(genCtx, conversationMessages(req), req.Model)
+		case req.SystemPrompt != "":
+			span.SetAttributes(attribute.String("system-prompt", req.SystemPrompt))
+			response, err = handler.GenerationModelSvc.TextGenerationWithSystem(genCtx, req.SystemPrompt, req.Prompt, req.Model)
+		default:
+			response, err = handler.GenerationModelSvc.TextGeneration(genCtx, req.Prompt, req.Model)
+		}
+		usedModel = generation.Model
+		if err != nil {
+			handler.Logger.
+				With("system-prompt", req.SystemPrompt).
+				With("prompt", req.Prompt).
+				With("error", err).
+				Error("failed to process chat model request")
+
+			errorMsg = err.Error()
+			break
+		}
+	}
+respond:
+	since := time.Since(execTime)
+
+	handler.Logger.
+		With("response", response).
+		With("since", since).
+		Info("pushing response to queue")
+
+	if errorMsg != "" {
+		span.RecordError(errors.New(errorMsg))
+	}
+	span.SetAttributes(
+		attribute.Int("response-bytes", len(response)),
+		attribute.Int("image-response-count", len(imagesResponse)),
+	)
+
+	event := &chatmodels.LastResponse{
+		RequestID:      req.RequestID,
+		UserID:         req.UserID,
+		SessionID:      req.SessionID,
+		Prompt:         req.Prompt,
+		Response:       response,
+		TimeDiff:       fmt.Sprintf("%.0f", since.Seconds()),
+		Model:          req.Model.String(),
+		ImagesResponse: imagesResponse,
+		Error:          errorMsg,
+		SystemPrompt:   req.SystemPrompt,
+		TargetLanguage: req.TargetLanguage,
+		Romanization:   romanization,
+		RequestedAt:    req.RequestedAt,
+	}
+
+	if generation.Usage.Tokens() > 0 {
+		event.Usage = &generation.Usage
+		span.SetAttributes(
+			attribute.Int("input-tokens", generation.Usage.InputTokens),
+			attribute.Int("output-tokens", generation.Usage.OutputTokens),
+			attribute.Float64("cost-usd", generation.Usage.Cost),
+		)
+	}
+
+	// report the fallback model when the requested one could not answer
+	if usedModel != "" {
+		event.Model = usedModel.String()
+		span.SetAttributes(attribute.String("model-used", usedModel.String()))
+	}
+
+	// override the model if image model was set
+	if req.ImageModel != nil {
+		event.Model = req.ImageModel.String()
+	}
+
+	err = handler.ResponseQueue.PushMessage(ctx, event)
+	if err != nil {
+		span.RecordError(err)
+		handler.Logger.
+			With("event", utils.ToJSON(event)).
+			With("error", err).
+			Error("failed to publish message to queue")
+	}
+
+	return err
+}
+
+// streamResponse streams the answer and pushes its first complete sentences
+// as a partial LastResponse, so the skill can start speaking while the rest is
+// generated. Pushing the partial is best effort; a failure is logged and the
+// stream carries on. When the stream fails, only the error is returned, even
+// if a partial was already pushed, so the final response is a plain failure.
+func (handler *SqsHandler) streamResponse(ctx context.Context, req *chatmodels.Request, execTime time.Time) (string, error) {
+	ctx, span := tracer.Start(ctx, "streamResponse")
+	defer span.End()
+
+	var streamed strings.Builder
+	pushed := false
+	onDelta := func(delta string) error {
+		streamed.WriteString(delta)
+		if pushed {
+			return nil
+		}
+
+		sentences := utils.CompleteSentences(streamed.String())
+		if len(sentences) < partialMinLength {
+			return nil
+		}
+		pushed = true
+
+		partial := &chatmodels.LastResponse{
+			RequestID:    req.RequestID,
+			UserID:       req.UserID,
+			SessionID:    req.SessionID,
+			Prompt:       req.Prompt,
+			Response:     sentences,
+			TimeDiff:     fmt.Sprintf("%.0f", time.Since(execTime).Seconds()),
+			Model:        req.Model.String(),
+			SystemPrompt: req.SystemPrompt,
+			Partial:      true,
+		}
+		if err := handler.ResponseQueue.PushMessage(ctx, partial); err != nil {
+			span.RecordError(err)
+			handler.Logger.
+				With("request-id", req.RequestID).
+				With("error", err).
+				Error("failed to publish partial response to queue")
+			return nil
+		}
+		span.AddEvent("partial-response", trace.WithAttributes(attribute.Int("response-bytes", len(sentences))))
+		return nil
+	}
+
+	response, err := handler.GenerationModelSvc.StreamGeneration(ctx, conversationMessages(req), req.Model, onDelta)
+	if err != nil {
+		return "", err
+	}
+	return response, nil
+}
+
+// conversationMessages lays out the request's history followed by its prompt,
+// led by the system prompt when one was set.
+func conversationMessages(req *chatmodels.Request) []chatmodels.Message {
+	var messages []chatmodels.Message
+	if req.SystemPrompt != "" {
+		messages = append(messages, chatmodels.Message{Role: chatmodels.RoleSystem, Content: req.SystemPrompt})
+	}
+	messages = append(messages, req.History...)
+	return append(messages, chatmodels.Message{Role: chatmodels.RoleUser, Content: req.Prompt})
+}
+
+// ProcessSQS processes every record in the batch, at most workers() at a time,
+// and reports the records that failed so only those are retried.
+func (handler *SqsHandler) ProcessSQS(ctx context.Context, event events.SQSEvent) (events.SQSEventResponse, error) {
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		failures []events.SQSBatchItemFailure
+	)
+
+	workers := make(chan struct{}, handler.workers())
+	for _, record := range event.Records {
+		workers <- struct{}{}
+		wg.Go(func() {
+			defer func() { <-workers }()
+
+			if err := handler.processRecord(ctx, record); err != nil {
+				mu.Lock()
+				failures = append(failures, events.SQSBatchItemFailure{ItemIdentifier: record.MessageId})
+				mu.Unlock()
+			}
+		})
+	}
+	wg.Wait()
+
+	if len(failures) > 0 {
+		handler.Logger.
+			With("failed", len(failures)).
+			With("records", len(event.Records)).
+			Warn("reporting batch item failures")
+	}
+
+	return events.SQSEventResponse{BatchItemFailures: failures}, nil
+}
+
+// Consume pulls messages off requests and processes each one until ctx is
+// done, standing in for the SQS event source mapping when the worker runs
+// outside Lambda. Failed records are logged and dropped, as there is no
+// redrive policy to hand them to.
+func (handler *SqsHandler) Consume(ctx context.Context, requests queue.PullPoll, wait int) {
+	for ctx.Err() == nil {
+		data, err := requests.PullMessage(ctx, wait)
+		if err != nil && !errors.Is(err, queue.EmptyMessageErr) {
+			if ctx.Err() == nil {
+				handler.Logger.With("error", err).Error("failed to pull request")
+			}
+			continue
+		}
+		if len(data) == 0 {
+			continue
+		}
+
+		messageID := uuid.New().String()
+		resp, _ := handler.ProcessSQS(ctx, events.SQSEvent{
+			Records: []events.SQSMessage{{MessageId: messageID, Body: string(data)}},
+		})
+		if len(resp.BatchItemFailures) > 0 {
+			handler.Logger.
+				With("message-id", messageID).
+				With("data", string(data)).
+				Warn("dropping request that failed processing")
+		}
+	}
+}
+
+func (handler *SqsHandler) processRecord(ctx context.Context, record events.SQSMessage) error {
+	var request *chatmodels.Request
+
+	err := json.Unmarshal([]byte(record.Body), &request)
+	if err != nil {
+		handler.Logger.
+			With("message-id", record.MessageId).
+			With("data", record.Body).
+			With("error", err).
+			Error("failed to unmarshal event")
+
+		return err
+	}
+
+	return handler.ProcessGenerationRequest(ctx, request)
+}
+
+func (handler *SqsHandler) workers() int {
+	if handler.MaxWorkers > 0 {
+		return handler.MaxWorkers
+	}
+	return DefaultMaxWorkers
+}
+
+func (handler *SqsHandler) Recover(ctx context.Context, req *chatmodels.Request) {
+	// Push a failure message to the queue
+	event := &chatmodels.LastResponse{
+		RequestID:    req.RequestID,
+		UserID:       req.UserID,
+		SessionID:    req.SessionID,
+		Prompt:       req.Prompt,
+		Error:        "an error occured when processing the prompt",
+		Model:        req.Model.String(),
+		SystemPrompt: req.SystemPrompt,
+	}
+
+	if pushErr := handler.ResponseQueue.PushMessage(ctx, event); pushErr != nil {
+		handler.Logger.
+			With("event", utils.ToJSON(event)).
+			With("error", pushErr).
+			Error("failed to publish panic message to queue")
+	}
+}
@@ -0,0 +1,456 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/jackmcguire1/alexa-chatgpt/internal/dom/chatmodels"
+	"github.com/jackmcguire1/alexa-chatgpt/internal/pkg/queue"
+	"github.com/jackmcguire1/alexa-chatgpt/internal/pkg/utils"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestHandler(t *testing.T) {
+	mockChatGptSvc := &chatmodels.MockClient{}
+	mockChatGptSvc.On("TextGeneration", mock.Anything, "tell me a random fact", chatmodels.CHAT_MODEL_SONNET).Return("The battle of zanzibar lasted 30 minutes.", nil)
+
+	mockQueue := &queue.MockQueue{}
+	mockQueue.On("PushMessage", mock.Anything, mock.Anything).Return(nil)
+
+	jsonH := slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo})
+	logger := slog.New(jsonH)
+
+	h := &SqsHandler{
+		GenerationModelSvc: mockChatGptSvc,
+		ResponseQueue:      mockQueue,
+		Logger:             logger,
+	}
+
+	request := &chatmodels.Request{
+		Prompt: "tell me a random fact",
+		Model:  chatmodels.CHAT_MODEL_SONNET,
+	}
+
+	resp, err := h.ProcessSQS(context.Background(), events.SQSEvent{
+		Records: []events.SQSMessage{
+			events.SQSMessage{
+				Body: utils.ToJSON(request),
+			},
+		},
+	})
+	assert.NoError(t, err)
+	assert.Empty(t, resp.BatchItemFailures)
+}
+
+func TestModelErrorResponse(t *testing.T) {
+	mockChatGptSvc := &chatmodels.MockClient{}
+	mockChatGptSvc.On("TextGeneration", mock.Anything, "tell me a random fact", chatmodels.CHAT_MODEL_SONNET).Return("", fmt.Errorf("there was an issue with your API KEY"))
+
+	mockQueue := &queue.MockQueue{}
+	mockQueue.On("PushMessage", mock.Anything, mock.Anything).Return(nil)
+
+	jsonH := slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo})
+	logger := slog.New(jsonH)
+
+	h := &SqsHandler{
+		GenerationModelSvc: mockChatGptSvc,
+		ResponseQueue:      mockQueue,
+		Logger:             logger,
+	}
+
+	request := &chatmodels.Request{
+		Prompt: "tell me a random fact",
+		Model:  chatmodels.CHAT_MODEL_SONNET,
+	}
+
+	resp, err := h.ProcessSQS(context.Background(), events.SQSEvent{
+		Records: []events.SQSMessage{
+			events.SQSMessage{
+				Body: utils.ToJSON(request),
+			},
+		},
+	})
+	assert.NoError(t, err)
+	assert.Empty(t, resp.BatchItemFailures)
+	mockQueue.AssertNumberOfCalls(t, "PushMessage", 1)
+}
+
+func TestSqsError(t *testing.T) {
+	mockChatGptSvc := &chatmodels.MockClient{}
+	mockChatGptSvc.On("TextGeneration", mock.Anything, "tell me a random fact", chatmodels.CHAT_MODEL_SONNET).Return("", fmt.Errorf("there was an issue with your API KEY"))
+
+	mockQueue := &queue.MockQueue{}
+	mockQueue.On("PushMessage", mock.Anything, mock.Anything).Return(fmt.Errorf("500 internal server error"))
+
+	jsonH := slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo})
+	logger := slog.New(jsonH)
+
+	h := &SqsHandler{
+		GenerationModelSvc: mockChatGptSvc,
+		ResponseQueue:      mockQueue,
+		Logger:             logger,
+	}
+
+	request := &chatmodels.Request{
+		Prompt: "tell me a random fact",
+		Model:  chatmodels.CHAT_MODEL_SONNET,
+	}
+
+	resp, err := h.ProcessSQS(context.Background(), events.SQSEvent{
+		Records: []events.SQSMessage{
+			events.SQSMessage{
+				MessageId: "message-1",
+				Body:      utils.ToJSON(request),
+			},
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []events.SQSBatchItemFailure{{ItemIdentifier: "message-1"}}, resp.BatchItemFailures)
+}
+
+func TestBadJSONPayload(t *testing.T) {
+	jsonH := slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo})
+	logger := slog.New(jsonH)
+
+	h := &SqsHandler{
+		Logger: logger,
+	}
+
+	resp, err := h.ProcessSQS(context.Background(), events.SQSEvent{
+		Records: []events.SQSMessage{
+			events.SQSMessage{
+				MessageId: "message-1",
+				Body:      `{]`,
+			},
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []events.SQSBatchItemFailure{{ItemIdentifier: "message-1"}}, resp.BatchItemFailures)
+}
+
+func TestResponseEchoesCorrelationIDs(t *testing.T) {
+	mockChatGptSvc := &chatmodels.MockClient{}
+	mockChatGptSvc.On("TextGeneration", mock.Anything, "tell me a random fact", chatmodels.CHAT_MODEL_SONNET).Return("The battle of zanzibar lasted 30 minutes.", nil)
+
+	mockQueue := &queue.MockQueue{}
+	mockQueue.On("PushMessage", mock.Anything, mock.MatchedBy(func(r *chatmodels.LastResponse) bool {
+		return r.RequestID == "req-1" && r.UserID == "user-1" && r.SessionID == "session-1"
+	})).Return(nil)
+
+	jsonH := slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo})
+	logger := slog.New(jsonH)
+
+	h := &SqsHandler{
+		GenerationModelSvc: mockChatGptSvc,
+		ResponseQueue:      mockQueue,
+		Logger:             logger,
+	}
+
+	request := &chatmodels.Request{
+		RequestID: "req-1",
+		UserID:    "user-1",
+		SessionID: "session-1",
+		Prompt:    "tell me a random fact",
+		Model:     chatmodels.CHAT_MODEL_SONNET,
+	}
+
+	err := h.ProcessGenerationRequest(context.Background(), request)
+	assert.NoError(t, err)
+	mockQueue.AssertNumberOfCalls(t, "PushMessage", 1)
+}
+
+func TestConversationHistoryIsSentAsMessages(t *testing.T) {
+	expected := []chatmodels.Message{
+		{Role: chatmodels.RoleSystem, Content: "you are a pirate"},
+		{Role: chatmodels.RoleUser, Content: "why is the sky blue"},
+		{Role: chatmodels.RoleAssistant, Content: "rayleigh scattering, arr"},
+		{Role: chatmodels.RoleUser, Content: "and why is that"},
+	}
+
+	mockChatGptSvc := &chatmodels.MockClient{}
+	mockChatGptSvc.On("ChatGeneration", mock.Anything, expected, chatmodels.CHAT_MODEL_SONNET).Return("shorter wavelengths, arr", nil)
+
+	mockQueue := &queue.MockQueue{}
+	mockQueue.On("PushMessage", mock.Anything, mock.MatchedBy(func(r *chatmodels.LastResponse) bool {
+		return r.Response == "shorter wavelengths, arr"
+	})).Return(nil)
+
+	h := &SqsHandler{
+		GenerationModelSvc: mockChatGptSvc,
+		ResponseQueue:      mockQueue,
+		Logger:             slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo})),
+	}
+
+	err := h.ProcessGenerationRequest(context.Background(), &chatmodels.Request{
+		SystemPrompt: "you are a pirate",
+		Prompt:       "and why is that",
+		History:      expected[1:3],
+		Model:        chatmodels.CHAT_MODEL_SONNET,
+	})
+	assert.NoError(t, err)
+	mockChatGptSvc.AssertExpectations(t)
+}
+
+func TestMixedBatchReportsOnlyFailedRecords(t *testing.T) {
+	mockChatGptSvc := &chatmodels.MockClient{}
+	mockChatGptSvc.On("TextGeneration", mock.Anything, "tell me a random fact", chatmodels.CHAT_MODEL_SONNET).Return("The battle of zanzibar lasted 30 minutes.", nil)
+	mockChatGptSvc.On("TextGeneration", mock.Anything, "tell me a joke", chatmodels.CHAT_MODEL_SONNET).Return("knock knock", nil)
+
+	mockQueue := &queue.MockQueue{}
+	mockQueue.On("PushMessage", mock.Anything, mock.MatchedBy(func(r *chatmodels.LastResponse) bool {
+		return r.RequestID == "unlucky"
+	})).Return(fmt.Errorf("500 internal server error"))
+	mockQueue.On("PushMessage", mock.Anything, mock.Anything).Return(nil)
+
+	h := &SqsHandler{
+		GenerationModelSvc: mockChatGptSvc,
+		ResponseQueue:      mockQueue,
+		Logger:             slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo})),
+		MaxWorkers:         2,
+	}
+
+	fact := &chatmodels.Request{RequestID: "fact", Prompt: "tell me a random fact", Model: chatmodels.CHAT_MODEL_SONNET}
+	joke := &chatmodels.Request{RequestID: "joke", Prompt: "tell me a joke", Model: chatmodels.CHAT_MODEL_SONNET}
+	unlucky := &chatmodels.Request{RequestID: "unlucky", Prompt: "tell me a joke", Model: chatmodels.CHAT_MODEL_SONNET}
+
+	resp, err := h.ProcessSQS(context.Background(), events.SQSEvent{
+		Records: []events.SQSMessage{
+			{MessageId: "message-1", Body: utils.ToJSON(fact)},
+			{MessageId: "message-2", Body: `{"prompt": `},
+			{MessageId: "message-3", Body: utils.ToJSON(joke)},
+			{MessageId: "message-4", Body: utils.ToJSON(unlucky)},
+			{MessageId: "message-5", Body: `not json at all`},
+		},
+	})
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []events.SQSBatchItemFailure{
+		{ItemIdentifier: "message-2"},
+		{ItemIdentifier: "message-4"},
+		{ItemIdentifier: "message-5"},
+	}, resp.BatchItemFailures)
+	mockQueue.AssertNumberOfCalls(t, "PushMessage", 3)
+}
+
+func TestEmptyBatch(t *testing.T) {
+	h := &SqsHandler{
+		Logger: slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo})),
+	}
+
+	resp, err := h.ProcessSQS(context.Background(), events.SQSEvent{})
+	assert.NoError(t, err)
+	assert.Empty(t, resp.BatchItemFailures)
+}
+
+func TestResponseReportsFallbackModel(t *testing.T) {
+	mockChatGptSvc := &chatmodels.MockClient{}
+	mockChatGptSvc.On("TextGeneration", mock.Anything, "tell me a random fact", chatmodels.CHAT_MODEL_SONNET).
+		Run(func(args mock.Arguments) {
+			chatmodels.GenerationFrom(args.Get(0).(context.Context)).Model = chatmodels.CHAT_MODEL_NOVA_PRO
+		}).
+		Return("The battle of zanzibar lasted 30 minutes.", nil)
+
+	mockQueue := &queue.MockQueue{}
+	mockQueue.On("PushMessage", mock.Anything, mock.MatchedBy(func(r *chatmodels.LastResponse) bool {
+		return r.Model == chatmodels.CHAT_MODEL_NOVA_PRO.String()
+	})).Return(nil)
+
+	h := &SqsHandler{
+		GenerationModelSvc: mockChatGptSvc,
+		ResponseQueue:      mockQueue,
+		Logger:             slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo})),
+	}
+
+	err := h.ProcessGenerationRequest(context.Background(), &chatmodels.Request{
+		Prompt: "tell me a random fact",
+		Model:  chatmodels.CHAT_MODEL_SONNET,
+	})
+	assert.NoError(t, err)
+	mockQueue.AssertExpectations(t)
+}
+
+func streamingHandler(svc chatmodels.Service, q queue.PullPoll) *SqsHandler {
+	return &SqsHandler{
+		GenerationModelSvc: svc,
+		ResponseQueue:      q,
+		Logger:             slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo})),
+		StreamPartials:     true,
+	}
+}
+
+const (
+	firstSentences = "The sky looks blue because air molecules scatter short wavelengths far more than long ones. This is Rayleigh scattering."
+	restOfAnswer   = " At sunset the light travels further, so the reds win."
+)
+
+func TestStreamingPushesPartialThenFullResponse(t *testing.T) {
+	mockChatGptSvc := &chatmodels.MockClient{}
+	mockChatGptSvc.On("StreamGeneration", mock.Anything, mock.Anything, chatmodels.CHAT_MODEL_OPUS).
+		Return(firstSentences+restOfAnswer, nil, []string{firstSentences[:50], firstSentences[50:], restOfAnswer})
+
+	var pushed []*chatmodels.LastResponse
+	mockQueue := &queue.MockQueue{}
+	mockQueue.On("PushMessage", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) { pushed = append(pushed, args.Get(1).(*chatmodels.LastResponse)) }).
+		Return(nil)
+
+	h := streamingHandler(mockChatGptSvc, mockQueue)
+	err := h.ProcessGenerationRequest(context.Background(), &chatmodels.Request{
+		RequestID: "req-1",
+		UserID:    "user-1",
+		Prompt:    "why is the sky blue",
+		Model:     chatmodels.CHAT_MODEL_OPUS,
+	})
+	assert.NoError(t, err)
+	if !assert.Len(t, pushed, 2) {
+		return
+	}
+
+	assert.True(t, pushed[0].Partial)
+	assert.Equal(t, "req-1", pushed[0].RequestID)
+	assert.Equal(t, "user-1", pushed[0].UserID)
+	assert.Equal(t, firstSentences, pushed[0].Response)
+
+	assert.False(t, pushed[1].Partial)
+	assert.Equal(t, "req-1", pushed[1].RequestID)
+	assert.Equal(t, firstSentences+restOfAnswer, pushed[1].Response)
+	assert.Empty(t, pushed[1].Error)
+}
+
+func TestStreamingShortAnswerPushesOnlyFullResponse(t *testing.T) {
+	mockChatGptSvc := &chatmodels.MockClient{}
+	mockChatGptSvc.On("StreamGeneration", mock.Anything, mock.Anything, chatmodels.CHAT_MODEL_SONNET).
+		Return("It is blue. Really.", nil, []string{"It is blue. ", "Really."})
+
+	mockQueue := &queue.MockQueue{}
+	mockQueue.On("PushMessage", mock.Anything, mock.MatchedBy(func(r *chatmodels.LastResponse) bool {
+		return !r.Partial && r.Response == "It is blue. Really."
+	})).Return(nil).Once()
+
+	h := streamingHandler(mockChatGptSvc, mockQueue)
+	err := h.ProcessGenerationRequest(context.Background(), &chatmodels.Request{Prompt: "what colour is the sky", Model: chatmodels.CHAT_MODEL_SONNET})
+	assert.NoError(t, err)
+	mockQueue.AssertExpectations(t)
+}
+
+func TestStreamingErrorAfterPartialSendsOnlyTheError(t *testing.T) {
+	mockChatGptSvc := &chatmodels.MockClient{}
+	mockChatGptSvc.On("StreamGeneration", mock.Anything, mock.Anything, chatmodels.CHAT_MODEL_OPUS).
+		Return(firstSentences+" At sunset", fmt.Errorf("model stream error"), []string{firstSentences, " At sunset"})
+
+	var pushed []*chatmodels.LastResponse
+	mockQueue := &queue.MockQueue{}
+	mockQueue.On("PushMessage", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) { pushed = append(pushed, args.Get(1).(*chatmodels.LastResponse)) }).
+		Return(nil)
+
+	h := streamingHandler(mockChatGptSvc, mockQueue)
+	err := h.ProcessGenerationRequest(context.Background(), &chatmodels.Request{RequestID: "req-1", Prompt: "why is the sky blue", Model: chatmodels.CHAT_MODEL_OPUS})
+	assert.NoError(t, err)
+	if !assert.Len(t, pushed, 2) {
+		return
+	}
+	assert.True(t, pushed[0].Partial)
+	assert.Equal(t, firstSentences, pushed[0].Response)
+	assert.False(t, pushed[1].Partial)
+	assert.Equal(t, "model stream error", pushed[1].Error)
+	assert.Empty(t, pushed[1].Response)
+}
+
+func TestStreamingPartialPushFailureStillDeliversFullResponse(t *testing.T) {
+	mockChatGptSvc := &chatmodels.MockClient{}
+	mockChatGptSvc.On("StreamGeneration", mock.Anything, mock.Anything, chatmodels.CHAT_MODEL_OPUS).
+		Return(firstSentences+restOfAnswer, nil, []string{firstSentences, restOfAnswer})
+
+	mockQueue := &queue.MockQueue{}
+	mockQueue.On("PushMessage", mock.Anything, mock.MatchedBy(func(r *chatmodels.LastResponse) bool {
+		return r.Partial
+	})).Return(fmt.Errorf("500 internal server error")).Once()
+	mockQueue.On("PushMessage", mock.Anything, mock.MatchedBy(func(r *chatmodels.LastResponse) bool {
+		return !r.Partial
+	})).Return(nil).Once()
+
+	h := streamingHandler(mockChatGptSvc, mockQueue)
+	err := h.ProcessGenerationRequest(context.Background(), &chatmodels.Request{Prompt: "why is the sky blue", Model: chatmodels.CHAT_MODEL_OPUS})
+	assert.NoError(t, err)
+	mockQueue.AssertExpectations(t)
+}
+
+func TestTranslationRequest(t *testing.T) {
+	glossary := map[string]string{"cheers": "geonbae"}
+	mockChatGptSvc := &chatmodels.MockClient{}
+	mockChatGptSvc.On("Translate", mock.Anything, "cheers", "en", "ko", chatmodels.CHAT_MODEL_OPUS, chatmodels.TranslateOptions{
+		Glossary: glossary,
+		Romanize: true,
+	}).Return(chatmodels.Translation{Text: "건배", Romanization: "geonbae"}, nil).Once()
+
+	mockQueue := &queue.MockQueue{}
+	mockQueue.On("PushMessage", mock.Anything, mock.MatchedBy(func(event *chatmodels.LastResponse) bool {
+		return event.Model == chatmodels.CHAT_MODEL_TRANSLATIONS.String() &&
+			event.Response == "건배" &&
+			event.Romanization == "geonbae" &&
+			event.TargetLanguage == "ko"
+	})).Return(nil).Once()
+
+	h := &SqsHandler{
+		GenerationModelSvc: mockChatGptSvc,
+		ResponseQueue:      mockQueue,
+		Logger:             slog.New(slog.NewJSONHandler(os.Stdout, nil)),
+	}
+
+	request := &chatmodels.Request{
+		Prompt:           "cheers",
+		SourceLanguage:   "en",
+		TargetLanguage:   "ko",
+		TranslationModel: chatmodels.CHAT_MODEL_OPUS,
+		Glossary:         glossary,
+		Romanize:         true,
+		Model:            chatmodels.CHAT_MODEL_TRANSLATIONS,
+	}
+
+	resp, err := h.ProcessSQS(context.Background(), events.SQSEvent{
+		Records: []events.SQSMessage{{Body: utils.ToJSON(request)}},
+	})
+	assert.NoError(t, err)
+	assert.Empty(t, resp.BatchItemFailures)
+	mockChatGptSvc.AssertExpectations(t)
+	mockQueue.AssertExpectations(t)
+}
+
+func TestResponseCarriesUsage(t *testing.T) {
+	usage := chatmodels.Usage{InputTokens: 12, OutputTokens: 34, Cost: 0.001}
+	mockChatGptSvc := &chatmodels.MockClient{}
+	mockChatGptSvc.On("TextGeneration", mock.Anything, "tell me a random fact", chatmodels.CHAT_MODEL_SONNET).
+		Run(func(args mock.Arguments) {
+			chatmodels.GenerationFrom(args.Get(0).(context.Context)).Usage.Add(usage)
+		}).
+		Return("The battle of zanzibar lasted 30 minutes.", nil)
+
+	mockQueue := &queue.MockQueue{}
+	mockQueue.On("PushMessage", mock.Anything, mock.MatchedBy(func(event *chatmodels.LastResponse) bool {
+		return assert.Equal(t, &usage, event.Usage)
+	})).Return(nil).Once()
+
+	h := &SqsHandler{
+		GenerationModelSvc: mockChatGptSvc,
+		ResponseQueue:      mockQueue,
+		Logger:             slog.New(slog.NewJSONHandler(os.Stdout, nil)),
+	}
+
+	request := &chatmodels.Request{
+		Prompt: "tell me a random fact",
+		Model:  chatmodels.CHAT_MODEL_SONNET,
+	}
+
+	resp, err := h.ProcessSQS(context.Background(), events.SQSEvent{
+		Records: []events.SQSMessage{{Body: utils.ToJSON(request)}},
+	})
+	assert.NoError(t, err)
+	assert.Empty(t, resp.BatchItemFailures)
+	mockQueue.AssertExpectations(t)
+}
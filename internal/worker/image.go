@@ -1,4 +1,4 @@
-package main
+package worker
 
 import (
 	"bytes"
@@ -6,12 +6,27 @@ import (
 	"fmt"
 	"image"
 	"image/jpeg"
+	"time"
 
 	"github.com/disintegration/imaging"
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
 )
 
-func (hndler *SqsHandler) processImage(ctx context.Context, body []byte) ([]string, error) {
+var imageDuration, _ = otel.Meter("prompt-handler").Float64Histogram(
+	"worker.image.duration",
+	metric.WithDescription("Time to resize, compress and upload a generated image"),
+	metric.WithUnit("s"),
+)
+
+func (hndler *SqsHandler) processImage(ctx context.Context, body []byte) (urls []string, err error) {
+	start := time.Now()
+	defer func() {
+		imageDuration.Record(ctx, time.Since(start).Seconds(), metric.WithAttributes(attribute.Bool("error", err != nil)))
+	}()
+
 	// Decode the PNG image
 	img, _, err := image.Decode(bytes.NewReader(body))
 	if err != nil {
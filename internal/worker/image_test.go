@@ -0,0 +1,21 @@
+package worker
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/jackmcguire1/alexa-chatgpt/internal/otel/metrictest"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+func TestImageProcessingIsTimed(t *testing.T) {
+	failed := attribute.Bool("error", true)
+	before := metrictest.Sum(t, "worker.image.duration", failed)
+
+	_, err := (&SqsHandler{Logger: slog.New(slog.NewJSONHandler(io.Discard, nil))}).processImage(context.Background(), []byte("not a png"))
+	assert.Error(t, err)
+	assert.Equal(t, before+1, metrictest.Sum(t, "worker.image.duration", failed))
+}
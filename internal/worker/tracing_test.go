@@ -0,0 +1,62 @@
+package worker
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"testing"
+
+	"github.com/jackmcguire1/alexa-chatgpt/internal/dom/chatmodels"
+	"github.com/jackmcguire1/alexa-chatgpt/internal/pkg/queue"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestRequestContinuesAlexaTrace(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	otel.SetTracerProvider(tp)
+
+	mockChatGptSvc := &chatmodels.MockClient{}
+	mockChatGptSvc.On("TextGeneration", mock.Anything, mock.Anything, chatmodels.CHAT_MODEL_SONNET).Return("hello", nil)
+	mockQueue := &queue.MockQueue{}
+	mockQueue.On("PushMessage", mock.Anything, mock.Anything).Return(nil)
+	h := &SqsHandler{
+		GenerationModelSvc: mockChatGptSvc,
+		ResponseQueue:      mockQueue,
+		Logger:             slog.New(slog.NewJSONHandler(os.Stdout, nil)),
+	}
+
+	ctx, batch := tp.Tracer("test").Start(context.Background(), "batch")
+	err := h.ProcessGenerationRequest(ctx, &chatmodels.Request{
+		Prompt:  "hi",
+		Model:   chatmodels.CHAT_MODEL_SONNET,
+		TraceID: "1-5759e988-bd862e3fe1be46a994272793",
+		SpanID:  "53995c3f42cd8ad8",
+	})
+	require.NoError(t, err)
+	batch.End()
+
+	var span sdktrace.ReadOnlySpan
+	for _, s := range recorder.Ended() {
+		if s.Name() == "ProcessGenerationRequest" {
+			span = s
+		}
+	}
+	require.NotNil(t, span)
+	assert.Equal(t, "5759e988bd862e3fe1be46a994272793", span.SpanContext().TraceID().String())
+	assert.Equal(t, "53995c3f42cd8ad8", span.Parent().SpanID().String())
+	assert.True(t, span.Parent().IsRemote())
+	require.Len(t, span.Links(), 1)
+	assert.Equal(t, batch.SpanContext(), span.Links()[0].SpanContext)
+
+	// requests without a trace stay in the batch's trace
+	err = h.ProcessGenerationRequest(ctx, &chatmodels.Request{Prompt: "hi", Model: chatmodels.CHAT_MODEL_SONNET})
+	require.NoError(t, err)
+	ended := recorder.Ended()
+	assert.Equal(t, batch.SpanContext().TraceID(), ended[len(ended)-1].SpanContext().TraceID())
+}